@@ -11,18 +11,26 @@ package dsm
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"golang.org/x/time/rate"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 type api_client struct {
@@ -36,11 +44,30 @@ type api_client struct {
 	azure_region string
 	insecure     bool
 	timeout      int
+	client       *FXHTTPClient
+	credentials  api_credentials
+	auth_lock    sync.RWMutex
+	api          *dsmclient.Client
+}
+
+// credentials used to open a DSM session, kept to renew an expired session
+type api_credentials struct {
+	username  string
+	password  string
+	api_key   string
+	ldap_name string
+	app_id    string
+	// app authenticates with the client certificate of the TLS connection
+	client_certificate bool
+	// app authenticates with a JWT, the file is read again for every new session
+	jwt_token      string
+	jwt_token_file string
 }
 
-type dsm_plugin struct {
-	Id   string `json:"plugin_id"`
-	Name string `json:"name"`
+// api clients configured in this provider process
+var api_sessions struct {
+	sync.Mutex
+	clients []*api_client
 }
 
 // FXHTTPClient Rate Limited HTTP Client
@@ -51,16 +78,11 @@ type FXHTTPClient struct {
 
 // [-]: do FXHTTPClient
 func (c *FXHTTPClient) Do(req *retryablehttp.Request) (*http.Response, error) {
-	ctx := context.Background()
-	err := c.Ratelimiter.Wait(ctx)
+	err := c.Ratelimiter.Wait(req.Context())
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+	return c.logged(req)
 }
 
 // [-]: set FXHTTPClient
@@ -72,9 +94,143 @@ func NewClient(rl *rate.Limiter) *FXHTTPClient {
 	return c
 }
 
+// retry and rate limit settings of the provider block
+type http_client_options struct {
+	max_retries         int
+	retry_wait_min      int
+	retry_wait_max      int
+	requests_per_second float64
+	burst               int
+	ca_cert_pem         string
+	fingerprints        []string
+	client_cert_pem     string
+	client_key_pem      string
+}
+
+// context key marking requests that must not be replayed blindly
+type non_idempotent_key struct{}
+
+// [-]: flag a request context as non-idempotent
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, non_idempotent_key{}, true)
+}
+
+// [-]: retry policy of FXHTTPClient
+// Non-idempotent requests (e.g. creating a key) are only retried when DSM rejected
+// them without processing them: 429 Too Many Requests and 503 Service Unavailable.
+// Retrying them on connection errors or other server errors could create duplicates.
+func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if non_idempotent, _ := ctx.Value(non_idempotent_key{}).(bool); non_idempotent {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if err == nil && resp != nil {
+			return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable, nil
+		}
+		return false, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+// [-]: build the TLS configuration used to connect to DSM
+// A custom CA bundle replaces the system roots. A client certificate is presented
+// for certificate based app authentication. Pinned SHA-256 fingerprints are
+// checked against the certificate chain presented by DSM, even when insecure is set.
+func newTLSConfig(insecure bool, options http_client_options) (*tls.Config, error) {
+	tls_config := &tls.Config{InsecureSkipVerify: insecure}
+	if len(options.ca_cert_pem) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(options.ca_cert_pem)) {
+			return nil, fmt.Errorf("no valid PEM certificate found in the CA bundle")
+		}
+		tls_config.RootCAs = pool
+	}
+	if len(options.client_cert_pem) > 0 {
+		cert, err := tls.X509KeyPair([]byte(options.client_cert_pem), []byte(options.client_key_pem))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %s", err)
+		}
+		tls_config.Certificates = []tls.Certificate{cert}
+	}
+	if len(options.fingerprints) > 0 {
+		pins := make(map[string]bool)
+		for _, fingerprint := range options.fingerprints {
+			pin, err := normalizeFingerprint(fingerprint)
+			if err != nil {
+				return nil, err
+			}
+			pins[pin] = true
+		}
+		tls_config.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				sum := sha256.Sum256(cert.Raw)
+				if pins[hex.EncodeToString(sum[:])] {
+					return nil
+				}
+			}
+			return fmt.Errorf("DSM server certificate does not match any of server_cert_sha256_fingerprints")
+		}
+	}
+	return tls_config, nil
+}
+
+// [-]: normalize a SHA-256 fingerprint to lower case hex without separators
+func normalizeFingerprint(fingerprint string) (string, error) {
+	pin := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fingerprint))
+	if decoded, err := hex.DecodeString(pin); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+	}
+	return pin, nil
+}
+
+// [-]: build the FXHTTPClient shared by all API calls of a provider instance
+// Connections are kept alive and pooled, and the rate limiter is shared so the
+// limit applies to the whole provider instead of to each individual call.
+func newHTTPClient(insecure bool, timeout int, options http_client_options) (*FXHTTPClient, error) {
+	tls_config, err := newTLSConfig(insecure, options)
+	if err != nil {
+		return nil, err
+	}
+	tr := &http.Transport{
+		TLSClientConfig: tls_config,
+		Proxy:           http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   16, // terraform runs 10 operations in parallel by default
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	rl := rate.NewLimiter(rate.Limit(options.requests_per_second), options.burst)
+	client := NewClient(rl)
+	client.client.HTTPClient.Transport = tr
+	client.client.HTTPClient.Timeout = time.Duration(timeout) * time.Second
+	client.client.RetryMax = options.max_retries
+	client.client.RetryWaitMin = time.Duration(options.retry_wait_min) * time.Second
+	client.client.RetryWaitMax = time.Duration(options.retry_wait_max) * time.Second
+	client.client.CheckRetry = retryPolicy
+	// requests are logged through tflog instead of the default stderr logger
+	client.client.Logger = nil
+	client.client.RequestLogHook = countAttempts
+	// DefaultBackoff waits for the Retry-After header of 429 and 503 responses
+	client.client.Backoff = retryablehttp.DefaultBackoff
+	return client, nil
+}
+
+// [-]: drain and close a response body so the connection can be reused
+func drainBody(body io.ReadCloser) {
+	io.Copy(io.Discard, body)
+	body.Close()
+}
+
 // LDAP Authentication
-func getLDAPBody(endpoint string, ldap_name string, password string, user_email string, c *FXHTTPClient) (map[string]interface{}, error) {
-	ldap_req, err := retryablehttp.NewRequest("POST", fmt.Sprintf("%s/sys/v1/session/auth/discover", endpoint), nil)
+func getLDAPBody(ctx context.Context, endpoint string, ldap_name string, password string, user_email string, c *FXHTTPClient) (map[string]interface{}, error) {
+	ldap_req, err := retryablehttp.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/sys/v1/session/auth/discover", endpoint), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +241,11 @@ func getLDAPBody(endpoint string, ldap_name string, password string, user_email
 		return nil, err
 	}
 	ldap_req.SetBody(reqBody)
-	ldap_req.Close = true
 	r, err := c.Do(ldap_req)
 	if err != nil {
 		return nil, err
 	}
+	defer drainBody(r.Body)
 	var ldap_array []interface{}
 	err = json.NewDecoder(r.Body).Decode(&ldap_array)
 	if err != nil {
@@ -115,107 +271,163 @@ func getLDAPBody(endpoint string, ldap_name string, password string, user_email
 }
 
 // [-]: set api_client state
-func NewAPIClient(endpoint string, port int, username string, password string, api_key string, acct_id string, aws_profile string, aws_region string, azure_region string, insecure bool, timeout int, ldap_name string) (*api_client, error) {
-	// FIXME: clunky way of creating api_client session
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		Proxy:           http.ProxyFromEnvironment,
+func NewAPIClient(ctx context.Context, endpoint string, port int, username string, password string, api_key string, acct_id string, aws_profile string, aws_region string, azure_region string, insecure bool, timeout int, ldap_name string, app_id string, jwt_token string, jwt_token_file string, options http_client_options) (*api_client, error) {
+	// The client is shared by every API call made through this provider instance,
+	// so authentication already benefits from the connection pool.
+	client, err := newHTTPClient(insecure, timeout, options)
+	if err != nil {
+		return nil, err
 	}
+	newclient := api_client{
+		endpoint:     endpoint,
+		port:         port,
+		acct_id:      acct_id,
+		aws_profile:  aws_profile,
+		aws_region:   aws_region,
+		azure_region: azure_region,
+		insecure:     insecure,
+		timeout:      timeout,
+		client:       client,
+		credentials: api_credentials{
+			username:  username,
+			password:  password,
+			api_key:   api_key,
+			ldap_name: ldap_name,
+			app_id:    app_id,
 
-	rl := rate.NewLimiter(rate.Every(1*time.Second), 5) // 5 requests in every second
-	client := NewClient(rl)
-	client.client.HTTPClient.Transport = tr
-	client.client.HTTPClient.Timeout = time.Duration(timeout) * time.Second
+			client_certificate: len(options.client_cert_pem) > 0,
+			jwt_token:          jwt_token,
+			jwt_token_file:     jwt_token_file,
+		},
+	}
+
+	authtoken, err := newclient.openSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newclient.authtype = "Bearer "
+	newclient.authtoken = authtoken
+	newclient.api = dsmclient.New(&newclient)
+
+	api_sessions.Lock()
+	api_sessions.clients = append(api_sessions.clients, &newclient)
+	api_sessions.Unlock()
+	return &newclient, nil
+}
+
+// [-]: run the configured authentication flow and return the new session token
+// This is the flow executed while configuring the provider. It is executed
+// again whenever DSM rejects the current session token.
+func (obj *api_client) openSession(ctx context.Context) (string, error) {
+	client := obj.client
+	endpoint := obj.endpoint
+	username := obj.credentials.username
+	password := obj.credentials.password
+	api_key := obj.credentials.api_key
+	ldap_name := obj.credentials.ldap_name
 
 	resp := make(map[string]interface{})
-	var authtype string
-	var authtoken string
 
 	ldap_body := make(map[string]interface{})
 	if len(ldap_name) > 0 {
-		ldap_resp, err := getLDAPBody(endpoint, ldap_name, password, username, client)
+		ldap_resp, err := getLDAPBody(ctx, endpoint, ldap_name, password, username, client)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		ldap_body = ldap_resp
 	}
-	req, err := retryablehttp.NewRequest("POST", fmt.Sprintf("%s/sys/v1/session/auth", endpoint), nil)
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/sys/v1/session/auth", endpoint), nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	req.Close = true
 	if len(api_key) > 0 {
-		authtype = "Basic "
-		authtoken = api_key
-		req.Header.Add("Authorization", authtype+authtoken)
+		req.Header.Add("Authorization", "Basic "+api_key)
+	} else if obj.credentials.client_certificate {
+		// certificate based app authentication: the app is identified by its id,
+		// the client certificate of the TLS connection is the credential.
+		if len(obj.credentials.app_id) == 0 {
+			return "", fmt.Errorf("app_id should be specified with client_cert")
+		}
+		req.SetBasicAuth(obj.credentials.app_id, "")
+	} else if len(obj.credentials.jwt_token) > 0 || len(obj.credentials.jwt_token_file) > 0 {
+		// JWT app authentication: DSM validates the token against the trusted
+		// issuers configured on the app identified by app_id.
+		jwt_token, err := obj.credentials.jwt()
+		if err != nil {
+			return "", err
+		}
+		if len(obj.credentials.app_id) == 0 {
+			return "", fmt.Errorf("app_id should be specified with jwt_token or jwt_token_file")
+		}
+		req.SetBasicAuth(obj.credentials.app_id, jwt_token)
 	} else if len(ldap_body) > 0 {
 		req_body, err := json.Marshal(ldap_body)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		req.SetBody(req_body)
 	} else if len(username) > 0 && len(password) > 0 {
 		req.SetBasicAuth(username, password)
 	} else {
-		return nil, fmt.Errorf("unauthorized access to DSM")
+		return "", fmt.Errorf("unauthorized access to DSM")
 	}
 	r, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer r.Body.Close()
+	defer drainBody(r.Body)
 
 	if r.StatusCode == 401 {
-		return nil, fmt.Errorf("unauthorized access to DSM")
+		return "", fmt.Errorf("unauthorized access to DSM")
 	}
 
 	err = json.NewDecoder(r.Body).Decode(&resp)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	authtoken, ok := resp["access_token"].(string)
+	if !ok {
+		return "", fmt.Errorf("DSM session/auth response has no access_token")
 	}
 
 	account_object := map[string]interface{}{
-		"acct_id": acct_id,
+		"acct_id": obj.acct_id,
 	}
 
 	reqBody, err := json.Marshal(account_object)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	req, err = retryablehttp.NewRequest("POST", fmt.Sprintf("%s/sys/v1/session/select_account", endpoint), bytes.NewBuffer(reqBody))
+	req, err = retryablehttp.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/sys/v1/session/select_account", endpoint), bytes.NewBuffer(reqBody))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	authtype = "Bearer "
-	authtoken = resp["access_token"].(string)
-	req.Header.Add("Authorization", authtype+authtoken)
-	req.Close = true
+	req.Header.Add("Authorization", "Bearer "+authtoken)
 
 	r, err = client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer r.Body.Close()
+	defer drainBody(r.Body)
 
 	// EOF error: select_acccount has no return
 	_, err = io.ReadAll(r.Body)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	// Check if AWS profile is set and use it within API client
-	if len(aws_profile) > 0 {
+	if len(obj.aws_profile) > 0 {
 		// Specify profile to load for the session's config
 		cfg, err := config.LoadDefaultConfig(
-			context.TODO(),
-			config.WithSharedConfigProfile(aws_profile),
+			ctx,
+			config.WithSharedConfigProfile(obj.aws_profile),
 		)
 		if err == nil {
-			output, err := cfg.Credentials.Retrieve(context.TODO())
+			output, err := cfg.Credentials.Retrieve(ctx)
 			if err != nil {
-				return nil, err
+				return "", err
 			} else {
 				aws_temporary_credentials := map[string]interface{}{
 					"access_key":    output.AccessKeyID,
@@ -224,380 +436,193 @@ func NewAPIClient(endpoint string, port int, username string, password string, a
 				}
 				reqBody, err := json.Marshal(aws_temporary_credentials)
 				if err != nil {
-					return nil, err
+					return "", err
 				}
 
-				req, err := retryablehttp.NewRequest("POST", fmt.Sprintf("%s/sys/v1/session/aws_temporary_credentials", endpoint), bytes.NewBuffer(reqBody))
+				req, err := retryablehttp.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/sys/v1/session/aws_temporary_credentials", endpoint), bytes.NewBuffer(reqBody))
 				if err != nil {
-					return nil, err
+					return "", err
 				}
-				req.Header.Add("Authorization", "Bearer "+resp["access_token"].(string))
-				req.Close = true
+				req.Header.Add("Authorization", "Bearer "+authtoken)
 
 				r, err := client.Do(req)
 				if err != nil {
-					return nil, err
+					return "", err
 				}
-				defer r.Body.Close()
+				defer drainBody(r.Body)
 
 				// EOF error: aws_temporary_credentials has no return
 				_, err = io.ReadAll(r.Body)
 				if err != nil {
-					return nil, err
+					return "", err
 				}
 			}
 		} else {
-			return nil, err
+			return "", err
 		}
 	}
 
-	newclient := api_client{
-		endpoint:     endpoint,
-		port:         port,
-		authtoken:    authtoken,
-		authtype:     authtype,
-		acct_id:      acct_id,
-		aws_profile:  aws_profile,
-		aws_region:   aws_region,
-		azure_region: azure_region,
-		insecure:     insecure,
-		timeout:      timeout,
-	}
-	return &newclient, nil
+	return authtoken, nil
 }
 
-// [-]: call api with body
-func (obj *api_client) APICallBody(method string, url string, body map[string]interface{}) (map[string]interface{}, diag.Diagnostics) {
-	var diags diag.Diagnostics
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: obj.insecure},
-		Proxy:           http.ProxyFromEnvironment,
+// [-]: JWT used for app authentication
+// A token file is read for every session: CI systems rotate short lived tokens in place.
+func (creds api_credentials) jwt() (string, error) {
+	if len(creds.jwt_token) > 0 {
+		return creds.jwt_token, nil
 	}
-
-	rl := rate.NewLimiter(rate.Every(1*time.Second), 5) // 5 requests in every second
-	client := NewClient(rl)
-	client.client.HTTPClient.Transport = tr
-	client.client.HTTPClient.Timeout = time.Duration(obj.timeout) * time.Second
-
-	reqBody, err := json.MarshalIndent(&body, "", "\t")
+	jwt_token, err := os.ReadFile(creds.jwt_token_file)
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[Conversion] Unable to marshal request body",
-			Detail:   fmt.Sprintf("[Conversion] Body: %s - Err: %v", body, err),
-		})
-		return nil, diags
+		return "", fmt.Errorf("unable to read jwt_token_file: %s", err)
 	}
+	return strings.TrimSpace(string(jwt_token)), nil
+}
 
-	req, err := retryablehttp.NewRequest(method, fmt.Sprintf("%s/%s", obj.endpoint, url), bytes.NewBuffer(reqBody))
-	req.Close = true
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to prepare DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-		})
-	} else {
-		req.Header.Add("Authorization", obj.authtype+obj.authtoken)
+// [-]: current Authorization header value
+func (obj *api_client) authorization() string {
+	obj.auth_lock.RLock()
+	defer obj.auth_lock.RUnlock()
+	return obj.authtype + obj.authtoken
+}
 
-		r, err := client.Do(req)
-		if err != nil {
-			if r != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK]: Unable to call DSM provider API client",
-					Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, err),
-				})
-			} else {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK]: Unable to call DSM provider API client",
-					Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-				})
-			}
-		} else {
-			defer r.Body.Close()
-			bodyBytes, err := io.ReadAll(r.Body)
-			if err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK]: Unable to read DSM provider API response",
-					Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, err),
-				})
-			} else {
-				resp := make(map[string]interface{})
-				if r.StatusCode > 204 || r.StatusCode < 200 {
-					err = json.Unmarshal(bodyBytes, &resp)
-					if err != nil {
-						bodyString := string(bodyBytes)
-						diags = append(diags, diag.Diagnostic{
-							Severity: diag.Error,
-							Summary:  "[DSM SDK]: Call DSM provider API returned non-JSON",
-							Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, bodyString),
-						})
-					} else {
-						diags = append(diags, diag.Diagnostic{
-							Severity: diag.Error,
-							Summary:  "[DSM SDK]: Call DSM provider API returned error",
-							Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, resp),
-						})
-					}
-				} else {
-					err = json.Unmarshal(bodyBytes, &resp)
-					if err != nil {
-						bodyString := string(bodyBytes)
-						resp = map[string]interface{}{
-							"msg": bodyString,
-						}
-					}
-					return resp, nil
-				}
-			}
-		}
+// [-]: renew the session unless another request already did it
+func (obj *api_client) reauthenticate(ctx context.Context, stale string) error {
+	obj.auth_lock.Lock()
+	defer obj.auth_lock.Unlock()
+	if obj.authtype+obj.authtoken != stale {
+		return nil
+	}
+	authtoken, err := obj.openSession(ctx)
+	if err != nil {
+		return err
 	}
-	return nil, diags
+	obj.authtype = "Bearer "
+	obj.authtoken = authtoken
+	return nil
 }
 
-// [-]: call api without body
-func (obj *api_client) APICall(method string, url string) (map[string]interface{}, int, diag.Diagnostics) {
-	var diags diag.Diagnostics
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: obj.insecure},
-		Proxy:           http.ProxyFromEnvironment,
+// [-]: send an authenticated request
+// When DSM answers 401 the session has expired: authenticate again and retry once.
+func (obj *api_client) send(req *retryablehttp.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost {
+		req = req.WithContext(withoutRetry(req.Context()))
 	}
+	authorization := obj.authorization()
+	req.Header.Set("Authorization", authorization)
+	r, err := obj.client.Do(req)
+	if err != nil || r.StatusCode != http.StatusUnauthorized {
+		return r, err
+	}
+	drainBody(r.Body)
+	if err := obj.reauthenticate(req.Context(), authorization); err != nil {
+		return nil, fmt.Errorf("DSM session expired and re-authentication failed: %s", err)
+	}
+	req.Header.Set("Authorization", obj.authorization())
+	return obj.client.Do(req)
+}
 
-	rl := rate.NewLimiter(rate.Every(1*time.Second), 5) // 5 requests in every second
-	client := NewClient(rl)
-	client.client.HTTPClient.Transport = tr
-	client.client.HTTPClient.Timeout = time.Duration(obj.timeout) * time.Second
-
-	req, err := retryablehttp.NewRequest(method, fmt.Sprintf("%s/%s", obj.endpoint, url), nil)
-	req.Close = true
+// [-]: terminate the DSM session of this api_client
+func (obj *api_client) terminateSession() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/sys/v1/session/terminate", obj.endpoint), nil)
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to prepare DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-		})
-	} else {
-		req.Header.Add("Authorization", obj.authtype+obj.authtoken)
-
-		r, err := client.Do(req)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK]: Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-			})
-		} else {
-			defer r.Body.Close()
-
-			// FIXME: DELETE does not have any output
-			if method == "DELETE" {
-				// Only check status
-				if r.StatusCode == 204 {
-					return nil, r.StatusCode, nil
-				} else {
-					diags = append(diags, diag.Diagnostic{
-						Severity: diag.Error,
-						Summary:  "[DSM SDK]: DSM provider API call failed",
-						Detail:   fmt.Sprintf("[E]: API: %s %s: %d", method, url, r.StatusCode),
-					})
-					return nil, r.StatusCode, diags
-				}
-			}
-
-			bodyBytes, err := io.ReadAll(r.Body)
-			if err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK]: Unable to read DSM provider API response",
-					Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, err),
-				})
-			} else {
-				resp := make(map[string]interface{})
-				if r.StatusCode > 204 || r.StatusCode < 200 {
-					err = json.Unmarshal(bodyBytes, &resp)
-					if err != nil {
-						bodyString := string(bodyBytes)
-						diags = append(diags, diag.Diagnostic{
-							Severity: diag.Error,
-							Summary:  "[DSM SDK]: Call DSM provider API returned non-JSON",
-							Detail:   fmt.Sprintf("[E]: API: %s %s %d: %s", method, url, r.StatusCode, bodyString),
-						})
-					} else {
-						diags = append(diags, diag.Diagnostic{
-							Severity: diag.Error,
-							Summary:  "[DSM SDK]: Call DSM provider API returned error",
-							Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, resp),
-						})
-					}
-				} else {
-					err = json.Unmarshal(bodyBytes, &resp)
-					if err != nil {
-						bodyString := string(bodyBytes)
-						resp = map[string]interface{}{
-							"msg": bodyString,
-						}
-					}
-					return resp, r.StatusCode, nil
-				}
-			}
-		}
+		return err
+	}
+	req.Header.Set("Authorization", obj.authorization())
+	r, err := obj.client.Do(req)
+	if err != nil {
+		return err
 	}
-	return nil, 500, diags
+	drainBody(r.Body)
+	return nil
 }
 
-// [-]: call api without body - return as array
-func (obj *api_client) APICallList(method string, url string) ([]interface{}, diag.Diagnostics) {
-	var diags diag.Diagnostics
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: obj.insecure},
-		Proxy:           http.ProxyFromEnvironment,
+// TerminateSessions ends the DSM sessions opened by this provider process.
+// It is called once the plugin server has been shut down by terraform.
+func TerminateSessions() {
+	api_sessions.Lock()
+	defer api_sessions.Unlock()
+	for _, client := range api_sessions.clients {
+		client.terminateSession()
 	}
+	api_sessions.clients = nil
+}
 
-	rl := rate.NewLimiter(rate.Every(1*time.Second), 5) // 5 requests in every second
-	client := NewClient(rl)
-	client.client.HTTPClient.Transport = tr
-	client.client.HTTPClient.Timeout = time.Duration(obj.timeout) * time.Second
+// [-]: typed client of the DSM REST API
+func (obj *api_client) API() *dsmclient.Client {
+	return obj.api
+}
 
-	req, err := retryablehttp.NewRequest(method, fmt.Sprintf("%s/%s", obj.endpoint, url), nil)
-	req.Close = true
+// [-]: call api with body
+func (obj *api_client) APICallBody(ctx context.Context, method string, url string, body map[string]interface{}) (map[string]interface{}, error) {
+	reqBody, err := json.MarshalIndent(&body, "", "\t")
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to prepare DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-		})
-		return nil, diags
+		return nil, &DSMError{Method: method, Path: url, Err: fmt.Errorf("unable to marshal request body: %w", err)}
 	}
-	req.Header.Add("Authorization", obj.authtype+obj.authtoken)
+	return obj.call(ctx, method, url, reqBody)
+}
 
-	r, err := client.Do(req)
+// [-]: call api without body
+func (obj *api_client) APICall(ctx context.Context, method string, url string) (map[string]interface{}, error) {
+	return obj.call(ctx, method, url, nil)
+}
+
+// [-]: send a request and decode a JSON object response
+func (obj *api_client) call(ctx context.Context, method string, url string, body []byte) (map[string]interface{}, error) {
+	bodyBytes, err := obj.Do(ctx, method, url, body)
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to call DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-		})
-		return nil, diags
+		return nil, err
 	}
-	defer r.Body.Close()
-
 	// FIXME: DELETE does not have any output
 	if method == "DELETE" {
 		return nil, nil
 	}
-
-	bodybytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to read DSM provider API response body",
-			Detail:   fmt.Sprintf("[E]: API: %s %s: %s", method, url, err),
-		})
-		return nil, diags
-	}
-
-	var response []interface{}
-
-	if string(bodybytes[0]) == "[" {
-		err = json.Unmarshal(bodybytes, &response)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK]: Unable to unmarshall DSM provider API response body",
-				Detail:   fmt.Sprintf("[E]: API: %s %s: %s -> %s", method, url, err, bodybytes),
-			})
-			return nil, diags
-		}
-	} else {
-		var msgMapTemplate map[string]interface{}
-		err = json.Unmarshal(bodybytes, &msgMapTemplate)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK]: Unable to unmarshall DSM provider API response body",
-				Detail:   fmt.Sprintf("[E]: API: %s %s: %s -> %s", method, url, err, bodybytes),
-			})
-			return nil, diags
+	resp := make(map[string]interface{})
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		resp = map[string]interface{}{
+			"msg": string(bodyBytes),
 		}
-		items := msgMapTemplate["items"]
-		response = items.([]interface{})
 	}
-
-	return response, nil
+	return resp, nil
 }
 
-// [-]: find plugin - "Terraform Plugin" - return as array
-func (obj *api_client) FindPluginId(plugin_name string) ([]byte, diag.Diagnostics) {
-	var diags diag.Diagnostics
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: obj.insecure},
-		Proxy:           http.ProxyFromEnvironment,
+// [-]: send a request and return the raw response body, any non 2xx
+// response is returned as a *DSMError. This is the dsmclient.Transport.
+func (obj *api_client) Do(ctx context.Context, method string, url string, body []byte) ([]byte, error) {
+	var reqBody interface{}
+	if body != nil {
+		reqBody = body
 	}
-
-	rl := rate.NewLimiter(rate.Every(1*time.Second), 5) // 5 requests in every second
-	client := NewClient(rl)
-	client.client.HTTPClient.Transport = tr
-	client.client.HTTPClient.Timeout = time.Duration(obj.timeout) * time.Second
-
-	req, err := retryablehttp.NewRequest("GET", fmt.Sprintf("%s/sys/v1/plugins", obj.endpoint), nil)
-	req.Close = true
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", obj.endpoint, url), reqBody)
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK]: Unable to prepare DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/plugins: %s", err),
-		})
-	} else {
-		req.Header.Add("Authorization", obj.authtype+obj.authtoken)
+		return nil, &DSMError{Method: method, Path: url, Err: err}
+	}
+	r, err := obj.send(req)
+	if err != nil {
+		return nil, &DSMError{Method: method, Path: url, Err: err}
+	}
+	defer drainBody(r.Body)
 
-		r, err := client.Do(req)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK]: Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET sys/v1/plugins: %s", err),
-			})
-		} else {
-			defer r.Body.Close()
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, &DSMError{Method: method, Path: url, Err: fmt.Errorf("unable to read response: %w", err)}
+	}
+	if r.StatusCode > 204 || r.StatusCode < 200 {
+		return nil, newDSMError(method, url, r, bodyBytes)
+	}
+	return bodyBytes, nil
+}
 
-			bodyBytes, err := io.ReadAll(r.Body)
-			if err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK]: Unable to read DSM provider API response",
-					Detail:   fmt.Sprintf("[E]: API: GET sys/v1/plugins: %s", err),
-				})
-			} else {
-				var allPlugins []dsm_plugin
-				err = json.Unmarshal(bodyBytes, &allPlugins)
-				if err != nil {
-					diags = append(diags, diag.Diagnostic{
-						Severity: diag.Error,
-						Summary:  "[DSM SDK]: Unable to unmarshal provider API response body",
-						Detail:   fmt.Sprintf("[E]: API: GET sys/v1/plugins: %s", err),
-					})
-				}
-				resp := ""
-				for i := range allPlugins {
-					if allPlugins[i].Name == plugin_name {
-						resp = allPlugins[i].Id
-						break
-					}
-				}
-				if resp == "" {
-					diags = append(diags, diag.Diagnostic{
-						Severity: diag.Error,
-						Summary:  "[DSM SDK]: Unable to find Terraform Plugin through DSM provider",
-						Detail:   "[E]: API: GET sys/v1/plugins",
-					})
-				} else {
-					return []byte(resp), nil
-				}
-			}
+// [-]: call api without body - return as array
+// GET requests walk every page of the list and return all items.
+func (obj *api_client) APICallList(ctx context.Context, method string, url string) ([]interface{}, error) {
+	if method != "GET" {
+		if _, err := obj.Do(ctx, method, url, nil); err != nil {
+			return nil, err
 		}
+		return nil, nil
 	}
-	return nil, diags
+	return obj.api.List(ctx, url)
 }
+
@@ -0,0 +1,341 @@
+package dsm
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-provider-dsm/internal/dsmclient"
+)
+
+// [-]: retry and rate limit options of the tests, without waits between retries
+func testHTTPClientOptions() http_client_options {
+	return http_client_options{
+		max_retries:         2,
+		retry_wait_min:      0,
+		retry_wait_max:      0,
+		requests_per_second: 1000,
+		burst:               100,
+	}
+}
+
+// [-]: api_client with the HTTP client of the provider, talking to srv without a DSM session
+func testProviderAPIClient(t *testing.T, srv *httptest.Server, options http_client_options) *api_client {
+	client, err := newHTTPClient(false, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &api_client{endpoint: srv.URL, client: client, authtype: "Bearer ", authtoken: "token"}
+	obj.api = dsmclient.New(obj)
+	return obj
+}
+
+func TestRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	for _, c := range []struct {
+		name   string
+		ctx    context.Context
+		status int
+		err    error
+		retry  bool
+	}{
+		{"GET 429", ctx, http.StatusTooManyRequests, nil, true},
+		{"GET 500", ctx, http.StatusInternalServerError, nil, true},
+		{"GET 404", ctx, http.StatusNotFound, nil, false},
+		{"POST 429", withoutRetry(ctx), http.StatusTooManyRequests, nil, true},
+		{"POST 503", withoutRetry(ctx), http.StatusServiceUnavailable, nil, true},
+		{"POST 500", withoutRetry(ctx), http.StatusInternalServerError, nil, false},
+		{"POST connection error", withoutRetry(ctx), 0, context.DeadlineExceeded, false},
+	} {
+		var resp *http.Response
+		if c.status != 0 {
+			resp = &http.Response{StatusCode: c.status, Header: http.Header{}}
+		}
+		if retry, _ := retryPolicy(c.ctx, resp, c.err); retry != c.retry {
+			t.Errorf("%s: retry is %t, expected %t", c.name, retry, c.retry)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(withoutRetry(ctx))
+	cancel()
+	if retry, err := retryPolicy(canceled, &http.Response{StatusCode: http.StatusTooManyRequests}, nil); retry || err == nil {
+		t.Errorf("a canceled request is retried: %t, %v", retry, err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts int32
+	var first time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			if waited := time.Since(first); waited < time.Second {
+				t.Errorf("retried after %s, before Retry-After", waited)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"version": "4.0"}`))
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := testProviderAPIClient(t, srv, testHTTPClientOptions()).APICall(context.Background(), "GET", "sys/v1/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp["version"] != "4.0" || attempts != 3 {
+		t.Errorf("unexpected response %v after %d attempts", resp, attempts)
+	}
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := testProviderAPIClient(t, srv, testHTTPClientOptions()).APICallBody(context.Background(), "POST", "crypto/v1/keys", map[string]interface{}{"name": "key"})
+	if err == nil {
+		t.Fatal("expected the server error")
+	}
+	if attempts != 1 {
+		t.Errorf("POST was sent %d times", attempts)
+	}
+}
+
+// [-]: PEM of the certificate of a TLS test server
+func testServerCertPEM(srv *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+}
+
+func TestCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "4.0"}`))
+	}))
+	defer srv.Close()
+
+	options := testHTTPClientOptions()
+	options.max_retries = 0
+	if _, err := testProviderAPIClient(t, srv, options).APICall(context.Background(), "GET", "sys/v1/version"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("a server outside of the system roots is trusted: %v", err)
+	}
+
+	options.ca_cert_pem = testServerCertPEM(srv)
+	if _, err := testProviderAPIClient(t, srv, options).APICall(context.Background(), "GET", "sys/v1/version"); err != nil {
+		t.Fatalf("the server of the CA bundle is not trusted: %v", err)
+	}
+
+	options.ca_cert_pem = "not a certificate"
+	if _, err := newHTTPClient(false, 10, options); err == nil {
+		t.Fatal("a CA bundle without certificates is accepted")
+	}
+}
+
+func TestServerCertificatePinning(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "4.0"}`))
+	}))
+	defer srv.Close()
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	pin := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	options := testHTTPClientOptions()
+	options.max_retries = 0
+	options.ca_cert_pem = testServerCertPEM(srv)
+	options.fingerprints = []string{strings.Repeat("ab", sha256.Size)}
+	if _, err := testProviderAPIClient(t, srv, options).APICall(context.Background(), "GET", "sys/v1/version"); err == nil || !strings.Contains(err.Error(), "server_cert_sha256_fingerprints") {
+		t.Fatalf("the handshake with a certificate that does not match the pins succeeded: %v", err)
+	}
+
+	// the pin is also checked with insecure, in the colon separated format of openssl
+	var colon_pin []string
+	for i := 0; i < len(pin); i += 2 {
+		colon_pin = append(colon_pin, pin[i:i+2])
+	}
+	options.ca_cert_pem = ""
+	options.fingerprints = []string{strings.Repeat("ab", sha256.Size), strings.Join(colon_pin, ":")}
+	client, err := newHTTPClient(true, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &api_client{endpoint: srv.URL, client: client, authtype: "Bearer ", authtoken: "token"}
+	if _, err := obj.APICall(context.Background(), "GET", "sys/v1/version"); err != nil {
+		t.Fatalf("the handshake with a pinned certificate failed: %v", err)
+	}
+
+	options.fingerprints = []string{"abcd"}
+	if _, err := newHTTPClient(true, 10, options); err == nil {
+		t.Fatal("a fingerprint that is not SHA-256 is accepted")
+	}
+}
+
+// [-]: self-signed client certificate and key of an app, in PEM format
+func testClientCertificate(t *testing.T) (string, string, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key_der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert_pem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key_pem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key_der})
+	return string(cert_pem), string(key_pem), der
+}
+
+func TestClientCertificateAuthentication(t *testing.T) {
+	cert_pem, key_pem, cert_der := testClientCertificate(t)
+	app_id := generateRandomID()
+	var authenticated, called int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || !bytes.Equal(r.TLS.PeerCertificates[0].Raw, cert_der) {
+			t.Errorf("%s %s without the client certificate", r.Method, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/sys/v1/session/auth":
+			// the app is identified by its id, the certificate is the credential
+			if user, password, ok := r.BasicAuth(); !ok || user != app_id || password != "" {
+				t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			}
+			atomic.AddInt32(&authenticated, 1)
+			w.Write([]byte(`{"access_token": "session-1"}`))
+		case "/sys/v1/session/select_account":
+		case "/sys/v1/version":
+			atomic.AddInt32(&called, 1)
+			w.Write([]byte(`{"version": "4.0"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	options := testHTTPClientOptions()
+	options.ca_cert_pem = testServerCertPEM(srv)
+	options.client_cert_pem = cert_pem
+	options.client_key_pem = key_pem
+	obj, err := NewAPIClient(context.Background(), srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", app_id, "", "", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := obj.APICall(context.Background(), "GET", "sys/v1/version"); err != nil {
+		t.Fatal(err)
+	}
+	if authenticated != 1 || called != 1 {
+		t.Errorf("%d authentications and %d calls", authenticated, called)
+	}
+
+	if _, err := NewAPIClient(context.Background(), srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", "", "", "", options); err == nil || !strings.Contains(err.Error(), "app_id") {
+		t.Errorf("client certificate authentication without app_id: %v", err)
+	}
+	options.client_key_pem = "not a key"
+	if _, err := newHTTPClient(false, 10, options); err == nil {
+		t.Error("a client certificate without a valid key is accepted")
+	}
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	app_id := generateRandomID()
+	var lock sync.Mutex
+	var tokens []string
+	session := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.URL.Path {
+		case "/sys/v1/session/auth":
+			user, jwt_token, ok := r.BasicAuth()
+			if !ok || user != app_id {
+				t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			}
+			tokens = append(tokens, jwt_token)
+			session = fmt.Sprintf("session-%d", len(tokens))
+			w.Write([]byte(fmt.Sprintf(`{"access_token": "%s"}`, session)))
+		case "/sys/v1/session/select_account":
+		case "/sys/v1/version":
+			if r.Header.Get("Authorization") != "Bearer "+session {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"version": "4.0"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := NewAPIClient(ctx, srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", app_id, "jwt-1", "", testHTTPClientOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0] != "jwt-1" {
+		t.Fatalf("authenticated with %v", tokens)
+	}
+
+	// the token file is read again when the session expires, to pick up a renewed token
+	jwt_token_file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwt_token_file, []byte("jwt-2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := NewAPIClient(ctx, srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", app_id, "", jwt_token_file, testHTTPClientOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jwt_token_file, []byte("jwt-3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	session = "expired"
+	lock.Unlock()
+	if _, err := obj.APICall(ctx, "GET", "sys/v1/version"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tokens, ",") != "jwt-1,jwt-2,jwt-3" {
+		t.Errorf("authenticated with %v", tokens)
+	}
+
+	if _, err := NewAPIClient(ctx, srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", "", "jwt-1", "", testHTTPClientOptions()); err == nil || !strings.Contains(err.Error(), "app_id") {
+		t.Errorf("JWT authentication without app_id: %v", err)
+	}
+	if _, err := NewAPIClient(ctx, srv.URL, 443, "", "", "", generateRandomID(), "", "", "", false, 10, "", app_id, "", filepath.Join(t.TempDir(), "missing"), testHTTPClientOptions()); err == nil || !strings.Contains(err.Error(), "jwt_token_file") {
+		t.Errorf("JWT authentication with a missing token file: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// **********
+// Terraform Provider - DSM: quorum approval
+// **********
+
+package dsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
+)
+
+// how long create and update wait for a quorum approval by default
+const approval_default_timeout = 30 * time.Minute
+
+// how often a pending approval request is polled
+var approval_poll_interval = 10 * time.Second
+
+// [-]: create and update timeouts of a resource whose operations can require quorum approval
+func approvalTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(approval_default_timeout),
+		Update: schema.DefaultTimeout(approval_default_timeout),
+	}
+}
+
+// [-]: approvalTimeouts of a resource whose delete can require quorum approval too
+func approvalDeleteTimeouts() *schema.ResourceTimeout {
+	timeouts := approvalTimeouts()
+	timeouts.Delete = schema.DefaultTimeout(approval_default_timeout)
+	return timeouts
+}
+
+// [-]: the timeout of the running create or update
+func approvalTimeout(d *schema.ResourceData) time.Duration {
+	if d.IsNewResource() {
+		return d.Timeout(schema.TimeoutCreate)
+	}
+	return d.Timeout(schema.TimeoutUpdate)
+}
+
+// [-]: call api with body, waiting for quorum approval when DSM requires it
+// The operation is sent as is first. When DSM answers that it requires
+// approval, an approval request is filed for it and polled until the quorum
+// approves or denies it or timeout is reached. The result of the approved
+// operation is returned. Progress is returned as warning diagnostics.
+func (obj *api_client) APICallBodyWithApproval(ctx context.Context, method string, url string, body map[string]interface{}, timeout time.Duration) (map[string]interface{}, diag.Diagnostics, error) {
+	resp, err := obj.APICallBody(ctx, method, url, body)
+	if !IsApprovalRequired(err) {
+		return resp, nil, err
+	}
+	tflog.Info(ctx, "DSM operation requires quorum approval", map[string]interface{}{
+		"method": method,
+		"path":   url,
+	})
+	return obj.approvedCall(ctx, method, url, body, timeout)
+}
+
+// [-]: file an approval request for an operation, wait for the quorum and
+// return the result of the operation
+func (obj *api_client) approvedCall(ctx context.Context, method string, url string, body map[string]interface{}, timeout time.Duration) (map[string]interface{}, diag.Diagnostics, error) {
+	var diags diag.Diagnostics
+
+	approval, err := obj.api.CreateApprovalRequest(ctx, map[string]interface{}{
+		"operation": url,
+		"method":    method,
+		"body":      body,
+	})
+	if err != nil {
+		return nil, diags, err
+	}
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "[DSM SDK] Operation requires quorum approval",
+		Detail:   fmt.Sprintf("[W]: API: %s %s: waiting up to %s for approval request %s", method, url, timeout, approval.Request_id),
+	})
+
+	request_id := approval.Request_id
+	approval, err = obj.waitForApproval(ctx, request_id, timeout)
+	if err != nil {
+		return nil, diags, obj.withdrawApprovalRequest(ctx, request_id, &DSMError{Method: method, Path: url, Err: err})
+	}
+	switch approval.Status {
+	case dsmclient.ApprovalApproved:
+	case dsmclient.ApprovalPending:
+		return nil, diags, obj.withdrawApprovalRequest(ctx, request_id, &DSMError{Method: method, Path: url, Err: fmt.Errorf("approval request %s is still pending after %s", request_id, timeout)})
+	default:
+		return nil, diags, &DSMError{Method: method, Path: url, Err: fmt.Errorf("approval request %s is %s", approval.Request_id, approval.Status)}
+	}
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "[DSM SDK] Operation approved by quorum",
+		Detail:   fmt.Sprintf("[W]: API: %s %s: approval request %s is approved", method, url, approval.Request_id),
+	})
+
+	result, err := obj.api.ApprovalRequestResult(ctx, approval.Request_id)
+	if err != nil {
+		return nil, diags, err
+	}
+	if !approvalSucceeded(result.Status) {
+		return nil, diags, &DSMError{
+			StatusCode: result.Status,
+			Message:    fmt.Sprintf("%v", result.Body),
+			Method:     method,
+			Path:       url,
+		}
+	}
+	if method == "DELETE" {
+		return nil, diags, nil
+	}
+	if resp, ok := result.Body.(map[string]interface{}); ok {
+		return resp, diags, nil
+	}
+	return map[string]interface{}{"msg": result.Body}, diags, nil
+}
+
+// [-]: delete an approval request that was not approved in time
+// The request is not in the state, the next apply files a new one: were the
+// old one left pending, approving both would run the operation twice.
+func (obj *api_client) withdrawApprovalRequest(ctx context.Context, request_id string, err *DSMError) error {
+	// the context of the operation may be canceled already
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if derr := obj.api.DeleteApprovalRequest(ctx, request_id); derr != nil && !IsNotFound(derr) {
+		err.Err = fmt.Errorf("%w, it could not be withdrawn and should be denied in DSM: %v", err.Err, derr)
+		return err
+	}
+	err.Err = fmt.Errorf("%w, it has been withdrawn", err.Err)
+	return err
+}
+
+// [-]: poll an approval request until it is no longer pending or timeout is reached
+func (obj *api_client) waitForApproval(ctx context.Context, request_id string, timeout time.Duration) (*dsmclient.ApprovalRequest, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(approval_poll_interval)
+	defer ticker.Stop()
+
+	for {
+		approval, err := obj.api.GetApprovalRequest(ctx, request_id)
+		if err != nil {
+			return nil, err
+		}
+		if approval.Status != dsmclient.ApprovalPending {
+			return approval, nil
+		}
+		tflog.Info(ctx, "Waiting for quorum approval", map[string]interface{}{
+			"request_id": request_id,
+			"operation":  approval.Operation,
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return approval, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// [-]: true when the status of an approval result is a success
+func approvalSucceeded(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// [-]: decode the response of an operation that may have required approval into a typed model
+func decodeApprovalResponse(resp map[string]interface{}, out interface{}) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
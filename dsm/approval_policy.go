@@ -0,0 +1,441 @@
+// **********
+// Terraform Provider - DSM: quorum approval policies
+// **********
+
+package dsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// nesting of the quorums of an approval policy: the members of the innermost
+// quorum are users and apps only
+const approval_quorum_depth = 3
+
+// protection options of a group and of an account approval policy
+var (
+	approval_group_options   = []string{"protect_crypto_operations", "protect_manage_operations"}
+	approval_account_options = []string{"manage_groups", "protect_authentication_methods", "protect_cryptographic_policy", "protect_logging_config"}
+)
+
+// [-]: approval_policy block of a group, or of an account with account set
+// A computed block has the same nesting, with every option computed.
+func approvalPolicySchema(description string, account bool, computed bool) *schema.Schema {
+	quorum := approvalQuorumSchema(approval_quorum_depth)
+	quorum.Description = "The quorum that approves the protected operations."
+	quorum.Optional = false
+	quorum.Required = true
+	options := map[string]*schema.Schema{
+		"quorum": quorum,
+	}
+	descriptions := map[string]string{
+		"protect_crypto_operations":      "Cryptographic operations with the security objects of the group require approval.",
+		"protect_manage_operations":      "Management operations of the group and of its security objects require approval.",
+		"manage_groups":                  "Creating and changing groups of the account require approval.",
+		"protect_authentication_methods": "Changes of the authentication methods of the account require approval.",
+		"protect_cryptographic_policy":   "Changes of the cryptographic policy of the account require approval.",
+		"protect_logging_config":         "Changes of the logging configuration of the account require approval.",
+	}
+	protect_options := approval_group_options
+	if account {
+		protect_options = approval_account_options
+	}
+	for _, option := range protect_options {
+		options[option] = &schema.Schema{
+			Description: descriptions[option] + " Default is false.",
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+		}
+	}
+	if !account {
+		options["protect_permissions"] = &schema.Schema{
+			Description: "The group permissions whose operations require approval, e.g. ROTATE_SOBJECTS or EXPORT_SOBJECTS.",
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`), "should be a group permission, e.g. ROTATE_SOBJECTS"),
+			},
+		}
+	}
+	policy := &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: options,
+		},
+	}
+	if computed {
+		approvalPolicyComputed(policy)
+	}
+	return policy
+}
+
+// [-]: quorum block with the given levels of nested quorums
+func approvalQuorumSchema(depth int) *schema.Schema {
+	members := map[string]*schema.Schema{
+		"user": {
+			Description:  "The UUID of a user of the quorum.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsUUID,
+		},
+		"app": {
+			Description:  "The UUID of an app of the quorum.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsUUID,
+		},
+	}
+	if depth > 1 {
+		members["quorum"] = approvalQuorumSchema(depth - 1)
+	}
+	return &schema.Schema{
+		Description: "A nested quorum, which counts as one approval when it approves.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"n": {
+					Description:  "The number of members whose approval is required. It should not be greater than the number of members.",
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"members": {
+					Description: "The members of the quorum. Each member is exactly one of user, app or quorum.",
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Elem: &schema.Resource{
+						Schema: members,
+					},
+				},
+				"require_2fa": {
+					Description: "The approvers should authenticate with two factors. Default is false.",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+				"require_password": {
+					Description: "The approvers should enter their password. Default is false.",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+			},
+		},
+	}
+}
+
+// [-]: turn an approval policy schema into its computed counterpart
+func approvalPolicyComputed(s *schema.Schema) {
+	s.Required = false
+	s.Optional = false
+	s.Computed = true
+	s.Default = nil
+	s.ValidateFunc = nil
+	s.MinItems = 0
+	s.MaxItems = 0
+	switch elem := s.Elem.(type) {
+	case *schema.Resource:
+		for _, option := range elem.Schema {
+			approvalPolicyComputed(option)
+		}
+	case *schema.Schema:
+		elem.ValidateFunc = nil
+	}
+}
+
+// [-]: approval_policy request from the approval_policy block, nil without one
+// The quorum of a group is at the top of its policy, the quorum of an account
+// under policy.
+func approvalPolicyWrite(approval_policy []interface{}, account bool) map[string]interface{} {
+	if len(approval_policy) == 0 || approval_policy[0] == nil {
+		return nil
+	}
+	policy := approval_policy[0].(map[string]interface{})
+	quorum := approvalQuorumWrite(policy["quorum"].([]interface{}))
+	request := make(map[string]interface{})
+	if account {
+		request["policy"] = map[string]interface{}{"quorum": quorum}
+		for _, option := range approval_account_options {
+			request[option] = policy[option].(bool)
+		}
+		return request
+	}
+	request["quorum"] = quorum
+	for _, option := range approval_group_options {
+		request[option] = policy[option].(bool)
+	}
+	if permissions, ok := policy["protect_permissions"].(*schema.Set); ok && permissions.Len() > 0 {
+		protect_permissions := make([]string, 0, permissions.Len())
+		for _, permission := range permissions.List() {
+			protect_permissions = append(protect_permissions, permission.(string))
+		}
+		sort.Strings(protect_permissions)
+		request["protect_permissions"] = protect_permissions
+	}
+	return request
+}
+
+func approvalQuorumWrite(quorum_list []interface{}) map[string]interface{} {
+	if len(quorum_list) == 0 || quorum_list[0] == nil {
+		return nil
+	}
+	quorum := quorum_list[0].(map[string]interface{})
+	members := make([]interface{}, 0)
+	for _, member := range quorum["members"].([]interface{}) {
+		member, _ := member.(map[string]interface{})
+		if user, _ := member["user"].(string); len(user) > 0 {
+			members = append(members, map[string]interface{}{"user": user})
+		} else if app, _ := member["app"].(string); len(app) > 0 {
+			members = append(members, map[string]interface{}{"app": app})
+		} else if nested, _ := member["quorum"].([]interface{}); len(nested) > 0 {
+			members = append(members, map[string]interface{}{"quorum": approvalQuorumWrite(nested)})
+		}
+	}
+	return map[string]interface{}{
+		"n":                quorum["n"].(int),
+		"members":          members,
+		"require_2fa":      quorum["require_2fa"].(bool),
+		"require_password": quorum["require_password"].(bool),
+	}
+}
+
+// [-]: approval_policy block from the approval_policy of DSM
+// DSM may return the members of a quorum in another order. A quorum that only
+// differs in the order of its members keeps the order of the old block.
+func approvalPolicyRead(dsm_policy map[string]interface{}, old_policy []interface{}, account bool) []interface{} {
+	dsm_quorum, ok := dsm_policy["quorum"].(map[string]interface{})
+	if !ok {
+		inner, _ := dsm_policy["policy"].(map[string]interface{})
+		dsm_quorum, ok = inner["quorum"].(map[string]interface{})
+	}
+	if !ok {
+		return []interface{}{}
+	}
+	quorum := approvalQuorumRead(dsm_quorum)
+	if len(old_policy) > 0 && old_policy[0] != nil {
+		if old_quorum, _ := old_policy[0].(map[string]interface{})["quorum"].([]interface{}); len(old_quorum) > 0 && old_quorum[0] != nil {
+			quorum = approvalQuorumState(old_quorum[0].(map[string]interface{}), quorum)
+		}
+	}
+	policy := map[string]interface{}{
+		"quorum": []interface{}{quorum},
+	}
+	protect_options := approval_group_options
+	if account {
+		protect_options = approval_account_options
+	}
+	for _, option := range protect_options {
+		policy[option], _ = dsm_policy[option].(bool)
+	}
+	if !account {
+		protect_permissions, _ := dsm_policy["protect_permissions"].([]interface{})
+		policy["protect_permissions"] = protect_permissions
+	}
+	return []interface{}{policy}
+}
+
+func approvalQuorumRead(dsm_quorum map[string]interface{}) map[string]interface{} {
+	n, _ := dsm_quorum["n"].(float64)
+	require_2fa, _ := dsm_quorum["require_2fa"].(bool)
+	require_password, _ := dsm_quorum["require_password"].(bool)
+	members := make([]interface{}, 0)
+	dsm_members, _ := dsm_quorum["members"].([]interface{})
+	for _, dsm_member := range dsm_members {
+		dsm_member, _ := dsm_member.(map[string]interface{})
+		member := make(map[string]interface{})
+		if user, ok := dsm_member["user"].(string); ok {
+			member["user"] = user
+		}
+		if app, ok := dsm_member["app"].(string); ok {
+			member["app"] = app
+		}
+		if nested, ok := dsm_member["quorum"].(map[string]interface{}); ok {
+			member["quorum"] = []interface{}{approvalQuorumRead(nested)}
+		}
+		members = append(members, member)
+	}
+	return map[string]interface{}{
+		"n":                int(n),
+		"members":          members,
+		"require_2fa":      require_2fa,
+		"require_password": require_password,
+	}
+}
+
+// The members of the old quorum come first, in their order, and the members
+// only DSM has follow in the order of DSM.
+func approvalQuorumState(old_quorum map[string]interface{}, quorum map[string]interface{}) map[string]interface{} {
+	if approvalQuorumKey(old_quorum) == approvalQuorumKey(quorum) {
+		return old_quorum
+	}
+	members := quorum["members"].([]interface{})
+	used := make([]bool, len(members))
+	ordered := make([]interface{}, 0, len(members))
+	old_members, _ := old_quorum["members"].([]interface{})
+	for _, old_member := range old_members {
+		old_key := approvalMemberKey(old_member)
+		for i, member := range members {
+			if !used[i] && approvalMemberKey(member) == old_key {
+				used[i] = true
+				ordered = append(ordered, old_member)
+				break
+			}
+		}
+	}
+	for i, member := range members {
+		if !used[i] {
+			ordered = append(ordered, member)
+		}
+	}
+	quorum["members"] = ordered
+	return quorum
+}
+
+// [-]: identity of a quorum regardless of the order of its members
+func approvalQuorumKey(quorum map[string]interface{}) string {
+	n, _ := quorum["n"].(int)
+	require_2fa, _ := quorum["require_2fa"].(bool)
+	require_password, _ := quorum["require_password"].(bool)
+	members, _ := quorum["members"].([]interface{})
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		keys = append(keys, approvalMemberKey(member))
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("quorum(%d,%t,%t:%s)", n, require_2fa, require_password, strings.Join(keys, ","))
+}
+
+func approvalMemberKey(member interface{}) string {
+	block, _ := member.(map[string]interface{})
+	if user, _ := block["user"].(string); len(user) > 0 {
+		return "user:" + user
+	}
+	if app, _ := block["app"].(string); len(app) > 0 {
+		return "app:" + app
+	}
+	if nested, _ := block["quorum"].([]interface{}); len(nested) > 0 && nested[0] != nil {
+		return approvalQuorumKey(nested[0].(map[string]interface{}))
+	}
+	return ""
+}
+
+// [-]: validate the quorums of the approval_policy block at plan time
+// The configuration is walked as is, so that members with values known only
+// at apply time still count.
+func validateApprovalPolicyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	policy := d.GetRawConfig().GetAttr("approval_policy")
+	if !policy.IsKnown() || policy.IsNull() || policy.LengthInt() == 0 {
+		return nil
+	}
+	quorum := policy.Index(cty.NumberIntVal(0)).GetAttr("quorum")
+	if !quorum.IsKnown() || quorum.IsNull() || quorum.LengthInt() == 0 {
+		return nil
+	}
+	return validateApprovalQuorum(cty.GetAttrPath("approval_policy").IndexInt(0).GetAttr("quorum").IndexInt(0), quorum.Index(cty.NumberIntVal(0)))
+}
+
+func validateApprovalQuorum(path cty.Path, quorum cty.Value) error {
+	members := quorum.GetAttr("members")
+	if !members.IsKnown() || members.IsNull() {
+		return nil
+	}
+	count := members.LengthInt()
+	if n := quorum.GetAttr("n"); n.IsKnown() && !n.IsNull() {
+		if n_int, _ := n.AsBigFloat().Int64(); int(n_int) > count {
+			return path.GetAttr("n").NewErrorf("n is %d but the quorum has %d members", n_int, count)
+		}
+	}
+	for i, it := 0, members.ElementIterator(); it.Next(); i++ {
+		_, member := it.Element()
+		member_path := path.GetAttr("members").IndexInt(i)
+		kinds := 0
+		nested := cty.NilVal
+		for _, kind := range []string{"user", "app", "quorum"} {
+			if !member.Type().HasAttribute(kind) {
+				continue
+			}
+			value := member.GetAttr(kind)
+			switch {
+			case value.IsNull():
+			case kind != "quorum" || !value.IsKnown():
+				kinds++
+			case value.LengthInt() > 0:
+				kinds++
+				nested = value.Index(cty.NumberIntVal(0))
+			}
+		}
+		if kinds != 1 {
+			return member_path.NewErrorf("member %d of the quorum should be exactly one of user, app or quorum", i)
+		}
+		if nested != cty.NilVal {
+			if err := validateApprovalQuorum(member_path.GetAttr("quorum").IndexInt(0), nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// [-]: state upgrader of the approval_policy JSON string of the given schema version to the block
+func approvalPolicyStateUpgrader(resource *schema.Resource, version int, account bool) schema.StateUpgrader {
+	previous_schema := make(map[string]*schema.Schema, len(resource.Schema))
+	for key, value := range resource.Schema {
+		previous_schema[key] = value
+	}
+	previous_schema["approval_policy"] = approvalPolicyJSONSchema()
+	// the cryptographic policies were JSON strings as well
+	if _, ok := previous_schema["cryptographic_policy"]; ok {
+		previous_schema["cryptographic_policy"] = cryptoPolicyJSONSchema()
+	}
+	previous := &schema.Resource{
+		Schema:   previous_schema,
+		Timeouts: resource.Timeouts,
+	}
+	return schema.StateUpgrader{
+		Version: version,
+		Type:    previous.CoreConfigSchema().ImpliedType(),
+		Upgrade: func(ctx context.Context, raw_state map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+			return approvalPolicyStateUpgrade(raw_state, account), nil
+		},
+	}
+}
+
+// [-]: approval_policy of the schema versions before the block
+func approvalPolicyJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Computed: true,
+	}
+}
+
+// The JSON was either the configuration or, for the computed attributes, a
+// formatted map that does not parse. Without a policy the next read adds the
+// approval policy of DSM.
+func approvalPolicyStateUpgrade(raw_state map[string]interface{}, account bool) map[string]interface{} {
+	policy_json, _ := raw_state["approval_policy"].(string)
+	raw_state["approval_policy"] = []interface{}{}
+	var dsm_policy map[string]interface{}
+	if err := json.Unmarshal([]byte(policy_json), &dsm_policy); err != nil {
+		return raw_state
+	}
+	raw_state["approval_policy"] = approvalPolicyRead(dsm_policy, nil, account)
+	return raw_state
+}
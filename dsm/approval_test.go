@@ -0,0 +1,120 @@
+package dsm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/time/rate"
+
+	"terraform-provider-dsm/internal/dsmclient"
+)
+
+// [-]: api_client talking to a test server without a DSM session
+func testAPIClient(t *testing.T, handler http.Handler) *api_client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := NewClient(rate.NewLimiter(rate.Inf, 1))
+	client.client.Logger = nil
+	client.client.RetryMax = 0
+	client.client.CheckRetry = retryablehttp.DefaultRetryPolicy
+	obj := &api_client{endpoint: srv.URL, client: client, authtype: "Bearer ", authtoken: "token"}
+	obj.api = dsmclient.New(obj)
+	return obj
+}
+
+func TestAPICallBodyWithApproval(t *testing.T) {
+	approval_poll_interval = 10 * time.Millisecond
+	var polls int32
+	obj := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/crypto/v1/keys":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("This operation requires quorum approval"))
+		case r.Method == "POST" && r.URL.Path == "/sys/v1/approval_requests":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["operation"] != "crypto/v1/keys" || body["method"] != "POST" {
+				t.Errorf("unexpected approval request: %v", body)
+			}
+			w.Write([]byte(`{"request_id": "r1", "status": "PENDING"}`))
+		case r.Method == "GET" && r.URL.Path == "/sys/v1/approval_requests/r1":
+			status := "PENDING"
+			if atomic.AddInt32(&polls, 1) > 2 {
+				status = "APPROVED"
+			}
+			w.Write([]byte(`{"request_id": "r1", "status": "` + status + `"}`))
+		case r.Method == "POST" && r.URL.Path == "/sys/v1/approval_requests/r1/result":
+			w.Write([]byte(`{"status": 201, "body": {"kid": "k1"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	resp, diags, err := obj.APICallBodyWithApproval(context.Background(), "POST", "crypto/v1/keys", map[string]interface{}{"name": "k"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp["kid"] != "k1" {
+		t.Fatalf("unexpected result: %v", resp)
+	}
+	if len(diags) != 2 || diags.HasError() || !strings.Contains(diags[0].Detail, "r1") {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestApprovalDeniedAndTimeout(t *testing.T) {
+	approval_poll_interval = 10 * time.Millisecond
+	status := "DENIED"
+	var withdrawn int32
+	obj := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/sys/v1/approval_requests":
+			w.Write([]byte(`{"request_id": "r2", "status": "PENDING"}`))
+		case r.Method == "DELETE" && r.URL.Path == "/sys/v1/approval_requests/r2":
+			atomic.AddInt32(&withdrawn, 1)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/sys/v1/approval_requests/r2":
+			w.Write([]byte(`{"request_id": "r2", "status": "` + status + `"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	_, _, err := obj.approvedCall(context.Background(), "PATCH", "sys/v1/groups/g1", map[string]interface{}{}, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "DENIED") {
+		t.Fatalf("expected denied: %v", err)
+	}
+	if withdrawn != 0 {
+		t.Fatal("a denied approval request is withdrawn")
+	}
+
+	// a request that is not approved in time is withdrawn, so that the next
+	// apply does not file a second one for the same operation
+	status = "PENDING"
+	_, _, err = obj.approvedCall(context.Background(), "PATCH", "sys/v1/groups/g1", map[string]interface{}{}, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "still pending") || !strings.Contains(err.Error(), "withdrawn") {
+		t.Fatalf("expected timeout: %v", err)
+	}
+	if withdrawn != 1 {
+		t.Fatalf("approval request withdrawn %d times after timeout", withdrawn)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = obj.approvedCall(ctx, "PATCH", "sys/v1/groups/g1", map[string]interface{}{}, time.Minute)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "withdrawn") {
+		t.Fatalf("expected canceled: %v", err)
+	}
+	if withdrawn != 2 {
+		t.Fatalf("approval request withdrawn %d times after cancel", withdrawn)
+	}
+}
@@ -9,6 +9,7 @@
 package dsm
 
 import (
+	"context"
 	//"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
@@ -19,10 +20,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"golang.org/x/crypto/ssh"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 func parseHmg(d *schema.ResourceData, hmg map[string]interface{}) interface{} {
@@ -142,61 +146,6 @@ func compute_add_and_del_arrays(old_array interface{}, new_array interface{}) ([
 	return add_array_ids, del_array_ids
 }
 
-func substr(input string, start int, length int) string {
-        asRunes := []rune(input)
-
-        if start >= len(asRunes) {
-                return ""
-        }
-
-        if start+length > len(asRunes) {
-                length = len(asRunes) - start
-        }
-
-        return string(asRunes[start : start+length])
-}
-
-// To write/update the security object rotation_policy
-func sobj_rotation_policy_write(rp map[string]interface{}) map[string]interface{} {
-        rotation_policy := make(map[string]interface{})
-        for k, v := range  rp{
-            /* while sending the request, interval_days should be assigned as an integer.
-               Hence it is converted to integer from the string.
-            */
-            if k == "interval_days" || k == "interval_months" {
-                val, _ := strconv.Atoi(v.(string))
-                rotation_policy[k] = val
-            } else if k == "deactivate_rotated_key" {
-                /* while sending the request, deactivate_rotated_key should be assigned as a boolean..
-                   Hence it is converted to boolean from the string.
-                */
-                val, _ := strconv.ParseBool(v.(string))
-                rotation_policy[k] = val
-            } else {
-                rotation_policy[k] = v
-            }
-        }
-        return rotation_policy
-}
-
-// To read the security object rotation_policy
-func sobj_rotation_policy_read(rp map[string]interface{}) map[string]interface{}  {
-        rotation_policy := make(map[string]interface{})
-        for k, v := range  rp{
-            /* while reading the rotation_policy from terraform the interval_days attribute is assigned as float64 datatype.
-               Hence it will be converted to string from float object.
-            */
-            if k == "interval_days" || k == "interval_months" {
-                rotation_policy[k] = strconv.FormatFloat(v.(float64), 'f', -1, 64)
-            } else if k == "deactivate_rotated_key" {
-                rotation_policy[k] = strconv.FormatBool(v.(bool))
-            } else {
-                rotation_policy[k] = v
-            }
-        }
-        return rotation_policy
-}
-
 // To read the security object rotation_policy
 func set_lms_read_sobject(lms map[string]interface{}) map[string]interface{}  {
         lms_data := make(map[string]interface{})
@@ -204,8 +153,8 @@ func set_lms_read_sobject(lms map[string]interface{}) map[string]interface{}  {
             /* while reading the lms value from terraform the interval_days attribute is assigned as float64 datatype.
                Hence it will be converted to string from float object.
             */
-            if k == "node_size" || k == "l1_height" || k == "l2_height" {
-                lms_data[k] = strconv.FormatFloat(v.(float64), 'f', -1, 64)
+            if value, ok := v.(float64); ok && (k == "node_size" || k == "l1_height" || k == "l2_height") {
+                lms_data[k] = strconv.FormatFloat(value, 'f', -1, 64)
              }
         }
         return lms_data
@@ -280,14 +229,15 @@ func undoTFstate(param_type string, d *schema.ResourceData) diag.Diagnostics {
 // This function is to parse the date given by the end user.
 // eg: User gives the expiry_date as 2025-01-02T15:04:05Z.
 // Then it parses to 20250102T150405Z.
+// Dates with an offset or fractional seconds are converted to UTC in seconds,
+// eg: 2025-01-02T17:04:05.5+02:00 parses to 20250102T150405Z.
 func parseTimeToDSM(expiry_date string) (string, diag.Diagnostics){
-	layoutRFC := "2006-01-02T15:04:05Z"
 	layoutDSM := "20060102T150405Z"
-	ddate, newerr := time.Parse(layoutRFC, expiry_date)
+	ddate, newerr := time.Parse(time.RFC3339, expiry_date)
 	if newerr != nil {
 		return "", diag.FromErr(newerr)
 	}
-	return ddate.Format(layoutDSM), nil
+	return ddate.UTC().Format(layoutDSM), nil
 }
 
 // expiry_date
@@ -322,15 +272,54 @@ func setKeyOpsTfState(d *schema.ResourceData, key_ops interface{}) diag.Diagnost
 }
 
 
+// [-]: convert a typed list to the []interface{} the terraform helpers expect
+func stringList(list []string) []interface{} {
+	items := make([]interface{}, len(list))
+	for i, item := range list {
+		items[i] = item
+	}
+	return items
+}
+
+// [-]: set copied_to, copied_from, replacement and replaced from the links of a security object
+func setSobjectLinksTfState(d *schema.ResourceData, links *dsmclient.DSMSobjectLinks) diag.Diagnostics {
+	if links == nil {
+		return nil
+	}
+	copied_to := links.Copiedto
+	if copied_to == nil {
+		copied_to = []string{}
+	}
+	if err := d.Set("copied_to", copied_to); err != nil {
+		return diag.FromErr(err)
+	}
+	if links.Copiedfrom != "" {
+		if err := d.Set("copied_from", links.Copiedfrom); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if links.Replacement != "" {
+		if err := d.Set("replacement", links.Replacement); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if links.Replaced != "" {
+		if err := d.Set("replaced", links.Replaced); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
+
 // A BYOK security object can be deleted only when it is in Destroyed state.
-func deleteBYOKDestroyedSobject(d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func deleteBYOKDestroyedSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	/*
 	BYOK object can be deleted only after destruction.
 	*/
 	error_summary := "[DSM SDK] Unable to call DSM provider API client"
 	if d.Get("state").(string) == "Destroyed" {
-		_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
-		if (err != nil) && (statuscode != 204) {
+		_, err := m.(*api_client).APICall(ctx, "DELETE", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
+		if err != nil {
 		    return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: DELETE crypto/v1/keys: %v", err))
 		}
 		d.SetId("")
@@ -354,10 +343,10 @@ func showWarning(msg string) diag.Diagnostics {
 
 
 // Delete key material. (AZURE and AWS)
-func deleteKeyMateialBYOKSobject(d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func deleteKeyMateialBYOKSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	error_summary := "[DSM SDK] Unable to call DSM provider API client"
-    _, statuscode, err := m.(*api_client).APICall("POST", fmt.Sprintf("crypto/v1/keys/%s/delete_key_material", d.Id()))
-    if (err != nil) && (statuscode != 200) {
+    _, err := m.(*api_client).APICall(ctx, "POST", fmt.Sprintf("crypto/v1/keys/%s/delete_key_material", d.Id()))
+    if err != nil {
         return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys: %v", err))
     }
 	return nil
@@ -376,4 +365,50 @@ func ConvertStringToJSONGeneric[T any](inputString string) (T, error) {
 // Generate a UUID
 func generateRandomID() string {
 	return uuid.New().String()
-}
\ No newline at end of file
+}
+// writeOnlyString reads a write-only argument. It is only ever set in the
+// configuration, never in the plan or the state.
+func writeOnlyString(d *schema.ResourceData, key string) (string, diag.Diagnostics) {
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath(key))
+	if diags.HasError() {
+		return "", diags
+	}
+	if !value.Type().Equals(cty.String) || value.IsNull() || !value.IsKnown() {
+		return "", nil
+	}
+	return value.AsString(), nil
+}
+
+// [-]: alg and mode of a DSM unwrap request for each wrapping_alg
+type wrapping_alg struct {
+	alg  string
+	mode interface{}
+}
+
+var wrapping_algs = map[string]wrapping_alg{
+	"AES-KW":       {alg: "AES", mode: "KW"},
+	"AES-KWP":      {alg: "AES", mode: "KWP"},
+	"RSA-OAEP":     {alg: "RSA", mode: map[string]interface{}{"OAEP": map[string]interface{}{"mgf": map[string]interface{}{"mgf1": map[string]interface{}{"hash": "SHA1"}}}}},
+	"RSA-OAEP-256": {alg: "RSA", mode: map[string]interface{}{"OAEP": map[string]interface{}{"mgf": map[string]interface{}{"mgf1": map[string]interface{}{"hash": "SHA256"}}}}},
+}
+
+var wrapping_alg_names = []string{"AES-KW", "AES-KWP", "RSA-OAEP", "RSA-OAEP-256"}
+
+// wrappedValueDiffSuppress ignores a new wrapped_value once the security object
+// exists: wrapping is randomized with RSA-OAEP, so the same material wraps to
+// a different value on every run and would otherwise replace the object.
+func wrappedValueDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	return len(d.Id()) > 0 && len(old) > 0 && len(new) > 0
+}
+
+// setUnwrapKeyRequest turns a create request into a crypto/v1/unwrapkey
+// request: DSM unwraps wrapped_value with wrapping_kid and imports it.
+func setUnwrapKeyRequest(d *schema.ResourceData, security_object map[string]interface{}) {
+	wrapping := wrapping_algs[d.Get("wrapping_alg").(string)]
+	security_object["key"] = map[string]interface{}{
+		"kid": d.Get("wrapping_kid").(string),
+	}
+	security_object["alg"] = wrapping.alg
+	security_object["mode"] = wrapping.mode
+	security_object["wrapped_key"] = d.Get("wrapped_value").(string)
+}
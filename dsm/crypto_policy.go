@@ -0,0 +1,487 @@
+// **********
+// Terraform Provider - DSM: cryptographic policies of groups and accounts
+// **********
+
+package dsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// cryptographic_policy value that removes the policy of a group or an account
+const crypto_policy_remove = "remove"
+
+var (
+	crypto_policy_legacy_policies = []string{"allowed", "prohibited", "unprotect_only"}
+	crypto_policy_key_ops         = []string{"SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "TRANSFORM", "MACGENERATE", "MACVERIFY", "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE"}
+	crypto_policy_curves          = []string{"SecP192K1", "SecP224K1", "SecP256K1", "NistP192", "NistP224", "NistP256", "NistP384", "NistP521", "Gost256A", "X25519", "Ed25519"}
+	crypto_policy_hash_algs       = []string{"SHA1", "SHA224", "SHA256", "SHA384", "SHA512", "SHA3_224", "SHA3_256", "SHA3_384", "SHA3_512"}
+)
+
+// algorithms of a cryptographic policy with the key sizes they allow
+var crypto_policy_key_sizes = map[string][]int{
+	"aes":  {128, 192, 256},
+	"aria": {128, 192, 256},
+	"des3": {112, 168},
+}
+
+// algorithms of a cryptographic policy without options
+var crypto_policy_algorithms = []string{"bip32", "bls", "certificate", "des", "dsa", "eckcdsa", "kcdsa", "lms", "mlkem_beta", "opaque", "secret", "seed"}
+
+// RSA paddings of the encryption and the signature policies
+var crypto_policy_rsa_paddings = map[string][]string{
+	"encryption_policy": {"OAEP", "PKCS1_V15", "RAW_DECRYPT"},
+	"signature_policy":  {"PKCS1_V15", "PSS"},
+}
+
+// [-]: cryptographic_policy block of a group or an account
+// An algorithm without a block is not allowed by the policy.
+func cryptoPolicySchema(description string) *schema.Schema {
+	options := map[string]*schema.Schema{
+		"legacy_policy": {
+			Description: "Whether security objects that do not comply with the policy can still be used. Default is `allowed`.\n" +
+				"   * `allowed`: they can be used for all the operations.\n" +
+				"   * `prohibited`: they cannot be used.\n" +
+				"   * `unprotect_only`: they can only be used to decrypt, verify and unwrap.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "allowed",
+			ValidateFunc: validation.StringInSlice(crypto_policy_legacy_policies, false),
+		},
+		"key_ops": {
+			Description: "The key operations that security objects can allow, e.g. `ENCRYPT`, `SIGN` or `EXPORT`. All the key operations are allowed when empty.",
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(crypto_policy_key_ops, false),
+			},
+		},
+		"hmac": {
+			Description: "Allows HMAC keys.",
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"minimum_key_length": {
+						Description:  "The minimum length of HMAC keys in bits. All the lengths are allowed when not set.",
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(1),
+					},
+				},
+			},
+		},
+		"rsa": {
+			Description: "Allows RSA keys.",
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"minimum_key_length": {
+						Description:  "The minimum length of RSA keys in bits. All the lengths are allowed when not set.",
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(1024, 8192),
+					},
+					"encryption_policy": cryptoPolicyPaddingSchema("An allowed padding of RSA encryption. All the paddings are allowed without a block.", "encryption_policy"),
+					"signature_policy":  cryptoPolicyPaddingSchema("An allowed padding of RSA signatures. All the paddings are allowed without a block.", "signature_policy"),
+				},
+			},
+		},
+		"ec": {
+			Description: "Allows EC keys.",
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"elliptic_curves": {
+						Description: "The allowed elliptic curves, e.g. `NistP256` or `Ed25519`. All the curves are allowed when empty.",
+						Type:        schema.TypeSet,
+						Optional:    true,
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringInSlice(crypto_policy_curves, false),
+						},
+					},
+				},
+			},
+		},
+	}
+	for algorithm, key_sizes := range crypto_policy_key_sizes {
+		options[algorithm] = &schema.Schema{
+			Description: fmt.Sprintf("Allows %s keys.", cryptoPolicyAlgorithmName(algorithm)),
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"key_sizes": {
+						Description: fmt.Sprintf("The allowed key sizes in bits, among %s. All the key sizes are allowed when empty.", strings.Trim(strings.Join(strings.Fields(fmt.Sprint(key_sizes)), ", "), "[]")),
+						Type:        schema.TypeSet,
+						Optional:    true,
+						Elem: &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntInSlice(key_sizes),
+						},
+					},
+				},
+			},
+		}
+	}
+	for _, algorithm := range crypto_policy_algorithms {
+		options[algorithm] = &schema.Schema{
+			Description: fmt.Sprintf("Allows %s security objects, with an empty block.", cryptoPolicyAlgorithmName(algorithm)),
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{},
+			},
+		}
+	}
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Required:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: options,
+		},
+	}
+}
+
+// [-]: encryption_policy or signature_policy of the rsa block
+func cryptoPolicyPaddingSchema(description string, policy string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"padding": {
+					Description:  fmt.Sprintf("The padding, one of `%s`.", strings.Join(crypto_policy_rsa_paddings[policy], "`, `")),
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(crypto_policy_rsa_paddings[policy], false),
+				},
+				"mgf1_hash": {
+					Description:  "The hash algorithm of the MGF1 mask generation of `OAEP` and `PSS`, e.g. `SHA256`. All the hash algorithms are allowed when not set.",
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice(crypto_policy_hash_algs, false),
+				},
+			},
+		},
+	}
+}
+
+// [-]: name of an algorithm of a cryptographic policy in the descriptions
+func cryptoPolicyAlgorithmName(algorithm string) string {
+	switch algorithm {
+	case "des3":
+		return "Triple DES"
+	case "mlkem_beta":
+		return "ML-KEM (beta)"
+	case "certificate", "opaque", "secret":
+		return algorithm
+	}
+	return strings.ToUpper(algorithm)
+}
+
+// [-]: cryptographic policy of DSM of a cryptographic_policy block
+func cryptoPolicyWrite(policy_list []interface{}) map[string]interface{} {
+	dsm_policy := make(map[string]interface{})
+	if len(policy_list) == 0 || policy_list[0] == nil {
+		return dsm_policy
+	}
+	policy := policy_list[0].(map[string]interface{})
+	dsm_policy["legacy_policy"] = policy["legacy_policy"]
+	if key_ops := cryptoPolicyStrings(policy["key_ops"]); len(key_ops) > 0 {
+		dsm_policy["key_ops"] = key_ops
+	}
+	for algorithm := range crypto_policy_key_sizes {
+		if options, ok := cryptoPolicyAlgorithm(policy, algorithm); ok {
+			dsm_algorithm := make(map[string]interface{})
+			if key_sizes := cryptoPolicyInts(options["key_sizes"]); len(key_sizes) > 0 {
+				dsm_algorithm["key_sizes"] = key_sizes
+			}
+			dsm_policy[algorithm] = dsm_algorithm
+		}
+	}
+	for _, algorithm := range crypto_policy_algorithms {
+		if _, ok := cryptoPolicyAlgorithm(policy, algorithm); ok {
+			dsm_policy[algorithm] = map[string]interface{}{}
+		}
+	}
+	if options, ok := cryptoPolicyAlgorithm(policy, "hmac"); ok {
+		dsm_hmac := make(map[string]interface{})
+		if length, _ := options["minimum_key_length"].(int); length > 0 {
+			dsm_hmac["minimum_key_length"] = length
+		}
+		dsm_policy["hmac"] = dsm_hmac
+	}
+	if options, ok := cryptoPolicyAlgorithm(policy, "rsa"); ok {
+		dsm_rsa := make(map[string]interface{})
+		if length, _ := options["minimum_key_length"].(int); length > 0 {
+			dsm_rsa["minimum_key_length"] = length
+		}
+		for rsa_policy := range crypto_policy_rsa_paddings {
+			if paddings := cryptoPolicyPaddingsWrite(options[rsa_policy]); len(paddings) > 0 {
+				dsm_rsa[rsa_policy] = paddings
+			}
+		}
+		dsm_policy["rsa"] = dsm_rsa
+	}
+	if options, ok := cryptoPolicyAlgorithm(policy, "ec"); ok {
+		dsm_ec := make(map[string]interface{})
+		if curves := cryptoPolicyStrings(options["elliptic_curves"]); len(curves) > 0 {
+			dsm_ec["elliptic_curves"] = curves
+		}
+		dsm_policy["ec"] = dsm_ec
+	}
+	return dsm_policy
+}
+
+// [-]: options of an algorithm block, and whether the policy allows the algorithm
+// The options of an empty block are nil.
+func cryptoPolicyAlgorithm(policy map[string]interface{}, algorithm string) (map[string]interface{}, bool) {
+	algorithm_list, _ := policy[algorithm].([]interface{})
+	if len(algorithm_list) == 0 {
+		return nil, false
+	}
+	options, _ := algorithm_list[0].(map[string]interface{})
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	return options, true
+}
+
+// The paddings are sorted so that the request does not depend on the order
+// of the set.
+func cryptoPolicyPaddingsWrite(value interface{}) []interface{} {
+	padding_set, ok := value.(*schema.Set)
+	if !ok {
+		return nil
+	}
+	paddings := padding_set.List()
+	sort.Slice(paddings, func(i, j int) bool {
+		return fmt.Sprint(paddings[i]) < fmt.Sprint(paddings[j])
+	})
+	dsm_paddings := make([]interface{}, 0, len(paddings))
+	for _, padding := range paddings {
+		padding := padding.(map[string]interface{})
+		dsm_padding := make(map[string]interface{})
+		if kind := padding["padding"].(string); kind == "OAEP" || kind == "PSS" {
+			mgf1 := make(map[string]interface{})
+			if hash := padding["mgf1_hash"].(string); hash != "" {
+				mgf1["hash"] = hash
+			}
+			dsm_padding[kind] = map[string]interface{}{"mgf": map[string]interface{}{"mgf1": mgf1}}
+		} else {
+			dsm_padding[kind] = map[string]interface{}{}
+		}
+		dsm_paddings = append(dsm_paddings, map[string]interface{}{"padding": dsm_padding})
+	}
+	return dsm_paddings
+}
+
+func cryptoPolicyStrings(value interface{}) []string {
+	set, ok := value.(*schema.Set)
+	if !ok {
+		return nil
+	}
+	items := make([]string, 0, set.Len())
+	for _, item := range set.List() {
+		items = append(items, item.(string))
+	}
+	sort.Strings(items)
+	return items
+}
+
+func cryptoPolicyInts(value interface{}) []int {
+	set, ok := value.(*schema.Set)
+	if !ok {
+		return nil
+	}
+	items := make([]int, 0, set.Len())
+	for _, item := range set.List() {
+		items = append(items, item.(int))
+	}
+	sort.Ints(items)
+	return items
+}
+
+// [-]: cryptographic_policy block of the cryptographic policy of DSM, empty without a policy
+// Algorithms that DSM sets to null are not allowed, and have no block.
+func cryptoPolicyRead(dsm_policy map[string]interface{}) []interface{} {
+	if dsm_policy == nil {
+		return []interface{}{}
+	}
+	policy := map[string]interface{}{
+		"legacy_policy": "allowed",
+		"key_ops":       cryptoPolicyReadList(dsm_policy["key_ops"], false),
+	}
+	if legacy_policy, ok := dsm_policy["legacy_policy"].(string); ok {
+		policy["legacy_policy"] = legacy_policy
+	}
+	for algorithm := range crypto_policy_key_sizes {
+		if dsm_algorithm, ok := dsm_policy[algorithm].(map[string]interface{}); ok {
+			policy[algorithm] = []interface{}{map[string]interface{}{
+				"key_sizes": cryptoPolicyReadList(dsm_algorithm["key_sizes"], true),
+			}}
+		}
+	}
+	for _, algorithm := range crypto_policy_algorithms {
+		if _, ok := dsm_policy[algorithm].(map[string]interface{}); ok {
+			policy[algorithm] = []interface{}{map[string]interface{}{}}
+		}
+	}
+	if dsm_hmac, ok := dsm_policy["hmac"].(map[string]interface{}); ok {
+		policy["hmac"] = []interface{}{map[string]interface{}{
+			"minimum_key_length": cryptoPolicyReadInt(dsm_hmac["minimum_key_length"]),
+		}}
+	}
+	if dsm_rsa, ok := dsm_policy["rsa"].(map[string]interface{}); ok {
+		rsa := map[string]interface{}{
+			"minimum_key_length": cryptoPolicyReadInt(dsm_rsa["minimum_key_length"]),
+		}
+		for rsa_policy := range crypto_policy_rsa_paddings {
+			rsa[rsa_policy] = cryptoPolicyPaddingsRead(dsm_rsa[rsa_policy])
+		}
+		policy["rsa"] = []interface{}{rsa}
+	}
+	if dsm_ec, ok := dsm_policy["ec"].(map[string]interface{}); ok {
+		policy["ec"] = []interface{}{map[string]interface{}{
+			"elliptic_curves": cryptoPolicyReadList(dsm_ec["elliptic_curves"], false),
+		}}
+	}
+	return []interface{}{policy}
+}
+
+func cryptoPolicyPaddingsRead(value interface{}) []interface{} {
+	dsm_paddings, _ := value.([]interface{})
+	paddings := make([]interface{}, 0, len(dsm_paddings))
+	for _, dsm_padding := range dsm_paddings {
+		dsm_padding, _ := dsm_padding.(map[string]interface{})
+		kinds, _ := dsm_padding["padding"].(map[string]interface{})
+		for kind, options := range kinds {
+			hash := ""
+			if options, ok := options.(map[string]interface{}); ok {
+				mgf, _ := options["mgf"].(map[string]interface{})
+				mgf1, _ := mgf["mgf1"].(map[string]interface{})
+				hash, _ = mgf1["hash"].(string)
+			}
+			paddings = append(paddings, map[string]interface{}{
+				"padding":   kind,
+				"mgf1_hash": hash,
+			})
+		}
+	}
+	return paddings
+}
+
+// [-]: items of a JSON array of DSM, as ints with ints set
+func cryptoPolicyReadList(value interface{}, ints bool) []interface{} {
+	dsm_items, _ := value.([]interface{})
+	items := make([]interface{}, 0, len(dsm_items))
+	for _, item := range dsm_items {
+		if ints {
+			item = cryptoPolicyReadInt(item)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func cryptoPolicyReadInt(value interface{}) int {
+	switch value := value.(type) {
+	case float64:
+		return int(value)
+	case json.Number:
+		number, _ := value.Int64()
+		return int(number)
+	case int:
+		return value
+	}
+	return 0
+}
+
+// [-]: validate the RSA paddings of the cryptographic_policy block at plan time
+func validateCryptoPolicyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	policy_list, _ := d.Get("cryptographic_policy").([]interface{})
+	if len(policy_list) == 0 || policy_list[0] == nil || !d.NewValueKnown("cryptographic_policy") {
+		return nil
+	}
+	options, ok := cryptoPolicyAlgorithm(policy_list[0].(map[string]interface{}), "rsa")
+	if !ok {
+		return nil
+	}
+	path := cty.GetAttrPath("cryptographic_policy").IndexInt(0).GetAttr("rsa").IndexInt(0)
+	for _, rsa_policy := range []string{"encryption_policy", "signature_policy"} {
+		padding_set, ok := options[rsa_policy].(*schema.Set)
+		if !ok {
+			continue
+		}
+		for _, padding := range padding_set.List() {
+			padding := padding.(map[string]interface{})
+			kind, hash := padding["padding"].(string), padding["mgf1_hash"].(string)
+			if hash != "" && kind != "OAEP" && kind != "PSS" {
+				return path.GetAttr(rsa_policy).NewErrorf("mgf1_hash %s is only allowed with the OAEP and PSS paddings, not with %s", hash, kind)
+			}
+		}
+	}
+	return nil
+}
+
+// [-]: StateUpgrader from the JSON string cryptographic_policy of the given schema version
+func cryptoPolicyStateUpgrader(resource *schema.Resource, version int) schema.StateUpgrader {
+	previous_schema := make(map[string]*schema.Schema, len(resource.Schema))
+	for key, value := range resource.Schema {
+		previous_schema[key] = value
+	}
+	previous_schema["cryptographic_policy"] = cryptoPolicyJSONSchema()
+	previous := &schema.Resource{
+		Schema:   previous_schema,
+		Timeouts: resource.Timeouts,
+	}
+	return schema.StateUpgrader{
+		Version: version,
+		Type:    previous.CoreConfigSchema().ImpliedType(),
+		Upgrade: func(ctx context.Context, raw_state map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+			return cryptoPolicyStateUpgrade(raw_state), nil
+		},
+	}
+}
+
+// [-]: cryptographic_policy of the schema versions before the block
+func cryptoPolicyJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+}
+
+// A policy that does not parse is left to the next read, which sets the
+// policy of DSM.
+func cryptoPolicyStateUpgrade(raw_state map[string]interface{}) map[string]interface{} {
+	policy_json, _ := raw_state["cryptographic_policy"].(string)
+	raw_state["cryptographic_policy"] = []interface{}{}
+	var dsm_policy map[string]interface{}
+	if err := json.Unmarshal([]byte(policy_json), &dsm_policy); err != nil {
+		return raw_state
+	}
+	raw_state["cryptographic_policy"] = cryptoPolicyRead(dsm_policy)
+	return raw_state
+}
@@ -45,7 +45,10 @@ func dataSourceAppRead(ctx context.Context, d *schema.ResourceData, m interface{
 
 	d.SetId(d.Get("app_id").(string))
 
-	req, _, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s/credential", d.Id()))
+	credential, err := m.(*api_client).API().GetAppCredential(ctx, d.Id())
+	if err == nil && credential.Secret == nil {
+		err = fmt.Errorf("app %s does not authenticate with an API key", d.Id())
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -55,7 +58,7 @@ func dataSourceAppRead(ctx context.Context, d *schema.ResourceData, m interface{
 		return diags
 	}
 
-	if err := d.Set("credential", base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+req["credential"].(map[string]interface{})["secret"].(string)))); err != nil {
+	if err := d.Set("credential", base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+*credential.Secret))); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -0,0 +1,31 @@
+package dsm
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataApp(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAppConfig("example_app", "created by terraform", false, "") + `
+data "dsm_app" "example_app" {
+  app_id = dsm_app.example_app.app_id
+}
+`,
+				Check: resource.TestCheckResourceAttrPair("data.dsm_app.example_app", "credential", "dsm_app.example_app", "credential"),
+			},
+			{
+				Config:      `data "dsm_app" "missing" { app_id = "00000000-0000-0000-0000-000000000000" }`,
+				ExpectError: regexp.MustCompile("sys/v1/apps/-/credential"),
+			},
+		},
+	})
+}
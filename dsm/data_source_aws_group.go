@@ -10,11 +10,12 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 func dataSourceAWSGroup() *schema.Resource {
@@ -74,11 +75,11 @@ func dataSourceAWSGroup() *schema.Resource {
 
 func dataSourceAWSGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	var group_data map[string]interface{}
+	var group *dsmclient.Group
 	original_name := d.Get("name").(string)
 	modified_name := fmt.Sprintf("%s-aws-%s", original_name, m.(*api_client).aws_region)
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/groups")
+	groups, err := m.(*api_client).API().ListGroups(ctx)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -88,20 +89,19 @@ func dataSourceAWSGroupRead(ctx context.Context, d *schema.ResourceData, m inter
 		return diags
 	}
 
-	// Shashi: First, check for the group name as provided. 
+	// Shashi: First, check for the group name as provided.
 	// If not found, fallback to checking the group name in the "name-aws-region" format used prior to v0.5.33.
-	for _, data := range req {
-		group_name := data.(map[string]interface{})["name"].(string)
-		if group_name == original_name {
-			group_data = data.(map[string]interface{})
+	for i := range groups {
+		if groups[i].Name == original_name {
+			group = &groups[i]
 			break
 		}
-		if group_name == modified_name && group_data == nil {
-			group_data = data.(map[string]interface{})
+		if groups[i].Name == modified_name && group == nil {
+			group = &groups[i]
 		}
 	}
 
-	if group_data == nil {
+	if group == nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Group not found.",
@@ -110,18 +110,8 @@ func dataSourceAWSGroupRead(ctx context.Context, d *schema.ResourceData, m inter
 		return diags
 	}
 
-	jsonbody, parsing_error := json.Marshal(group_data)
-	if parsing_error != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/groups: %s", parsing_error),
-		})
-		return diags
-	}
-
-	awsgroup := AWSGroup{}
-	if err := json.Unmarshal(jsonbody, &awsgroup); err != nil {
+	awsgroup, err := group.AWS()
+	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
@@ -129,34 +119,29 @@ func dataSourceAWSGroupRead(ctx context.Context, d *schema.ResourceData, m inter
 		})
 		return diags
 	}
+
 	// FYOO: AWSGroup must conform to this JSON struct - if this crashes, then we have DSM issues
-	name := awsgroup.Name
-	d.Set("name", name)
+	d.Set("name", awsgroup.Name)
 	d.Set("group_id", awsgroup.Group_id)
 	d.Set("acct_id", awsgroup.Acct_id)
-
-	var creatorInt map[string]interface{}
-	creatorRec, _ := json.Marshal(awsgroup.Creator)
-	json.Unmarshal(creatorRec, &creatorInt)
-	d.Set("creator", creatorInt)
+	d.Set("creator", awsgroup.Creator.Map())
 	d.Set("region", m.(*api_client).aws_region)
+
 	// FYOO: there is only one HMG per AWSGroup
 	for _, value := range awsgroup.Hmg {
 		d.Set("access_key", value.Access_key)
 	}
 	// FYOO: if description is blank, DSM does not return
-	if _, ok := group_data["description"]; ok {
-		d.Set("description", group_data["description"].(string))
+	if group.Description != nil {
+		d.Set("description", *group.Description)
 	}
 
-	d.SetId(d.Get("group_id").(string))
+	d.SetId(awsgroup.Group_id)
 
 	// If Scan is set, then move to scanning for data source
 	if d.Get("scan").(bool) {
-		check_hmg_req := map[string]interface{}{}
-		// Scan the AWS Group first before
-		_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/groups/%s/hmg/check", d.Get("group_id").(string)), check_hmg_req)
-		if err != nil {
+		// Scan the group first before
+		if err := m.(*api_client).API().CheckGroupHmg(ctx, awsgroup.Group_id); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -165,8 +150,7 @@ func dataSourceAWSGroupRead(ctx context.Context, d *schema.ResourceData, m inter
 			return diags
 		}
 
-		_, err = m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/groups/%s/hmg/scan", d.Get("group_id").(string)), check_hmg_req)
-		if err != nil {
+		if err := m.(*api_client).API().ScanGroupHmg(ctx, awsgroup.Group_id); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -1,10 +1,10 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
@@ -28,7 +28,7 @@ func TestAccDataAwsGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroyAwsGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             dataAwsGroup_createConfig,
@@ -37,3 +37,38 @@ func TestAccDataAwsGroup(t *testing.T) {
 		},
 	})
 }
+
+func TestUnitDataAwsGroup(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dsm_aws_group" "example_aws_group" {
+  name        = "example_aws_group"
+  description = "AWS Group Test"
+  access_key  = "AKIAEXAMPLE"
+}
+
+data "dsm_aws_group" "example_aws_group" {
+  name = dsm_aws_group.example_aws_group.name
+  scan = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_aws_group.example_aws_group", "group_id", "dsm_aws_group.example_aws_group", "group_id"),
+					resource.TestCheckResourceAttr("data.dsm_aws_group.example_aws_group", "description", "AWS Group Test"),
+					resource.TestCheckResourceAttr("data.dsm_aws_group.example_aws_group", "access_key", "AKIAEXAMPLE"),
+					resource.TestCheckResourceAttr("data.dsm_aws_group.example_aws_group", "region", "us-east-1"),
+				),
+			},
+			{
+				Config:      `data "dsm_aws_group" "missing" { name = "missing" }`,
+				ExpectError: regexp.MustCompile("No group found with name: missing"),
+			},
+		},
+	})
+}
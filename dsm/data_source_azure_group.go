@@ -10,12 +10,13 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 func dataSourceAzureGroup() *schema.Resource {
@@ -96,11 +97,11 @@ func dataSourceAzureGroup() *schema.Resource {
 
 func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	var group_data map[string]interface{}
+	var group *dsmclient.Group
 	original_name := d.Get("name").(string)
 	modified_name := fmt.Sprintf("%s-azure-%s", original_name, m.(*api_client).azure_region)
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/groups")
+	groups, err := m.(*api_client).API().ListGroups(ctx)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -112,18 +113,17 @@ func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m int
 
 	// Shashi: First, check for the group name as provided.
 	// If not found, fallback to checking the group name in the "name-azure-region" format used prior to v0.5.33.
-	for _, data := range req {
-		group_name := data.(map[string]interface{})["name"].(string)
-		if group_name == original_name {
-			group_data = data.(map[string]interface{})
+	for i := range groups {
+		if groups[i].Name == original_name {
+			group = &groups[i]
 			break
 		}
-		if group_name == modified_name && group_data == nil {
-			group_data = data.(map[string]interface{})
+		if groups[i].Name == modified_name && group == nil {
+			group = &groups[i]
 		}
 	}
 
-	if group_data == nil {
+	if group == nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Group not found.",
@@ -132,18 +132,8 @@ func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m int
 		return diags
 	}
 
-	jsonbody, parsing_error := json.Marshal(group_data)
-	if parsing_error != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] Unable to parse DSM provider API client output.",
-			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %s", parsing_error),
-		})
-		return diags
-	}
-
-	azuregroup := AzureGroup{}
-	if err := json.Unmarshal(jsonbody, &azuregroup); err != nil {
+	azuregroup, err := group.Azure()
+	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
@@ -157,11 +147,7 @@ func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m int
 	d.Set("name", name[0])
 	d.Set("group_id", azuregroup.Group_id)
 	d.Set("acct_id", azuregroup.Acct_id)
-
-	var creatorInt map[string]interface{}
-	creatorRec, _ := json.Marshal(azuregroup.Creator)
-	json.Unmarshal(creatorRec, &creatorInt)
-	d.Set("creator", creatorInt)
+	d.Set("creator", azuregroup.Creator.Map())
 	d.Set("region", m.(*api_client).azure_region)
 
 	// FYOO: there is only one HMG per AzureGroup
@@ -175,18 +161,16 @@ func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m int
 	// FYOO: remove sensitive information
 	d.Set("secret_key", "")
 	// FYOO: if description is blank, DSM does not return
-	if _, ok := group_data["description"]; ok {
-		d.Set("description", group_data["description"].(string))
+	if group.Description != nil {
+		d.Set("description", *group.Description)
 	}
 
-	d.SetId(d.Get("group_id").(string))
+	d.SetId(azuregroup.Group_id)
 
 	// If Scan is set, then move to scanning for data source
 	if d.Get("scan").(bool) {
-		check_hmg_req := map[string]interface{}{}
-		// Scan the AWS Group first before
-		_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/groups/%s/hmg/check", d.Get("group_id").(string)), check_hmg_req)
-		if err != nil {
+		// Scan the group first before
+		if err := m.(*api_client).API().CheckGroupHmg(ctx, azuregroup.Group_id); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -195,8 +179,7 @@ func dataSourceAzureGroupRead(ctx context.Context, d *schema.ResourceData, m int
 			return diags
 		}
 
-		_, err = m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/groups/%s/hmg/scan", d.Get("group_id").(string)), check_hmg_req)
-		if err != nil {
+		if err := m.(*api_client).API().ScanGroupHmg(ctx, azuregroup.Group_id); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
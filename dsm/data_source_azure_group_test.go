@@ -1,12 +1,11 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"os"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
@@ -38,7 +37,7 @@ func TestAccdataAzureGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheckAzure(t) },
-		CheckDestroy: testAccCheckDestroyAzureGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             fmt.Sprintf(dataAzureGroup_createConfig, azure_tenant_id, azure_secret_key, azure_subscription_id, azure_client_id, azure_url),
@@ -47,3 +46,28 @@ func TestAccdataAzureGroup(t *testing.T) {
 		},
 	})
 }
+
+func TestUnitDataAzureGroup(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAzureGroupConfig("Azure Group Test") + `
+data "dsm_azure_group" "example_azure_group" {
+  name = dsm_azure_group.example_azure_group.name
+  scan = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_azure_group.example_azure_group", "group_id", "dsm_azure_group.example_azure_group", "group_id"),
+					resource.TestCheckResourceAttr("data.dsm_azure_group.example_azure_group", "tenant_id", "33333333-3333-3333-3333-333333333333"),
+					resource.TestCheckResourceAttr("data.dsm_azure_group.example_azure_group", "key_vault_type", "Standard"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -90,7 +90,10 @@ func dataSourceCsrRead(ctx context.Context, d *schema.ResourceData, m interface{
 		"subject_dn":  subject_dn,
 	}
 
-	reqfpi, err := m.(*api_client).FindPluginId("Terraform Plugin - CSR")
+	plugin, err := m.(*api_client).API().FindPlugin(ctx, "Terraform Plugin - CSR")
+	if err == nil && plugin == nil {
+		err = fmt.Errorf("unable to find plugin %q through DSM provider", "Terraform Plugin - CSR")
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -99,10 +102,10 @@ func dataSourceCsrRead(ctx context.Context, d *schema.ResourceData, m interface{
 		})
 		return diags
 	}
-	var endpoint = fmt.Sprintf("sys/v1/plugins/%s", string(reqfpi))
+	var endpoint = fmt.Sprintf("sys/v1/plugins/%s", plugin.Plugin_id)
 	var operation = "POST"
 
-	req, err := m.(*api_client).APICallBody(operation, endpoint, plugin_object)
+	req, err := m.(*api_client).APICallBody(ctx, operation, endpoint, plugin_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
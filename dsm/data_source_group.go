@@ -71,7 +71,7 @@ func dataSourceGroup() *schema.Resource {
 func dataSourceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/groups")
+	group, err := m.(*api_client).API().FindGroup(ctx, d.Get("name").(string))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -82,41 +82,39 @@ func dataSourceGroupRead(ctx context.Context, d *schema.ResourceData, m interfac
 	}
 
 	group_id := ""
-	for _, data := range req {
-		if data.(map[string]interface{})["name"].(string) == d.Get("name").(string) {
-			group_id = data.(map[string]interface{})["group_id"].(string)
-			if err := d.Set("name", data.(map[string]interface{})["name"].(string)); err != nil {
+	if group != nil {
+		group_id = group.Group_id
+		if err := d.Set("name", group.Name); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("group_id", group_id); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("acct_id", group.Acct_id); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("creator", group.Creator.Map()); err != nil {
+			return diag.FromErr(err)
+		}
+		if group.Description != nil {
+			if err := d.Set("description", *group.Description); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("group_id", group_id); err != nil {
+		}
+		if group.Approval_policy != nil {
+			if err := d.Set("approval_policy", fmt.Sprintf("%v", group.Approval_policy)); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("acct_id", data.(map[string]interface{})["acct_id"].(string)); err != nil {
+		}
+		if group.Cryptographic_policy != nil {
+			if err := d.Set("cryptographic_policy", fmt.Sprintf("%v", group.Cryptographic_policy)); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("creator", data.(map[string]interface{})["creator"]); err != nil {
+		}
+		if group.Hmg != nil {
+			if err := d.Set("hmg", fmt.Sprintf("%v", group.Hmg)); err != nil {
 				return diag.FromErr(err)
 			}
-			if description, ok := data.(map[string]interface{})["description"]; ok {
-				if err := d.Set("description", description.(string)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-			if approval_policy, ok := data.(map[string]interface{})["approval_policy"]; ok {
-				if err := d.Set("approval_policy", fmt.Sprintf("%v", approval_policy)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-			if cryptographic_policy, ok := data.(map[string]interface{})["cryptographic_policy"]; ok {
-				if err := d.Set("cryptographic_policy", fmt.Sprintf("%v", cryptographic_policy)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-			if hmg, ok := data.(map[string]interface{})["hmg"]; ok {
-				if err := d.Set("hmg", fmt.Sprintf("%v", hmg)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
 		}
 	}
 
@@ -1,10 +1,9 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
@@ -25,7 +24,7 @@ func TestAccDataGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroyGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: dataGroup_createConfig,
@@ -33,3 +32,31 @@ func TestAccDataGroup(t *testing.T) {
 		},
 	})
 }
+
+func TestUnitDataGroup(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("existing_group", map[string]interface{}{
+		"description":     "created outside of terraform",
+		"approval_policy": map[string]interface{}{"policy": map[string]interface{}{"quorum": map[string]interface{}{"n": 1, "members": []interface{}{}}}},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: dataGroup_createConfig + `
+	data "dsm_group" "existing_group" {
+		name = "existing_group"
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_group.example_group", "group_id", "dsm_group.example_group", "group_id"),
+					resource.TestCheckResourceAttr("data.dsm_group.example_group", "acct_id", m.acct_id),
+					resource.TestCheckResourceAttr("data.dsm_group.existing_group", "id", group_id),
+					resource.TestCheckResourceAttr("data.dsm_group.existing_group", "description", "created outside of terraform"),
+					resource.TestCheckResourceAttrSet("data.dsm_group.existing_group", "approval_policy"),
+				),
+			},
+		},
+	})
+}
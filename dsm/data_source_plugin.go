@@ -91,7 +91,7 @@ func dataSourcePlugin() *schema.Resource {
 func dataSourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/plugins")
+	plugin, err := m.(*api_client).API().FindPlugin(ctx, d.Get("name").(string))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -100,42 +100,38 @@ func dataSourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interfa
 		})
 		return diags
 	}
-	for _, data := range req {
-		if data.(map[string]interface{})["name"].(string) == d.Get("name").(string) {
-			if err := d.Set("name", data.(map[string]interface{})["name"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("description", data.(map[string]interface{})["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("plugin_id", data.(map[string]interface{})["plugin_id"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("default_group", data.(map[string]interface{})["default_group"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("enabled", data.(map[string]interface{})["enabled"].(bool)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("acct_id", data.(map[string]interface{})["acct_id"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("groups", data.(map[string]interface{})["groups"].([]interface{})); err != nil {
+	if plugin != nil {
+		if err := d.Set("name", plugin.Name); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("description", plugin.Description); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("plugin_id", plugin.Plugin_id); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("default_group", plugin.Default_group); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("enabled", plugin.Enabled); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("acct_id", plugin.Acct_id); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("groups", plugin.Groups); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("creator", plugin.Creator.Map()); err != nil {
+			return diag.FromErr(err)
+		}
+		if plugin.Source != nil {
+			if err := d.Set("language", plugin.Source.Language); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("creator", data.(map[string]interface{})["creator"]); err != nil {
+			if err := d.Set("code", plugin.Source.Code); err != nil {
 				return diag.FromErr(err)
 			}
-			if _, ok := data.(map[string]interface{})["source"].(map[string]interface{}); ok {
-				source := data.(map[string]interface{})["source"].(map[string]interface{})
-				if err := d.Set("language", source["language"].(string)); err != nil {
-					return diag.FromErr(err)
-				}
-				if err := d.Set("code", source["code"].(string)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-			break
 		}
 	}
 	d.SetId(d.Get("plugin_id").(string))
@@ -0,0 +1,32 @@
+package dsm
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataPlugin(t *testing.T) {
+	m := newMockDSM(t)
+	m.seedPlugins(t, m.seedGroup("plugin_group", nil))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dsm_plugin" "csr" {
+  name = "Terraform Plugin - CSR"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dsm_plugin.csr", "plugin_id", m.pluginByName("Terraform Plugin - CSR")["plugin_id"].(string)),
+					resource.TestCheckResourceAttr("data.dsm_plugin.csr", "enabled", "true"),
+					resource.TestCheckResourceAttr("data.dsm_plugin.csr", "groups.#", "1"),
+					resource.TestMatchResourceAttr("data.dsm_plugin.csr", "code", regexp.MustCompile(`function run\(input\)`)),
+				),
+			},
+		},
+	})
+}
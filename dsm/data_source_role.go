@@ -30,7 +30,7 @@ func dataSourceRole() *schema.Resource {
 func dataSourceRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/roles")
+	req, err := m.(*api_client).APICallList(ctx, "GET", "sys/v1/roles")
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -0,0 +1,25 @@
+package dsm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataRole(t *testing.T) {
+	m := newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dsm_role" "custodian" {
+  name = "Key Custodian"
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.dsm_role.custodian", "role_id", m.find("roles", "Key Custodian")["role_id"].(string)),
+			},
+		},
+	})
+}
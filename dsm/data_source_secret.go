@@ -19,6 +19,9 @@ import (
 func dataSourceSecret() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceSecretRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(approval_default_timeout),
+		},
 		Description: "Returns the Fortanix DSM secret object from the cluster as a Data Source.",
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -79,7 +82,8 @@ func dataSourceSecretRead(ctx context.Context, d *schema.ResourceData, m interfa
 		"name": d.Get("name").(string),
 	}
 
-	req, err := m.(*api_client).APICallBody("POST", "crypto/v1/keys/export", security_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", "crypto/v1/keys/export", security_object, d.Timeout(schema.TimeoutRead))
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -89,7 +93,7 @@ func dataSourceSecretRead(ctx context.Context, d *schema.ResourceData, m interfa
 		return diags
 	}
 
-	//req, err := m.(*api_client).APICallList("GET", "crypto/v1/keys")
+	//req, err := m.(*api_client).APICallList(ctx, "GET", "crypto/v1/keys")
 	//if err != nil {
 	//	diags = append(diags, diag.Diagnostic{
 	//		Severity: diag.Error,
@@ -129,5 +133,5 @@ func dataSourceSecretRead(ctx context.Context, d *schema.ResourceData, m interfa
 	}
 
 	d.SetId(d.Get("kid").(string))
-	return nil
+	return diags
 }
@@ -0,0 +1,55 @@
+package dsm
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataSecret(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	quorum_group_id := m.seedGroup("quorum_group", map[string]interface{}{"approval_policy": m.quorumPolicy()})
+	value := base64.StdEncoding.EncodeToString([]byte("quorum secret"))
+	m.seedKey(t, map[string]interface{}{
+		"name":     "quorum_secret",
+		"group_id": quorum_group_id,
+		"obj_type": "SECRET",
+		"key_ops":  []interface{}{"EXPORT", "APPMANAGEABLE"},
+		"value":    value,
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitSecretConfig("example_secret", "created by terraform", true) + `
+data "dsm_secret" "example_secret" {
+  name   = dsm_secret.example_secret.name
+  export = true
+}
+
+data "dsm_secret" "quorum_secret" {
+  name   = "quorum_secret"
+  export = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_secret.example_secret", "kid", "dsm_secret.example_secret", "kid"),
+					resource.TestCheckResourceAttr("data.dsm_secret.example_secret", "value", "c2VjcmV0IHZhbHVl"),
+					resource.TestCheckResourceAttr("data.dsm_secret.example_secret", "acct_id", m.acct_id),
+					// the export waits for the quorum of the group
+					resource.TestCheckResourceAttr("data.dsm_secret.quorum_secret", "value", value),
+				),
+			},
+			{
+				Config:      `data "dsm_secret" "missing" { name = "missing" }`,
+				ExpectError: regexp.MustCompile("POST crypto/v1/keys/export"),
+			},
+		},
+	})
+}
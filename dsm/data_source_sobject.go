@@ -11,15 +11,19 @@ package dsm
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 func dataSourceSobject() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceSobjectRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(approval_default_timeout),
+		},
 		Description: "Returns the DSM security object from the cluster as a Data Source.\n\n" + 
 		"`Note`: This data source supports only security objects with EXPORT permission set in DSM.",
 		Schema: map[string]*schema.Schema{
@@ -100,29 +104,30 @@ func dataSourceSobject() *schema.Resource {
 
 func dataSourceSobjectRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	var req map[string]interface{}
-	var reqErr diag.Diagnostics
+	sobject := &dsmclient.Sobject{}
 
 	security_object := map[string]interface{}{
 		"name": d.Get("name").(string),
 	}
 
 	if d.Get("export").(bool) {
-		req, reqErr = m.(*api_client).APICallBody("POST", "crypto/v1/keys/export", security_object)
-		if reqErr != nil {
+		req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", "crypto/v1/keys/export", security_object, d.Timeout(schema.TimeoutRead))
+		diags = append(diags, approval_diags...)
+		if err == nil {
+			err = decodeApprovalResponse(req, sobject)
+		}
+		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: POST crypto/v1/keys/export: %v", reqErr),
+				Detail:   fmt.Sprintf("[E]: API: POST crypto/v1/keys/export: %v", err),
 			})
 			return diags
 		}
 	} else {
-		var reqList []interface{}
-		encodedName := url.QueryEscape(d.Get("name").(string))
-		reqList, reqErr = m.(*api_client).APICallList("GET", "crypto/v1/keys?name=" + encodedName) 
-		if reqErr == nil && len(reqList) > 0 {
-			req = reqList[0].(map[string]interface{})
+		sobjects, err := m.(*api_client).API().ListKeys(ctx, map[string]string{"name": d.Get("name").(string)})
+		if err == nil && len(sobjects) > 0 {
+			sobject = &sobjects[0]
 		} else {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -133,69 +138,65 @@ func dataSourceSobjectRead(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
-	if err := d.Set("name", req["name"].(string)); err != nil {
+	if err := d.Set("name", sobject.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("kid", req["kid"].(string)); err != nil {
+	if err := d.Set("kid", sobject.Kid); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["pub_key"]; ok {
-		if err := d.Set("pub_key", req["pub_key"].(string)); err != nil {
+	if sobject.Pub_key != nil {
+		if err := d.Set("pub_key", *sobject.Pub_key); err != nil {
 			return diag.FromErr(err)
 		}
 	}
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("creator", req["creator"]); err != nil {
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["description"]; ok {
-		if err := d.Set("description", req["description"].(string)); err != nil {
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
 	}
-	if err := d.Set("obj_type", req["obj_type"].(string)); err != nil {
+	if err := d.Set("obj_type", sobject.Obj_type); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("key_size", int(req["key_size"].(float64))); err != nil {
-		return diag.FromErr(err)
+	if sobject.Key_size != nil {
+		if err := d.Set("key_size", *sobject.Key_size); err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
 		return diag.FromErr(err)
 	}
-	if d.Get("export").(bool) {
-		if err := d.Set("value", req["value"].(string)); err != nil {
+	if d.Get("export").(bool) && sobject.Value != nil {
+		if err := d.Set("value", *sobject.Value); err != nil {
 			return diag.FromErr(err)
 		}
 	}
-	key_ops := make([]string, len(req["key_ops"].([]interface{})))
-	if err := d.Get("key_ops").([]interface{}); len(err) > 0 {
-		if len(d.Get("key_ops").([]interface{})) == len(req["key_ops"].([]interface{})) {
-			for idx, key_op := range d.Get("key_ops").([]interface{}) {
+	key_ops := make([]string, len(sobject.Key_ops))
+	if tf_key_ops := d.Get("key_ops").([]interface{}); len(tf_key_ops) > 0 {
+		if len(tf_key_ops) == len(sobject.Key_ops) {
+			for idx, key_op := range tf_key_ops {
 				key_ops[idx] = fmt.Sprint(key_op)
 			}
 		} else {
-			req_key_ops := make([]string, len(req["key_ops"].([]interface{})))
-			for idx, key_op := range req["key_ops"].([]interface{}) {
-				req_key_ops[idx] = fmt.Sprint(key_op)
-			}
 			final_idx := 0
-			for _, key_op := range d.Get("key_ops").([]interface{}) {
-				if contains(req_key_ops, fmt.Sprint(key_op)) {
+			for _, key_op := range tf_key_ops {
+				if contains(sobject.Key_ops, fmt.Sprint(key_op)) {
 					key_ops[final_idx] = fmt.Sprint(key_op)
 					final_idx = final_idx + 1
 				}
 			}
 		}
 	} else {
-		for idx, key_op := range req["key_ops"].([]interface{}) {
-			key_ops[idx] = fmt.Sprint(key_op)
-		}
+		copy(key_ops, sobject.Key_ops)
 	}
 	if err := d.Set("key_ops", key_ops); err != nil {
 		return diag.FromErr(err)
 	}
 	d.SetId(d.Get("kid").(string))
-	return nil
+	return diags
 }
@@ -78,7 +78,7 @@ func dataSourceSobjectInfoRead(ctx context.Context, d *schema.ResourceData, m in
 		"name": d.Get("name").(string),
 	}
 
-	req, err := m.(*api_client).APICallBody("POST", "crypto/v1/keys/info", security_object)
+	req, err := m.(*api_client).APICallBody(ctx, "POST", "crypto/v1/keys/info", security_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -105,8 +105,10 @@ func dataSourceSobjectInfoRead(ctx context.Context, d *schema.ResourceData, m in
 	if err := d.Set("creator", req["creator"]); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("description", req["description"].(string)); err != nil {
-		return diag.FromErr(err)
+	if _, ok := req["description"]; ok {
+		if err := d.Set("description", req["description"].(string)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 	if err := d.Set("obj_type", req["obj_type"].(string)); err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,36 @@
+package dsm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataSobjectInfo(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitSobjectConfig("example_aes", "created by terraform", false, "") + `
+data "dsm_sobject_info" "example_ec" {
+  name = dsm_sobject.example_ec.name
+}
+
+data "dsm_sobject_info" "example_aes" {
+  name = dsm_sobject.example_aes.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_sobject_info.example_ec", "kid", "dsm_sobject.example_ec", "kid"),
+					resource.TestCheckResourceAttr("data.dsm_sobject_info.example_ec", "obj_type", "EC"),
+					resource.TestCheckResourceAttrSet("data.dsm_sobject_info.example_ec", "pub_key"),
+					resource.TestCheckResourceAttr("data.dsm_sobject_info.example_aes", "enabled", "false"),
+					resource.TestCheckResourceAttr("data.dsm_sobject_info.example_aes", "key_ops.#", "4"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package dsm
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataSobject(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitSobjectConfig("example_aes", "created by terraform", true, "") + `
+data "dsm_sobject" "example_aes" {
+  name   = dsm_sobject.example_aes.name
+  export = true
+}
+
+data "dsm_sobject" "example_rsa" {
+  name = dsm_sobject.example_rsa.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dsm_sobject.example_aes", "kid", "dsm_sobject.example_aes", "kid"),
+					resource.TestCheckResourceAttr("data.dsm_sobject.example_aes", "obj_type", "AES"),
+					resource.TestCheckResourceAttr("data.dsm_sobject.example_aes", "key_size", "256"),
+					resource.TestCheckResourceAttr("data.dsm_sobject.example_aes", "description", "created by terraform"),
+					resource.TestMatchResourceAttr("data.dsm_sobject.example_aes", "value", regexp.MustCompile(`^[A-Za-z0-9+/]{43}=$`)),
+					resource.TestCheckResourceAttrPair("data.dsm_sobject.example_rsa", "pub_key", "dsm_sobject.example_rsa", "pub_key"),
+					resource.TestCheckNoResourceAttr("data.dsm_sobject.example_rsa", "value"),
+				),
+			},
+			{
+				// the RSA key has no EXPORT permission
+				Config: testUnitSobjectConfig("example_aes", "created by terraform", true, "") + `
+data "dsm_sobject" "example_rsa" {
+  name   = dsm_sobject.example_rsa.name
+  export = true
+}
+`,
+				ExpectError: regexp.MustCompile("Security object is not exportable"),
+			},
+		},
+	})
+}
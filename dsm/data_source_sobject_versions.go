@@ -0,0 +1,167 @@
+// **********
+// Terraform Provider - DSM: data source: security object versions
+// **********
+
+package dsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
+)
+
+func dataSourceSobjectVersions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSobjectVersionsRead,
+		Description: "Returns every version of a Fortanix DSM security object, following the rotation chain (the replaced and replacement links) from any of its versions.",
+		Schema: map[string]*schema.Schema{
+			"kid": {
+				Description:  "The ID of any version of the security object. Either kid or name should be specified.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"kid", "name"},
+			},
+			"name": {
+				Description:  "The name of the security object. Either kid or name should be specified. After a rotation, the name belongs to the newest version.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"kid", "name"},
+			},
+			"versions": {
+				Description: "The versions of the security object, from the oldest to the newest.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"kid": {
+							Description: "The ID of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"name": {
+							Description: "The name of the version. DSM renames the rotated versions.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"state": {
+							Description: "The state of the version.\n" +
+								"   * Allowed states are: None, PreActive, Active, Deactivated, Compromised, Destroyed, Deleted.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_at": {
+							Description: "The creation date of the version in RFC format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"activation_date": {
+							Description: "The activation date of the version in RFC format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"deactivation_date": {
+							Description: "The deactivation (expiry) date of the version in RFC format, if any.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"enabled": {
+							Description: "Whether the version is enabled.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSobjectVersionsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api_client).API()
+
+	var start *dsmclient.Sobject
+	var err error
+	if kid, ok := d.GetOk("kid"); ok {
+		start, err = client.GetKeyAnyState(ctx, kid.(string))
+	} else {
+		start, err = client.KeyInfo(ctx, map[string]interface{}{"name": d.Get("name").(string)})
+	}
+	if err != nil {
+		return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err))
+	}
+
+	// walk back to the oldest version, then forward to the newest one
+	seen := map[string]bool{start.Kid: true}
+	versions := []*dsmclient.Sobject{start}
+	for next := start.Links; next != nil && next.Replaced != ""; {
+		if seen[next.Replaced] {
+			break
+		}
+		sobject, err := client.GetKeyAnyState(ctx, next.Replaced)
+		if IsNotFound(err) {
+			// the older versions have been deleted
+			break
+		}
+		if err != nil {
+			return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err))
+		}
+		seen[sobject.Kid] = true
+		versions = append([]*dsmclient.Sobject{sobject}, versions...)
+		next = sobject.Links
+	}
+	for next := start.Links; next != nil && next.Replacement != ""; {
+		if seen[next.Replacement] {
+			break
+		}
+		sobject, err := client.GetKeyAnyState(ctx, next.Replacement)
+		if IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err))
+		}
+		seen[sobject.Kid] = true
+		versions = append(versions, sobject)
+		next = sobject.Links
+	}
+
+	version_list := make([]interface{}, 0, len(versions))
+	for _, sobject := range versions {
+		version := map[string]interface{}{
+			"kid":     sobject.Kid,
+			"name":    sobject.Name,
+			"state":   sobject.State,
+			"enabled": sobject.Enabled,
+		}
+		dates := map[string]*string{
+			"created_at":        &sobject.Created_at,
+			"activation_date":   sobject.Activation_date,
+			"deactivation_date": sobject.Deactivation_date,
+		}
+		for key, date := range dates {
+			if date == nil || len(*date) == 0 {
+				continue
+			}
+			rfc_date, diags := parseTimeFromDSM(*date)
+			if diags.HasError() {
+				return diags
+			}
+			version[key] = rfc_date
+		}
+		version_list = append(version_list, version)
+	}
+
+	if err := d.Set("kid", start.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("versions", version_list); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(start.Kid)
+	return nil
+}
@@ -0,0 +1,74 @@
+package dsm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataSobjectVersions(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	kids := []string{m.seedKey(t, map[string]interface{}{
+		"name":     "rotated_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_size": 256,
+	})}
+	kids = append(kids, m.seedRotation(t, "rotated_key"))
+	kids = append(kids, m.seedRotation(t, "rotated_key"))
+	m.mutate("keys", kids[0], func(key map[string]interface{}) {
+		key["state"] = "Deactivated"
+		key["enabled"] = false
+		key["deactivation_date"] = "20250102T150405Z"
+	})
+
+	checkVersions := func(name string) resource.TestCheckFunc {
+		checks := []resource.TestCheckFunc{
+			resource.TestCheckResourceAttr(name, "versions.#", "3"),
+			resource.TestCheckResourceAttr(name, "versions.0.state", "Deactivated"),
+			resource.TestCheckResourceAttr(name, "versions.0.enabled", "false"),
+			resource.TestCheckResourceAttr(name, "versions.0.deactivation_date", "2025-01-02T15:04:05Z"),
+			resource.TestCheckResourceAttr(name, "versions.2.name", "rotated_key"),
+			resource.TestCheckResourceAttr(name, "versions.2.state", "Active"),
+			resource.TestCheckResourceAttr(name, "versions.2.enabled", "true"),
+			resource.TestCheckResourceAttrSet(name, "versions.2.created_at"),
+			resource.TestCheckResourceAttrSet(name, "versions.2.activation_date"),
+			resource.TestCheckResourceAttr(name, "versions.2.deactivation_date", ""),
+		}
+		for i, kid := range kids {
+			checks = append(checks, resource.TestCheckResourceAttr(name, fmt.Sprintf("versions.%d.kid", i), kid))
+		}
+		return resource.ComposeTestCheckFunc(checks...)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data "dsm_sobject_versions" "missing" { kid = "f81b6b4c-6b8e-4b4a-9f0a-6a4b1f1d3c2e" }`,
+				ExpectError: regexp.MustCompile("GET crypto/v1/keys"),
+			},
+			{
+				Config: fmt.Sprintf(`
+data "dsm_sobject_versions" "by_kid" {
+  kid = %q
+}
+
+data "dsm_sobject_versions" "by_name" {
+  name = "rotated_key"
+}
+`, kids[1]),
+				Check: resource.ComposeTestCheckFunc(
+					checkVersions("data.dsm_sobject_versions.by_kid"),
+					checkVersions("data.dsm_sobject_versions.by_name"),
+					resource.TestCheckResourceAttr("data.dsm_sobject_versions.by_kid", "kid", kids[1]),
+					resource.TestCheckResourceAttr("data.dsm_sobject_versions.by_name", "kid", kids[2]),
+				),
+			},
+		},
+	})
+}
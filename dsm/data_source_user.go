@@ -3,7 +3,6 @@ package dsm
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -31,7 +30,7 @@ func dataSourceUser() *schema.Resource {
 func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/users")
+	user, err := m.(*api_client).API().FindUser(ctx, d.Get("user_email").(string))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -42,15 +41,13 @@ func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface
 	}
 
 	user_id := ""
-	for _, data := range req {
-		if strings.ToLower(data.(map[string]interface{})["user_email"].(string)) == strings.ToLower(d.Get("user_email").(string)) {
-			user_id = data.(map[string]interface{})["user_id"].(string)
-			if err := d.Set("user_email", d.Get("user_email").(string)); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("user_id", user_id); err != nil {
-				return diag.FromErr(err)
-			}
+	if user != nil {
+		user_id = user.User_id
+		if err := d.Set("user_email", d.Get("user_email").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("user_id", user_id); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
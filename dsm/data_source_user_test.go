@@ -0,0 +1,31 @@
+package dsm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataUser(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dsm_user" "example_user" {
+  user_email = "user@example.com"
+  role       = "ACCOUNTAUDITOR"
+}
+
+data "dsm_user" "example_user" {
+  user_email = dsm_user.example_user.user_email
+}
+`,
+				Check: resource.TestCheckResourceAttrPair("data.dsm_user.example_user", "user_id", "dsm_user.example_user", "user_id"),
+			},
+		},
+	})
+}
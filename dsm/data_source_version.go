@@ -45,7 +45,7 @@ func dataSourceVersion() *schema.Resource {
 func dataSourceVersionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, _, err := m.(*api_client).APICall("GET", "sys/v1/version")
+	req, err := m.(*api_client).APICall(ctx, "GET", "sys/v1/version")
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -0,0 +1,25 @@
+package dsm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataVersion(t *testing.T) {
+	newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "dsm_version" "version" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dsm_version.version", "version", "4.31.2100"),
+					resource.TestCheckResourceAttr("data.dsm_version.version", "api_version", "1.0"),
+					resource.TestCheckResourceAttrSet("data.dsm_version.version", "server_mode"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,176 @@
+// **********
+// Terraform Provider - DSM: data source: wrapped key
+// **********
+
+package dsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceWrappedKey() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceWrappedKeyRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(approval_default_timeout),
+		},
+		Description: "Exports a Fortanix DSM security object wrapped (encrypted) by another security object, e.g. a KEK for key escrow.\n\n" +
+			"The wrapped_value, wrapping_alg and the key metadata can be imported by dsm_sobject in another account that holds the same wrapping key.\n\n" +
+			"`Note`: The security object should have the EXPORT permission and the wrapping key the WRAPKEY permission.",
+		Schema: map[string]*schema.Schema{
+			"kid": {
+				Description:  "The ID of the security object to wrap. Either kid or name should be specified.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"kid", "name"},
+			},
+			"name": {
+				Description:  "The name of the security object to wrap. Either kid or name should be specified.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"kid", "name"},
+			},
+			"wrapping_kid": {
+				Description:  "The ID of the wrapping key. Either wrapping_kid or wrapping_name should be specified.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"wrapping_kid", "wrapping_name"},
+			},
+			"wrapping_name": {
+				Description:  "The name of the wrapping key. Either wrapping_kid or wrapping_name should be specified.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"wrapping_kid", "wrapping_name"},
+			},
+			"wrapping_alg": {
+				Description: "The algorithm and mode of the wrapping.\n" +
+					"   * `AES-KW`, `AES-KWP`: key wrap (RFC 3394) and key wrap with padding (RFC 5649) with an AES wrapping key.\n" +
+					"   * `RSA-OAEP`, `RSA-OAEP-256`: RSA OAEP with SHA-1 or SHA-256 with an RSA wrapping key.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(wrapping_alg_names, false),
+			},
+			"wrapped_value": {
+				Description: "The wrapped security object in base64 format.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"obj_type": {
+				Description: "The type of the security object.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"key_size": {
+				Description: "The size of the security object in bits (If applicable).",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"elliptic_curve": {
+				Description: "The elliptic curve of the security object (If applicable).",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"key_ops": {
+				Description: "The security object key permission from Fortanix DSM.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"group_id": {
+				Description: "The group of the security object.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"acct_id": {
+				Description: "The account ID from Fortanix DSM.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// sobjectRef references a security object by kid, or else by name
+func sobjectRef(d *schema.ResourceData, kid_key string, name_key string) map[string]interface{} {
+	if kid, ok := d.GetOk(kid_key); ok {
+		return map[string]interface{}{"kid": kid.(string)}
+	}
+	return map[string]interface{}{"name": d.Get(name_key).(string)}
+}
+
+func dataSourceWrappedKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*api_client)
+
+	sobject, err := client.API().KeyInfo(ctx, sobjectRef(d, "kid", "name"))
+	if err != nil {
+		return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: POST crypto/v1/keys/info: %v", err))
+	}
+	wrapping_key, err := client.API().KeyInfo(ctx, sobjectRef(d, "wrapping_kid", "wrapping_name"))
+	if err != nil {
+		return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: POST crypto/v1/keys/info: wrapping key: %v", err))
+	}
+
+	wrapping := wrapping_algs[d.Get("wrapping_alg").(string)]
+	wrap_request := map[string]interface{}{
+		"key":     map[string]interface{}{"kid": wrapping_key.Kid},
+		"subject": map[string]interface{}{"kid": sobject.Kid},
+		"alg":     wrapping.alg,
+		"mode":    wrapping.mode,
+	}
+	req, diags, err := client.APICallBodyWithApproval(ctx, "POST", "crypto/v1/wrapkey", wrap_request, d.Timeout(schema.TimeoutRead))
+	if err != nil {
+		return append(diags, invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: POST crypto/v1/wrapkey: %v", err))...)
+	}
+
+	if err := d.Set("wrapped_value", req["wrapped_key"]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wrapping_kid", wrapping_key.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wrapping_name", wrapping_key.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("obj_type", sobject.Obj_type); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Key_size != nil {
+		if err := d.Set("key_size", *sobject.Key_size); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if sobject.Elliptic_curve != nil {
+		if err := d.Set("elliptic_curve", *sobject.Elliptic_curve); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("key_ops", sobject.Key_ops); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(sobject.Kid)
+	return diags
+}
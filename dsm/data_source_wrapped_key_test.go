@@ -0,0 +1,151 @@
+package dsm
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitDataWrappedKey(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("escrow_group", nil)
+	kek := []byte("0123456789abcdef0123456789abcdef")
+	material := []byte("an escrowed 256 bit AES key ....")
+	kek_kid := m.seedKey(t, map[string]interface{}{
+		"name":     "escrow_kek",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_ops":  []interface{}{"WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+		"value":    base64.StdEncoding.EncodeToString(kek),
+	})
+	rsa_kid := m.seedKey(t, map[string]interface{}{
+		"name":     "escrow_rsa",
+		"group_id": group_id,
+		"obj_type": "RSA",
+		"key_size": 2048,
+		"key_ops":  []interface{}{"WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+	})
+	kid := m.seedKey(t, map[string]interface{}{
+		"name":     "escrowed_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_ops":  []interface{}{"ENCRYPT", "DECRYPT", "EXPORT", "APPMANAGEABLE"},
+		"value":    base64.StdEncoding.EncodeToString(material),
+	})
+	m.seedKey(t, map[string]interface{}{
+		"name":     "local_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_size": 256,
+		"key_ops":  []interface{}{"ENCRYPT", "DECRYPT", "APPMANAGEABLE"},
+	})
+
+	// checkWrapped unwraps the wrapped_value of a data source
+	checkWrapped := func(name string, unwrap func(wrapped []byte) ([]byte, error)) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			wrapped, err := base64.StdEncoding.DecodeString(s.RootModule().Resources[name].Primary.Attributes["wrapped_value"])
+			if err != nil {
+				return err
+			}
+			plain, err := unwrap(wrapped)
+			if err != nil || string(plain) != string(material) {
+				return fmt.Errorf("%s does not unwrap to the key material: %v", name, err)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			// the post-test destroy runs the configuration of the last step
+			{
+				Config: `
+data "dsm_wrapped_key" "local_key" {
+  name          = "local_key"
+  wrapping_name = "escrow_kek"
+  wrapping_alg  = "AES-KW"
+}
+`,
+				ExpectError: regexp.MustCompile("POST crypto/v1/wrapkey: .*not exportable"),
+			},
+			{
+				Config: `
+data "dsm_wrapped_key" "missing_kek" {
+  name          = "escrowed_key"
+  wrapping_name = "missing"
+  wrapping_alg  = "AES-KW"
+}
+`,
+				ExpectError: regexp.MustCompile("wrapping key: .*does not exist"),
+			},
+			{
+				Config: fmt.Sprintf(`
+data "dsm_wrapped_key" "both" {
+  kid           = %q
+  name          = "escrowed_key"
+  wrapping_name = "escrow_kek"
+  wrapping_alg  = "AES-KW"
+}
+`, kid),
+				ExpectError: regexp.MustCompile(`only one of`),
+			},
+			{
+				Config: fmt.Sprintf(`
+data "dsm_wrapped_key" "aes_kwp" {
+  name          = "escrowed_key"
+  wrapping_name = "escrow_kek"
+  wrapping_alg  = "AES-KWP"
+}
+
+data "dsm_wrapped_key" "rsa_oaep" {
+  kid          = %q
+  wrapping_kid = %q
+  wrapping_alg = "RSA-OAEP"
+}
+
+# the wrapped key is imported again, as in a second account with the same KEK
+resource "dsm_sobject" "restored" {
+  name          = "restored_key"
+  group_id      = data.dsm_wrapped_key.aes_kwp.group_id
+  obj_type      = data.dsm_wrapped_key.aes_kwp.obj_type
+  key_ops       = data.dsm_wrapped_key.aes_kwp.key_ops
+  wrapped_value = data.dsm_wrapped_key.aes_kwp.wrapped_value
+  wrapping_kid  = data.dsm_wrapped_key.aes_kwp.wrapping_kid
+  wrapping_alg  = data.dsm_wrapped_key.aes_kwp.wrapping_alg
+}
+`, kid, rsa_kid),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "kid", kid),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "wrapping_kid", kek_kid),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "obj_type", "AES"),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "key_size", "256"),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "key_ops.#", "4"),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.aes_kwp", "acct_id", m.acct_id),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.rsa_oaep", "name", "escrowed_key"),
+					resource.TestCheckResourceAttr("data.dsm_wrapped_key.rsa_oaep", "wrapping_name", "escrow_rsa"),
+					checkWrapped("data.dsm_wrapped_key.aes_kwp", func(wrapped []byte) ([]byte, error) {
+						return mockKeyUnwrap(kek, wrapped, true)
+					}),
+					checkWrapped("data.dsm_wrapped_key.rsa_oaep", func(wrapped []byte) ([]byte, error) {
+						return rsa.DecryptOAEP(sha1.New(), rand.Reader, m.signers[rsa_kid].(*rsa.PrivateKey), wrapped, nil)
+					}),
+					testUnitCheckMock(m, "keys", "dsm_sobject.restored", func(key map[string]interface{}) error {
+						if string(m.values[key["kid"].(string)]) != string(material) {
+							return fmt.Errorf("the wrapped key was not restored: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
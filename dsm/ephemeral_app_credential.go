@@ -0,0 +1,85 @@
+// **********
+// Terraform Provider - DSM: ephemeral resource: app credential
+// **********
+
+package dsm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// [-] Define Ephemeral App Credential
+type ephemeralAppCredential struct {
+	client *api_client
+}
+
+type ephemeralAppCredentialModel struct {
+	AppId      types.String `tfsdk:"app_id"`
+	Credential types.String `tfsdk:"credential"`
+}
+
+var _ ephemeral.EphemeralResourceWithConfigure = &ephemeralAppCredential{}
+
+func newEphemeralAppCredential() ephemeral.EphemeralResource {
+	return &ephemeralAppCredential{}
+}
+
+func (e *ephemeralAppCredential) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_app_credential"
+}
+
+func (e *ephemeralAppCredential) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Returns the API key of a Fortanix DSM app without storing it in the Terraform state or plan.",
+		Attributes: map[string]schema.Attribute{
+			"app_id": schema.StringAttribute{
+				Description: "The unique ID of the app from Fortanix DSM.",
+				Required:    true,
+			},
+			"credential": schema.StringAttribute{
+				Description: "The (sensitive) API key of the app, in the same format as the credential of dsm_app.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (e *ephemeralAppCredential) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	e.client = req.ProviderData.(*api_client)
+}
+
+func (e *ephemeralAppCredential) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ephemeralAppCredentialModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if e.client == nil {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to call DSM provider API client", "[E]: SDK: Terraform: the DSM provider is not configured")
+		return
+	}
+
+	app_id := data.AppId.ValueString()
+	credential, err := e.client.API().GetAppCredential(ctx, app_id)
+	if err != nil {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET sys/v1/apps/-/credential: %v", err))
+		return
+	}
+	if credential.Secret == nil {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to read the app credential", fmt.Sprintf("[E]: API: GET sys/v1/apps/-/credential: app %s does not authenticate with an API key", app_id))
+		return
+	}
+
+	data.Credential = types.StringValue(base64.StdEncoding.EncodeToString([]byte(app_id + ":" + *credential.Secret)))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,44 @@
+package dsm
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestUnitEphemeralAppCredential(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	app_id := m.seedApp(t, map[string]interface{}{"name": "example_app", "default_group": group_id})
+	cert_app_id := m.seedApp(t, map[string]interface{}{
+		"name":          "cert_app",
+		"default_group": group_id,
+		"credential":    map[string]interface{}{"certificate": "MIIB"},
+	})
+
+	server, schemas := testUnitProtoV5Server(t)
+	schema := schemas.EphemeralResourceSchemas["dsm_app_credential"]
+	open := func(app_id string) (*tfprotov5.OpenEphemeralResourceResponse, error) {
+		return server.OpenEphemeralResource(context.Background(), &tfprotov5.OpenEphemeralResourceRequest{
+			TypeName: "dsm_app_credential",
+			Config:   testUnitDynamicValue(t, schema, map[string]tftypes.Value{"app_id": tftypes.NewValue(tftypes.String, app_id)}),
+		})
+	}
+
+	opened, err := open(app_id)
+	testUnitProtoV5Diags(t, "OpenEphemeralResource", err, opened.Diagnostics)
+	secret := m.get("apps", app_id)["credential"].(map[string]interface{})["secret"].(string)
+	expected := base64.StdEncoding.EncodeToString([]byte(app_id + ":" + secret))
+	if got := testUnitString(t, testUnitDecode(t, schema, opened.Result)["credential"]); got != expected {
+		t.Errorf("credential is %q, expected %q", got, expected)
+	}
+
+	opened, err = open(cert_app_id)
+	if err != nil || !strings.Contains(testUnitProtoV5Error(opened.Diagnostics), "does not authenticate with an API key") {
+		t.Errorf("reading the API key of a certificate app did not fail: %v %v", err, opened.Diagnostics)
+	}
+}
@@ -0,0 +1,117 @@
+// **********
+// Terraform Provider - DSM: ephemeral resource: secret value
+// **********
+
+package dsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// [-] Define Ephemeral Secret Value
+type ephemeralSecretValue struct {
+	client *api_client
+}
+
+type ephemeralSecretValueModel struct {
+	Name  types.String `tfsdk:"name"`
+	Kid   types.String `tfsdk:"kid"`
+	Value types.String `tfsdk:"value"`
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &ephemeralSecretValue{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &ephemeralSecretValue{}
+)
+
+func newEphemeralSecretValue() ephemeral.EphemeralResource {
+	return &ephemeralSecretValue{}
+}
+
+func (e *ephemeralSecretValue) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secret_value"
+}
+
+func (e *ephemeralSecretValue) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exports the value of a Fortanix DSM secret or security object without storing it in the Terraform state or plan.\n\n" +
+			"`Note`: The security object should have the EXPORT permission. Exports under a quorum policy wait for the approval.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "The security object name in Fortanix DSM. Either name or kid should be specified.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"kid": schema.StringAttribute{
+				Description: "The security object ID in Fortanix DSM. Either name or kid should be specified.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"value": schema.StringAttribute{
+				Description: "The (sensitive) value of the security object in base64 format.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (e *ephemeralSecretValue) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	e.client = req.ProviderData.(*api_client)
+}
+
+func (e *ephemeralSecretValue) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var config ephemeralSecretValueModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Name.IsUnknown() || config.Kid.IsUnknown() {
+		return
+	}
+	if config.Name.IsNull() == config.Kid.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid security object reference", "Exactly one of name or kid should be specified.")
+	}
+}
+
+func (e *ephemeralSecretValue) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ephemeralSecretValueModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if e.client == nil {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to call DSM provider API client", "[E]: SDK: Terraform: the DSM provider is not configured")
+		return
+	}
+
+	security_object := map[string]interface{}{}
+	if data.Kid.IsNull() {
+		security_object["name"] = data.Name.ValueString()
+	} else {
+		security_object["kid"] = data.Kid.ValueString()
+	}
+
+	req_export, approval_diags, err := e.client.APICallBodyWithApproval(ctx, "POST", "crypto/v1/keys/export", security_object, approval_default_timeout)
+	resp.Diagnostics.Append(frameworkDiags(approval_diags)...)
+	if err != nil {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: POST crypto/v1/keys/export: %v", err))
+		return
+	}
+	value, ok := req_export["value"].(string)
+	if !ok {
+		resp.Diagnostics.AddError("[DSM SDK] Unable to export the security object", fmt.Sprintf("[E]: API: POST crypto/v1/keys/export: %v has no value", req_export["kid"]))
+		return
+	}
+
+	data.Name = types.StringValue(fmt.Sprint(req_export["name"]))
+	data.Kid = types.StringValue(fmt.Sprint(req_export["kid"]))
+	data.Value = types.StringValue(value)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,77 @@
+package dsm
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestUnitEphemeralSecretValue(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	group_id := m.seedGroup("example_group", nil)
+	quorum_group_id := m.seedGroup("quorum_group", map[string]interface{}{"approval_policy": m.quorumPolicy()})
+	value := base64.StdEncoding.EncodeToString([]byte("secret value"))
+	kid := m.seedKey(t, map[string]interface{}{
+		"name":     "example_secret",
+		"group_id": group_id,
+		"obj_type": "SECRET",
+		"key_ops":  []interface{}{"EXPORT", "APPMANAGEABLE"},
+		"value":    value,
+	})
+	m.seedKey(t, map[string]interface{}{
+		"name":     "quorum_secret",
+		"group_id": quorum_group_id,
+		"obj_type": "SECRET",
+		"key_ops":  []interface{}{"EXPORT", "APPMANAGEABLE"},
+		"value":    value,
+	})
+
+	server, schemas := testUnitProtoV5Server(t)
+	schema := schemas.EphemeralResourceSchemas["dsm_secret_value"]
+	ctx := context.Background()
+	open := func(values map[string]tftypes.Value) (*tfprotov5.OpenEphemeralResourceResponse, error) {
+		return server.OpenEphemeralResource(ctx, &tfprotov5.OpenEphemeralResourceRequest{
+			TypeName: "dsm_secret_value",
+			Config:   testUnitDynamicValue(t, schema, values),
+		})
+	}
+
+	for _, values := range []map[string]tftypes.Value{
+		{"name": tftypes.NewValue(tftypes.String, "example_secret")},
+		{"kid": tftypes.NewValue(tftypes.String, kid)},
+		// the export waits for the quorum of the group
+		{"name": tftypes.NewValue(tftypes.String, "quorum_secret")},
+	} {
+		opened, err := open(values)
+		testUnitProtoV5Diags(t, "OpenEphemeralResource", err, opened.Diagnostics)
+		result := testUnitDecode(t, schema, opened.Result)
+		if got := testUnitString(t, result["value"]); got != value {
+			t.Errorf("%v: value is %q, expected %q", values, got, value)
+		}
+		if testUnitString(t, result["kid"]) == "" || testUnitString(t, result["name"]) == "" {
+			t.Errorf("%v: name and kid are not set: %v", values, result)
+		}
+	}
+
+	opened, err := open(map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, "missing")})
+	if err != nil || !strings.Contains(testUnitProtoV5Error(opened.Diagnostics), "POST crypto/v1/keys/export") {
+		t.Errorf("exporting a missing secret did not fail: %v %v", err, opened.Diagnostics)
+	}
+
+	validated, err := server.ValidateEphemeralResourceConfig(ctx, &tfprotov5.ValidateEphemeralResourceConfigRequest{
+		TypeName: "dsm_secret_value",
+		Config: testUnitDynamicValue(t, schema, map[string]tftypes.Value{
+			"name": tftypes.NewValue(tftypes.String, "example_secret"),
+			"kid":  tftypes.NewValue(tftypes.String, kid),
+		}),
+	})
+	if err != nil || !strings.Contains(testUnitProtoV5Error(validated.Diagnostics), "Exactly one of name or kid") {
+		t.Errorf("name and kid together are valid: %v %v", err, validated.Diagnostics)
+	}
+}
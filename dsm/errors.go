@@ -0,0 +1,132 @@
+// **********
+// Terraform Provider - DSM: API errors
+// **********
+
+package dsm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DSMError describes a failed DSM API call. StatusCode is 0 when the request
+// never got an answer from DSM, in which case Err holds the transport error.
+type DSMError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	Method     string
+	Path       string
+	Err        error
+}
+
+func (e *DSMError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s %s: %v", e.Method, e.Path, e.Err)
+	}
+	msg := fmt.Sprintf("%s %s %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id: %s)", e.RequestID)
+	}
+	return msg
+}
+
+func (e *DSMError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same call may succeed if repeated later.
+func (e *DSMError) Retryable() bool {
+	switch e.StatusCode {
+	case 0, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newDSMError builds the error for a non 2xx DSM response.
+func newDSMError(method string, path string, r *http.Response, body []byte) *DSMError {
+	e := &DSMError{
+		StatusCode: r.StatusCode,
+		Method:     method,
+		Path:       path,
+		Message:    dsmErrorMessage(body),
+		RequestID:  requestID(r),
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(r.StatusCode)
+	}
+	return e
+}
+
+// DSM answers with either a plain text message or a JSON object
+func dsmErrorMessage(body []byte) string {
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		for _, k := range []string{"message", "error", "msg"} {
+			if msg, ok := resp[k].(string); ok && msg != "" {
+				return msg
+			}
+		}
+		return strings.TrimSpace(string(body))
+	}
+	var msg string
+	if err := json.Unmarshal(body, &msg); err == nil {
+		return msg
+	}
+	return strings.TrimSpace(string(body))
+}
+
+func asDSMError(err error) (*DSMError, bool) {
+	var e *DSMError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+func hasStatus(err error, status int) bool {
+	e, ok := asDSMError(err)
+	return ok && e.StatusCode == status
+}
+
+// IsNotFound reports whether DSM answered 404, i.e. the object is gone.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether DSM rejected the call with 409.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsBadRequest reports whether DSM rejected the call with 400.
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// DSM answers an operation guarded by a quorum policy with a 403 whose
+// message says that the operation requires (quorum) approval. Other 403
+// messages may mention approvals or quorums, e.g. a missing permission to
+// change an approval policy, and must not be retried as approval requests.
+var approval_required_message = regexp.MustCompile(`(?i)\brequires\s+(quorum\s+)?approval\b`)
+
+// IsApprovalRequired reports whether the call is guarded by a quorum
+// approval policy and has to go through sys/v1/approval_requests.
+func IsApprovalRequired(err error) bool {
+	e, ok := asDSMError(err)
+	if !ok || e.StatusCode != http.StatusForbidden {
+		return false
+	}
+	return approval_required_message.MatchString(e.Message)
+}
+
+// IsRetryable reports whether the call failed for a transient reason.
+func IsRetryable(err error) bool {
+	e, ok := asDSMError(err)
+	return ok && e.Retryable()
+}
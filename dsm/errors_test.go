@@ -0,0 +1,56 @@
+package dsm
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDSMErrorClassification(t *testing.T) {
+	newResp := func(status int) *http.Response {
+		r := &http.Response{StatusCode: status, Header: http.Header{}}
+		r.Header.Set("Request-Id", "req-1")
+		return r
+	}
+
+	notFound := newDSMError("GET", "crypto/v1/keys/1", newResp(404), []byte(`"sobject does not exist"`))
+	if !IsNotFound(notFound) || IsConflict(notFound) {
+		t.Fatalf("expected not found: %v", notFound)
+	}
+	if notFound.Message != "sobject does not exist" || notFound.RequestID != "req-1" {
+		t.Fatalf("unexpected error fields: %#v", notFound)
+	}
+
+	wrapped := fmt.Errorf("read: %w", newDSMError("POST", "sys/v1/groups", newResp(409), []byte(`{"message":"duplicate name"}`)))
+	if !IsConflict(wrapped) {
+		t.Fatalf("expected conflict through wrapping: %v", wrapped)
+	}
+
+	approval := newDSMError("POST", "crypto/v1/keys", newResp(403), []byte("This operation requires quorum approval"))
+	if !IsApprovalRequired(approval) {
+		t.Fatalf("expected approval required: %v", approval)
+	}
+	if !IsApprovalRequired(newDSMError("PATCH", "sys/v1/groups/1", newResp(403), []byte(`{"message":"Operation requires approval"}`))) {
+		t.Fatal("expected approval required from a JSON message")
+	}
+	for _, msg := range []string{
+		"forbidden",
+		"User does not have permission to modify the quorum approval policy",
+		"Approval request 1 is not found for this user",
+		"Quorum members cannot approve their own request",
+	} {
+		if IsApprovalRequired(newDSMError("POST", "crypto/v1/keys", newResp(403), []byte(msg))) {
+			t.Fatalf("403 %q should not require approval", msg)
+		}
+	}
+	if IsApprovalRequired(newDSMError("POST", "crypto/v1/keys", newResp(400), []byte("This operation requires quorum approval"))) {
+		t.Fatal("only a 403 requires approval")
+	}
+
+	if !IsRetryable(newDSMError("GET", "sys/v1/version", newResp(503), nil)) || IsRetryable(notFound) {
+		t.Fatal("unexpected retryability")
+	}
+	if IsNotFound(fmt.Errorf("plain error")) {
+		t.Fatal("plain errors are not DSM errors")
+	}
+}
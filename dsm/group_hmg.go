@@ -0,0 +1,423 @@
+// **********
+// Terraform Provider - DSM: external HSM/KMS (HMG) of groups
+// **********
+
+package dsm
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// kinds of an HMG that DSM supports
+var hmg_kinds = []string{"NCIPHER", "SAFENET", "AWSCLOUDHSM", "AWSKMS", "FORTANIX", "FORTANIXFIPSCLUSTER", "AZUREKEYVAULT", "GCPKEYRING"}
+
+// HMG options of the hmg block, as DSM names them
+var (
+	hmg_string_options = []string{"url", "access_key", "secret_key", "region", "service", "tenant_id", "client_id",
+		"subscription_id", "key_vault_type", "project_id", "location", "key_ring", "service_account_email", "private_key", "pin"}
+	// DSM never returns these
+	hmg_secret_options = []string{"secret_key", "private_key", "pin"}
+	// an hmg block is identified by these: changing them replaces the HMG
+	hmg_identity_options = []string{"kind", "url", "project_id", "location", "key_ring"}
+)
+
+// [-]: hmg block of dsm_group
+func groupHmgSchema() *schema.Schema {
+	options := map[string]*schema.Schema{
+		"hmg_id": {
+			Description: "HSM/KMS ID from Fortanix DSM.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"kind": {
+			Description:  "The kind of the external HSM or KMS. The values are " + strings.Join(hmg_kinds, ", ") + ".",
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringInSlice(hmg_kinds, false),
+		},
+		"url": {
+			Description: "The URL or the host of the external HSM or KMS, e.g. kms.us-east-1.amazonaws.com or https://sampleakv.vault.azure.net/.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"port": {
+			Description: "The port of the external HSM.",
+			Type:        schema.TypeInt,
+			Optional:    true,
+		},
+		"hsm_order": {
+			Description: "The order of the HMG for the hmg_redundancy of the group: DSM tries a lower order first. Default is 0.",
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Default:     0,
+		},
+		"tls": {
+			Description: "The TLS settings of the connection to the external HSM or KMS.",
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"mode": {
+						Description:  "The TLS mode. The values are disabled, opportunistic and required. Default is required.",
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "required",
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "opportunistic", "required"}, false),
+					},
+					"validate_hostname": {
+						Description: "Whether the hostname of the certificate should be validated.",
+						Type:        schema.TypeBool,
+						Optional:    true,
+					},
+					"ca_set": {
+						Description: "The set of trusted certificate authorities. The value is global_roots. It is used unless ca_certs is specified.",
+						Type:        schema.TypeString,
+						Optional:    true,
+						Default:     "global_roots",
+					},
+					"ca_certs": {
+						Description: "Pinned certificates, in PEM format, that the external HSM or KMS certificate should chain to.",
+						Type:        schema.TypeList,
+						Optional:    true,
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+				},
+			},
+		},
+		"access_key": {
+			Description: "The Access Key ID of AWSKMS.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"secret_key": {
+			Description: "The Secret Access Key of AWSKMS, or the client secret of AZUREKEYVAULT.",
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"region": {
+			Description: "The region of AWSKMS, e.g. us-east-1.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"service": {
+			Description: "The service of AWSKMS. The value is kms.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"tenant_id": {
+			Description: "The tenant ID of AZUREKEYVAULT.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"client_id": {
+			Description: "The client ID of AZUREKEYVAULT.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"subscription_id": {
+			Description: "The subscription ID of AZUREKEYVAULT.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"key_vault_type": {
+			Description: "The key vault type of AZUREKEYVAULT. The values are STANDARD and PREMIUM.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"project_id": {
+			Description: "The project ID of GCPKEYRING.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"location": {
+			Description: "The location of GCPKEYRING, e.g. us-east1.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"key_ring": {
+			Description: "The key ring name of GCPKEYRING.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"service_account_email": {
+			Description: "The service account email of GCPKEYRING.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"private_key": {
+			Description: "The base64 encoded private key of the service account of GCPKEYRING.",
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"slot": {
+			Description: "The slot of the external HSM, e.g. NCIPHER or SAFENET.",
+			Type:        schema.TypeInt,
+			Optional:    true,
+		},
+		"pin": {
+			Description: "The PIN of the slot of the external HSM.",
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+		},
+	}
+	return &schema.Schema{
+		Description: "An external HSM or KMS of the group. Repeat the block for several HMGs, e.g. for failover. For more examples refer Guides/create_BYOK_groups\n" +
+			"   * A block is identified by its kind, url, project_id, location and key_ring: changing them replaces the HMG, other changes modify it in place.",
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: options,
+		},
+	}
+}
+
+// [-]: HMG request of an hmg block
+func groupHmgWrite(hmg map[string]interface{}) map[string]interface{} {
+	request := map[string]interface{}{
+		"kind":      hmg["kind"].(string),
+		"hsm_order": hmg["hsm_order"].(int),
+	}
+	for _, option := range hmg_string_options {
+		if value, _ := hmg[option].(string); len(value) > 0 {
+			request[option] = value
+		}
+	}
+	if port, _ := hmg["port"].(int); port > 0 {
+		request["port"] = port
+	}
+	switch request["kind"] {
+	case "NCIPHER", "SAFENET", "AWSCLOUDHSM", "FORTANIX", "FORTANIXFIPSCLUSTER":
+		request["slot"] = hmg["slot"].(int)
+	}
+	if tls_list, _ := hmg["tls"].([]interface{}); len(tls_list) > 0 && tls_list[0] != nil {
+		tls := tls_list[0].(map[string]interface{})
+		tls_request := map[string]interface{}{
+			"mode": tls["mode"].(string),
+		}
+		if tls["mode"].(string) != "disabled" {
+			tls_request["validate_hostname"] = tls["validate_hostname"].(bool)
+			if ca_certs, _ := tls["ca_certs"].([]interface{}); len(ca_certs) > 0 {
+				tls_request["ca"] = map[string]interface{}{"pinned": ca_certs}
+			} else {
+				tls_request["ca"] = map[string]interface{}{"ca_set": tls["ca_set"].(string)}
+			}
+		}
+		request["tls"] = tls_request
+	}
+	return request
+}
+
+// [-]: hmg block of an HMG from DSM, or of the JSON of schema version 0
+func groupHmgRead(hmg_id string, config map[string]interface{}) map[string]interface{} {
+	hmg := map[string]interface{}{
+		"hmg_id": hmg_id,
+	}
+	if kind, ok := config["kind"].(string); ok {
+		hmg["kind"] = kind
+	}
+	for _, option := range hmg_string_options {
+		if value, ok := config[option].(string); ok {
+			hmg[option] = value
+		}
+	}
+	for _, option := range []string{"port", "slot", "hsm_order"} {
+		if value, ok := config[option].(float64); ok {
+			hmg[option] = int(value)
+		}
+	}
+	if tls_config, ok := config["tls"].(map[string]interface{}); ok {
+		tls := map[string]interface{}{}
+		if mode, ok := tls_config["mode"].(string); ok {
+			tls["mode"] = mode
+		}
+		if validate_hostname, ok := tls_config["validate_hostname"].(bool); ok {
+			tls["validate_hostname"] = validate_hostname
+		}
+		if ca, ok := tls_config["ca"].(map[string]interface{}); ok {
+			if ca_set, ok := ca["ca_set"].(string); ok {
+				tls["ca_set"] = ca_set
+			}
+			if pinned, ok := ca["pinned"].([]interface{}); ok {
+				tls["ca_certs"] = pinned
+			}
+		}
+		hmg["tls"] = []interface{}{tls}
+	}
+	return hmg
+}
+
+// [-]: identity of an hmg block
+func groupHmgKey(hmg map[string]interface{}) string {
+	var key []string
+	for _, option := range hmg_identity_options {
+		value, _ := hmg[option].(string)
+		key = append(key, value)
+	}
+	return strings.Join(key, "\x00")
+}
+
+// [-]: add_hmg, mod_hmg and del_hmg to change the old hmg blocks to the new ones
+// The new blocks are matched to the old blocks by identity, as the position of
+// a block may change.
+func groupHmgChanges(old_hmg []interface{}, new_hmg []interface{}) ([]interface{}, map[string]interface{}, []string) {
+	add_hmg := []interface{}{}
+	mod_hmg := map[string]interface{}{}
+	del_hmg := []string{}
+	matched := make(map[int]bool)
+	for _, new_block := range new_hmg {
+		new_config := new_block.(map[string]interface{})
+		old_index := -1
+		for i, old_block := range old_hmg {
+			if !matched[i] && groupHmgKey(old_block.(map[string]interface{})) == groupHmgKey(new_config) {
+				old_index = i
+				break
+			}
+		}
+		if old_index < 0 {
+			add_hmg = append(add_hmg, groupHmgWrite(new_config))
+			continue
+		}
+		matched[old_index] = true
+		old_config := old_hmg[old_index].(map[string]interface{})
+		if request := groupHmgWrite(new_config); !reflect.DeepEqual(request, groupHmgWrite(old_config)) {
+			mod_hmg[old_config["hmg_id"].(string)] = request
+		}
+	}
+	for i, old_block := range old_hmg {
+		if !matched[i] {
+			del_hmg = append(del_hmg, old_block.(map[string]interface{})["hmg_id"].(string))
+		}
+	}
+	return add_hmg, mod_hmg, del_hmg
+}
+
+// [-]: hmg blocks of the HMGs of a group from DSM, in the order of the given blocks
+// An HMG is matched to a block by identity and then by hmg_id, and keeps the
+// secrets of the block. HMGs without a block follow by hsm_order.
+func groupHmgState(hmg_blocks []interface{}, dsm_hmg map[string]interface{}) []interface{} {
+	read := make(map[string]map[string]interface{}, len(dsm_hmg))
+	var hmg_ids []string
+	for hmg_id, config := range dsm_hmg {
+		config, _ := config.(map[string]interface{})
+		read[hmg_id] = groupHmgRead(hmg_id, config)
+		hmg_ids = append(hmg_ids, hmg_id)
+	}
+	sort.Slice(hmg_ids, func(i, j int) bool {
+		order_i, _ := read[hmg_ids[i]]["hsm_order"].(int)
+		order_j, _ := read[hmg_ids[j]]["hsm_order"].(int)
+		if order_i != order_j {
+			return order_i < order_j
+		}
+		return hmg_ids[i] < hmg_ids[j]
+	})
+
+	state := []interface{}{}
+	used := make(map[string]bool)
+	match := func(block map[string]interface{}) string {
+		hmg_id, _ := block["hmg_id"].(string)
+		if hmg, ok := read[hmg_id]; ok && !used[hmg_id] && groupHmgKey(hmg) == groupHmgKey(block) {
+			return hmg_id
+		}
+		for _, id := range hmg_ids {
+			if !used[id] && groupHmgKey(read[id]) == groupHmgKey(block) {
+				return id
+			}
+		}
+		if _, ok := read[hmg_id]; ok && !used[hmg_id] {
+			return hmg_id
+		}
+		return ""
+	}
+	for _, block := range hmg_blocks {
+		block, _ := block.(map[string]interface{})
+		hmg_id := match(block)
+		if hmg_id == "" {
+			continue
+		}
+		used[hmg_id] = true
+		hmg := read[hmg_id]
+		for _, option := range hmg_secret_options {
+			if _, ok := hmg[option]; !ok {
+				hmg[option] = block[option]
+			}
+		}
+		state = append(state, hmg)
+	}
+	for _, hmg_id := range hmg_ids {
+		if !used[hmg_id] {
+			state = append(state, read[hmg_id])
+		}
+	}
+	return state
+}
+
+// [-]: validate that the hmg blocks of dsm_group are distinct at plan time
+func validateGroupHmgCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("hmg") {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for i, block := range d.Get("hmg").([]interface{}) {
+		block, _ := block.(map[string]interface{})
+		key := groupHmgKey(block)
+		if seen[key] {
+			return cty.GetAttrPath("hmg").IndexInt(i).NewErrorf("hmg %d has the same kind, url, project_id, location and key_ring as an earlier hmg", i)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// [-]: state upgrader of the hmg JSON string of schema version 0 to the blocks
+func groupHmgStateUpgrader(resource *schema.Resource) schema.StateUpgrader {
+	v0_schema := make(map[string]*schema.Schema, len(resource.Schema))
+	for key, value := range resource.Schema {
+		v0_schema[key] = value
+	}
+	v0_schema["hmg"] = &schema.Schema{
+		Type:      schema.TypeString,
+		Optional:  true,
+		Sensitive: true,
+	}
+	delete(v0_schema, "hmg_redundancy")
+	v0_schema["approval_policy"] = approvalPolicyJSONSchema()
+	v0 := &schema.Resource{
+		Schema:   v0_schema,
+		Timeouts: resource.Timeouts,
+	}
+	return schema.StateUpgrader{
+		Version: 0,
+		Type:    v0.CoreConfigSchema().ImpliedType(),
+		Upgrade: groupHmgStateUpgradeV0,
+	}
+}
+
+// The JSON was the configuration of the single HMG of the group, of the
+// top-level hmg_id. JSON numbers decode as float64, as they come from DSM.
+// Without a JSON the next read adds the HMGs of the group from DSM.
+func groupHmgStateUpgradeV0(ctx context.Context, raw_state map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	hmg_json, _ := raw_state["hmg"].(string)
+	raw_state["hmg"] = []interface{}{}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(hmg_json), &config); err != nil || len(config) == 0 {
+		return raw_state, nil
+	}
+	hmg_id, _ := raw_state["hmg_id"].(string)
+	raw_state["hmg"] = []interface{}{groupHmgRead(hmg_id, config)}
+	return raw_state, nil
+}
@@ -0,0 +1,120 @@
+// **********
+// Terraform Provider - DSM: semantic comparison of JSON strings
+// **********
+
+package dsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// [-]: DiffSuppressFunc of a JSON string attribute
+// JSON documents that only differ in key order or whitespace do not differ.
+func jsonDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	return jsonEquivalent(old, new, nil)
+}
+
+// [-]: DiffSuppressFunc of a JSON string attribute to which DSM adds defaults
+// Keys of the old document that the new one does not have are ignored when
+// defaults has them with the same value, or with a nil value for any value.
+// defaults has the nesting of the document, and the defaults of an array
+// apply to each of its elements.
+func jsonDiffSuppressWithDefaults(defaults map[string]interface{}) schema.SchemaDiffSuppressFunc {
+	// the defaults are compared with decoded JSON, e.g. float64 numbers
+	var decoded map[string]interface{}
+	if b, err := json.Marshal(defaults); err == nil && json.Unmarshal(b, &decoded) == nil {
+		defaults = decoded
+	}
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		return jsonEquivalent(old, new, defaults)
+	}
+}
+
+// [-]: DiffSuppressFunc of a string map with JSON values at the given keys, e.g. aws-policy of custom_metadata
+func jsonMapDiffSuppress(json_keys ...string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		for _, key := range json_keys {
+			if strings.HasSuffix(k, "."+key) {
+				return jsonEquivalent(old, new, nil)
+			}
+		}
+		return false
+	}
+}
+
+// [-]: StateFunc of a JSON string attribute: compact JSON with sorted keys, or the string as is when it is not JSON
+func normalizeJSON(value interface{}) string {
+	json_string, _ := value.(string)
+	var decoded interface{}
+	decoder := json.NewDecoder(strings.NewReader(json_string))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil || decoder.More() {
+		return json_string
+	}
+	var normalized bytes.Buffer
+	encoder := json.NewEncoder(&normalized)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(decoded); err != nil {
+		return json_string
+	}
+	return strings.TrimSuffix(normalized.String(), "\n")
+}
+
+// [-]: whether two JSON strings are the same document, apart from the defaults of the old one
+// Strings that are not both JSON are compared as they are.
+func jsonEquivalent(old string, new string, defaults map[string]interface{}) bool {
+	if old == new {
+		return true
+	}
+	var old_value, new_value interface{}
+	if json.Unmarshal([]byte(old), &old_value) != nil || json.Unmarshal([]byte(new), &new_value) != nil {
+		return false
+	}
+	return jsonEqual(old_value, new_value, defaults)
+}
+
+func jsonEqual(old interface{}, new interface{}, defaults interface{}) bool {
+	switch old := old.(type) {
+	case map[string]interface{}:
+		new, ok := new.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		default_map, _ := defaults.(map[string]interface{})
+		for key, old_item := range old {
+			if new_item, ok := new[key]; ok {
+				if !jsonEqual(old_item, new_item, default_map[key]) {
+					return false
+				}
+				continue
+			}
+			default_value, ok := default_map[key]
+			if !ok || (default_value != nil && !jsonEqual(old_item, default_value, nil)) {
+				return false
+			}
+		}
+		for key := range new {
+			if _, ok := old[key]; !ok {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		new, ok := new.([]interface{})
+		if !ok || len(old) != len(new) {
+			return false
+		}
+		for i := range old {
+			if !jsonEqual(old[i], new[i], defaults) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(old, new)
+}
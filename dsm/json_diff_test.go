@@ -0,0 +1,69 @@
+package dsm
+
+import (
+	"testing"
+)
+
+func TestJSONDiffSuppress(t *testing.T) {
+	for _, c := range []struct {
+		old, new   string
+		equivalent bool
+	}{
+		{`{"aes":{"key_sizes":[128,256]}}`, "{\n  \"aes\": { \"key_sizes\": [128, 256] }\n}", true},
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, true},
+		{`{"key_sizes":[128,256]}`, `{"key_sizes":[256,128]}`, false},
+		{`{"aes":{},"legacy_policy":"allowed"}`, `{"aes":{}}`, false},
+		{`{"aes":{}}`, `{"aes":{},"legacy_policy":"allowed"}`, false},
+		{`{"n":1}`, `{"n":1.0}`, true},
+		{`not json`, `not json`, true},
+		{`not json`, `{}`, false},
+		{``, `{}`, false},
+	} {
+		if equivalent := jsonDiffSuppress("cryptographic_policy", c.old, c.new, nil); equivalent != c.equivalent {
+			t.Errorf("%s and %s: equivalent is %t, expected %t", c.old, c.new, equivalent, c.equivalent)
+		}
+	}
+
+	with_defaults := jsonDiffSuppressWithDefaults(map[string]interface{}{
+		"public_exponent":  65537,
+		"key_size":         nil,
+		"signature_policy": map[string]interface{}{"padding": nil},
+	})
+	for _, c := range []struct {
+		old, new   string
+		equivalent bool
+	}{
+		{`{"key_size":2048,"public_exponent":65537}`, `{}`, true},
+		{`{"public_exponent":3}`, `{}`, false},
+		{`{}`, `{"key_size":2048}`, false},
+		{`{"signature_policy":[{"padding":{"PSS":{}}},{}]}`, `{"signature_policy":[{},{}]}`, true},
+		{`{"signature_policy":[{"hash":"SHA256"}]}`, `{"signature_policy":[{}]}`, false},
+		{`{"legacy_policy":"allowed"}`, `{}`, false},
+	} {
+		if equivalent := with_defaults("rsa", c.old, c.new, nil); equivalent != c.equivalent {
+			t.Errorf("%s and %s with defaults: equivalent is %t, expected %t", c.old, c.new, equivalent, c.equivalent)
+		}
+	}
+
+	aws_policy := jsonMapDiffSuppress("aws-policy")
+	if !aws_policy("custom_metadata.aws-policy", `{"Version":"2012-10-17","Id":"key-default-1"}`, `{"Id":"key-default-1","Version":"2012-10-17"}`, nil) {
+		t.Error("reordered aws-policy should not differ")
+	}
+	if aws_policy("custom_metadata.aws-aliases", `{"a":1}`, `{ "a": 1 }`, nil) {
+		t.Error("only the JSON keys of the map should be compared as JSON")
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	for input, expected := range map[string]string{
+		"{\n  \"b\": [1, 2.50],\n  \"a\": \"<x>\"\n}": `{"a":"<x>","b":[1,2.50]}`,
+		`12345678901234567890`:                        `12345678901234567890`,
+		`not json`:                                    `not json`,
+		`{} {}`:                                       `{} {}`,
+		``:                                            ``,
+	} {
+		if normalized := normalizeJSON(input); normalized != expected {
+			t.Errorf("normalized %q to %q, expected %q", input, normalized, expected)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// **********
+// Terraform Provider - DSM: request logging
+// **********
+
+package dsm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// fields never written to the logs, wherever they appear in a body
+var redacted_fields = map[string]bool{
+	"value":        true,
+	"password":     true,
+	"credential":   true,
+	"secret_key":   true,
+	"access_token": true,
+}
+
+const redacted_value = "[REDACTED]"
+
+// context key holding the attempt counter of a request
+type request_attempts_key struct{}
+
+// [-]: RequestLogHook of the retryable client, records the attempt number
+func countAttempts(_ retryablehttp.Logger, req *http.Request, attempt int) {
+	if attempts, ok := req.Context().Value(request_attempts_key{}).(*int); ok {
+		*attempts = attempt
+	}
+}
+
+// [-]: request id DSM assigned to a response
+func requestID(r *http.Response) string {
+	if id := r.Header.Get("Request-Id"); id != "" {
+		return id
+	}
+	return r.Header.Get("X-Request-Id")
+}
+
+// [-]: log a request to DSM
+// Method, path, status, latency, retries and request id are logged at DEBUG,
+// the redacted request and response bodies at TRACE.
+func (c *FXHTTPClient) logged(req *retryablehttp.Request) (*http.Response, error) {
+	attempts := 0
+	req = req.WithContext(context.WithValue(req.Context(), request_attempts_key{}, &attempts))
+	ctx := req.Context()
+	fields := map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	}
+	if body, err := req.BodyBytes(); err == nil && len(body) > 0 {
+		tflog.Trace(ctx, "DSM API request body", map[string]interface{}{
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"body":   redactBody(body),
+		})
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	fields["latency_ms"] = time.Since(start).Milliseconds()
+	fields["retries"] = attempts
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "DSM API request failed", fields)
+		return nil, err
+	}
+	fields["status"] = resp.StatusCode
+	if id := requestID(resp); id != "" {
+		fields["request_id"] = id
+	}
+	tflog.Debug(ctx, "DSM API request", fields)
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) > 0 {
+		fields["body"] = redactBody(body)
+		tflog.Trace(ctx, "DSM API response body", fields)
+	}
+	return resp, nil
+}
+
+// [-]: redact secrets of a JSON body, other bodies are logged as is
+func redactBody(body []byte) string {
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(redactValue(parsed))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if redacted_fields[k] {
+				v[k] = redacted_value
+			} else {
+				v[k] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return v
+}
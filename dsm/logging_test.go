@@ -0,0 +1,27 @@
+package dsm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	body := `{"name":"key","value":"c2VjcmV0","creds":[{"password":"p","user":"u"}],"access_token":"t"}`
+	var redacted map[string]interface{}
+	if err := json.Unmarshal([]byte(redactBody([]byte(body))), &redacted); err != nil {
+		t.Fatalf("redacted body is not JSON: %s", err)
+	}
+	if redacted["name"] != "key" {
+		t.Fatalf("name should be kept: %v", redacted)
+	}
+	if redacted["value"] != redacted_value || redacted["access_token"] != redacted_value {
+		t.Fatalf("secrets should be redacted: %v", redacted)
+	}
+	cred := redacted["creds"].([]interface{})[0].(map[string]interface{})
+	if cred["password"] != redacted_value || cred["user"] != "u" {
+		t.Fatalf("nested secrets should be redacted: %v", cred)
+	}
+	if got := redactBody([]byte("not json")); got != "not json" {
+		t.Fatalf("non JSON bodies are logged as is: %s", got)
+	}
+}
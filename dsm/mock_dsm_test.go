@@ -0,0 +1,1926 @@
+// **********
+// Terraform Provider - DSM: offline DSM stand-in for unit tests
+// **********
+
+package dsm
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	mock_username = "admin@example.com"
+	mock_password = "mock-password"
+
+	// DSM answers a guarded operation with this message, see IsApprovalRequired
+	mock_quorum_message = "This operation requires quorum approval"
+)
+
+// mockDSM is an in-process stand-in for the DSM REST API of one account.
+// Objects are kept in memory and every call answers with the status codes
+// and messages of DSM, so resources can be tested without a cluster.
+type mockDSM struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	acct_id string
+	user_id string
+	// session token -> selected account, "" until select_account
+	sessions map[string]string
+	// collection name -> objects, see mock_collections
+	objects map[string]*mockCollection
+	// key material, never returned by GET
+	values  map[string][]byte
+	signers map[string]crypto.Signer
+
+	// an approval request is decided after this many polls
+	approval_polls int
+	// approval requests are denied instead of approved
+	deny_approvals bool
+
+	// a session expires after this many calls, 0 keeps it until terminate
+	session_lifetime int
+	session_calls    map[string]int
+	// calls answered with 401
+	unauthorized int
+}
+
+// collection name -> id field
+var mock_collections = map[string]string{
+	"keys":              "kid",
+	"groups":            "group_id",
+	"apps":              "app_id",
+	"users":             "user_id",
+	"plugins":           "plugin_id",
+	"accounts":          "acct_id",
+	"approval_requests": "request_id",
+	"roles":             "role_id",
+}
+
+// [-]: insertion ordered objects of one collection
+type mockCollection struct {
+	id_field string
+	ids      []string
+	items    map[string]map[string]interface{}
+}
+
+func (c *mockCollection) get(id string) map[string]interface{} {
+	return c.items[id]
+}
+
+func (c *mockCollection) put(obj map[string]interface{}) {
+	id := obj[c.id_field].(string)
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = obj
+}
+
+func (c *mockCollection) remove(id string) bool {
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	for i := range c.ids {
+		if c.ids[i] == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (c *mockCollection) all() []map[string]interface{} {
+	all := make([]map[string]interface{}, 0, len(c.ids))
+	for _, id := range c.ids {
+		all = append(all, c.items[id])
+	}
+	return all
+}
+
+func (c *mockCollection) find(match func(map[string]interface{}) bool) map[string]interface{} {
+	for _, obj := range c.all() {
+		if match(obj) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// a response body sent as text/plain, which is how DSM reports errors
+type mockMessage string
+
+// newMockDSM starts a DSM stand-in with one account, its administrator and
+// the built-in roles, and points the provider at it. It is closed when the
+// test ends.
+func newMockDSM(t *testing.T) *mockDSM {
+	m := &mockDSM{
+		acct_id:        mockUUID(),
+		user_id:        mockUUID(),
+		sessions:       map[string]string{},
+		session_calls:  map[string]int{},
+		objects:        map[string]*mockCollection{},
+		values:         map[string][]byte{},
+		signers:        map[string]crypto.Signer{},
+		approval_polls: 1,
+	}
+	for name, id_field := range mock_collections {
+		m.objects[name] = &mockCollection{id_field: id_field, items: map[string]map[string]interface{}{}}
+	}
+	m.objects["accounts"].put(map[string]interface{}{
+		"acct_id":         m.acct_id,
+		"name":            "Terraform Unit Tests",
+		"enabled":         true,
+		"approval_policy": nil,
+	})
+	m.objects["users"].put(map[string]interface{}{
+		"user_id":        m.user_id,
+		"user_email":     mock_username,
+		"first_name":     "Account",
+		"last_name":      "Administrator",
+		"account_role":   []interface{}{"ACCOUNTADMINISTRATOR"},
+		"groups":         map[string]interface{}{},
+		"email_verified": true,
+		"has_password":   true,
+	})
+	for _, role := range []string{"ACCOUNTADMINISTRATOR", "ACCOUNTMEMBER", "ACCOUNTAUDITOR", "GROUPADMINISTRATOR", "GROUPAUDITOR", "Key Custodian"} {
+		kind := "ACCOUNT"
+		if strings.HasPrefix(role, "GROUP") || role == "Key Custodian" {
+			kind = "GROUP"
+		}
+		m.objects["roles"].put(map[string]interface{}{
+			"role_id": mockUUID(),
+			"name":    role,
+			"kind":    kind,
+			"acct_id": m.acct_id,
+		})
+	}
+	m.Server = httptest.NewServer(m)
+	t.Cleanup(m.Close)
+	// the provider block of the test configurations stays empty, like
+	// for acceptance tests it is configured from the environment
+	t.Setenv("DSM_ENDPOINT", m.URL)
+	t.Setenv("DSM_USERNAME", mock_username)
+	t.Setenv("DSM_PASSWORD", mock_password)
+	t.Setenv("DSM_ACCT_ID", m.acct_id)
+	return m
+}
+
+// [-]: random id in the UUID format of DSM
+func mockUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// [-]: DSM timestamp, e.g. 20221113T101010Z
+func mockTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// [-]: deep copy through JSON, so callers never share the stored maps
+func mockClone(in interface{}) interface{} {
+	raw, _ := json.Marshal(in)
+	var out interface{}
+	json.Unmarshal(raw, &out)
+	return out
+}
+
+// quorumPolicy is an approval policy that makes every guarded operation of
+// its group or account wait for the approval of the mock user.
+func (m *mockDSM) quorumPolicy() map[string]interface{} {
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"quorum": map[string]interface{}{
+				"n":       1,
+				"members": []interface{}{map[string]interface{}{"user": m.user_id}},
+			},
+		},
+		"manage_groups": true,
+	}
+}
+
+func mockError(status int, format string, args ...interface{}) (int, interface{}) {
+	return status, mockMessage(fmt.Sprintf(format, args...))
+}
+
+func (m *mockDSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	path := strings.Trim(r.URL.Path, "/")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var status int
+	var resp interface{}
+	switch path {
+	case "sys/v1/session/auth":
+		status, resp = m.auth(r)
+	case "sys/v1/session/select_account":
+		status, resp = m.selectAccount(r, raw)
+	case "sys/v1/session/terminate":
+		delete(m.sessions, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		status = http.StatusNoContent
+	default:
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		acct_id, ok := m.sessions[token]
+		if ok && m.session_lifetime > 0 {
+			m.session_calls[token]++
+			if m.session_calls[token] > m.session_lifetime {
+				delete(m.sessions, token)
+				ok = false
+			}
+		}
+		var in interface{}
+		switch {
+		case !ok:
+			m.unauthorized++
+			status, resp = mockError(http.StatusUnauthorized, "Invalid or expired session token")
+		case acct_id == "":
+			status, resp = mockError(http.StatusForbidden, "No account selected for this session")
+		case len(raw) > 0 && json.Unmarshal(raw, &in) != nil:
+			status, resp = mockError(http.StatusBadRequest, "Request body is not valid JSON")
+		case m.guarded(r.Method, path, in):
+			status, resp = mockError(http.StatusForbidden, mock_quorum_message)
+		default:
+			status, resp = m.route(r.Method, path, r.URL.Query(), in)
+		}
+	}
+
+	w.Header().Set("Request-Id", mockUUID())
+	switch body := resp.(type) {
+	case nil:
+		if status == 0 || status == http.StatusOK {
+			status = http.StatusNoContent
+		}
+		w.WriteHeader(status)
+	case mockMessage:
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		io.WriteString(w, string(body))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+// [-]: POST sys/v1/session/auth with the username and password of the administrator
+func (m *mockDSM) auth(r *http.Request) (int, interface{}) {
+	if r.Method != "POST" {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok || username != mock_username || password != mock_password {
+		return mockError(http.StatusUnauthorized, "Invalid username or password")
+	}
+	token := base64.RawURLEncoding.EncodeToString([]byte(mockUUID()))
+	m.sessions[token] = ""
+	return http.StatusOK, map[string]interface{}{
+		"token_type":   "Bearer",
+		"expires_in":   600,
+		"access_token": token,
+		"entity_id":    m.user_id,
+	}
+}
+
+// [-]: POST sys/v1/session/select_account
+func (m *mockDSM) selectAccount(r *http.Request, raw []byte) (int, interface{}) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if _, ok := m.sessions[token]; !ok {
+		return mockError(http.StatusUnauthorized, "Invalid or expired session token")
+	}
+	var body struct {
+		Acct_id string `json:"acct_id"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return mockError(http.StatusBadRequest, "Request body is not valid JSON")
+	}
+	if body.Acct_id != m.acct_id {
+		return mockError(http.StatusNotFound, "Account %s does not exist", body.Acct_id)
+	}
+	m.sessions[token] = body.Acct_id
+	return http.StatusOK, map[string]interface{}{"cookie": token}
+}
+
+// expireSessions invalidates every session token, the next call must
+// authenticate again.
+func (m *mockDSM) expireSessions() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions = map[string]string{}
+}
+
+// [-]: true if the account or the group of the object has an approval policy
+// that requires quorum approval for this operation
+func (m *mockDSM) guarded(method string, path string, in interface{}) bool {
+	parts := mockSplit(path)
+	body, _ := in.(map[string]interface{})
+	if len(parts) < 4 {
+		if len(parts) == 3 && parts[2] == "keys" && (method == "POST" || method == "PUT") {
+			return m.quorumGroup(body["group_id"])
+		}
+		return false
+	}
+	switch {
+	case parts[2] == "accounts" && method == "PATCH":
+		return m.hasQuorum(m.objects["accounts"].get(parts[3]))
+	case parts[2] == "groups" && method == "PATCH":
+		return m.hasQuorum(m.objects["groups"].get(parts[3]))
+	case parts[2] == "keys" && parts[3] == "export":
+		if key := m.keyByRef(body); key != nil {
+			return m.quorumGroup(key["group_id"])
+		}
+	case parts[2] == "keys" && parts[3] == "copy":
+		return m.quorumGroup(body["group_id"])
+	case parts[2] == "keys" && (method == "PATCH" && len(parts) == 4 || len(parts) == 5 && parts[4] == "schedule_deletion"):
+		if key := m.objects["keys"].get(parts[3]); key != nil {
+			return m.quorumGroup(key["group_id"])
+		}
+	}
+	return false
+}
+
+func (m *mockDSM) quorumGroup(group_id interface{}) bool {
+	id, _ := group_id.(string)
+	return m.hasQuorum(m.objects["groups"].get(id))
+}
+
+func (m *mockDSM) hasQuorum(obj map[string]interface{}) bool {
+	if obj == nil {
+		return false
+	}
+	policy, ok := obj["approval_policy"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	// the quorum of a group may also be at the top of its policy
+	if quorum, ok := policy["quorum"].(map[string]interface{}); ok && len(quorum) > 0 {
+		return true
+	}
+	quorum, ok := policy["policy"].(map[string]interface{})
+	return ok && len(quorum) > 0
+}
+
+// [-]: path segments without the empty ones
+func mockSplit(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// [-]: dispatch an authenticated call
+func (m *mockDSM) route(method string, path string, query url.Values, in interface{}) (int, interface{}) {
+	parts := mockSplit(path)
+	body, _ := in.(map[string]interface{})
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	if len(parts) < 3 {
+		return mockError(http.StatusNotFound, "No route for %s %s", method, path)
+	}
+	api, rest := strings.Join(parts[:3], "/"), parts[3:]
+	switch api {
+	case "crypto/v1/keys":
+		return m.keys(method, rest, query, body)
+	case "crypto/v1/unwrapkey":
+		if method == "POST" && len(rest) == 0 {
+			return m.unwrapKey(body)
+		}
+	case "crypto/v1/wrapkey":
+		if method == "POST" && len(rest) == 0 {
+			return m.wrapKey(body)
+		}
+	case "sys/v1/groups":
+		return m.groups(method, rest, query, body)
+	case "sys/v1/apps":
+		return m.apps(method, rest, query, body)
+	case "sys/v1/users":
+		return m.users(method, rest, query, body)
+	case "sys/v1/plugins":
+		return m.plugins(method, rest, query, in)
+	case "sys/v1/accounts":
+		return m.accounts(method, rest, body)
+	case "sys/v1/approval_requests":
+		return m.approvals(method, rest, query, body)
+	case "sys/v1/roles":
+		if method == "GET" && len(rest) == 0 {
+			return m.list(m.objects["roles"], query, nil)
+		}
+	case "sys/v1/version":
+		if method == "GET" && len(rest) == 0 {
+			return http.StatusOK, map[string]interface{}{
+				"version":     "4.31.2100",
+				"api_version": "1.0",
+				"server_mode": "Software",
+				"fips_level":  nil,
+			}
+		}
+	}
+	return mockError(http.StatusNotFound, "No route for %s %s", method, path)
+}
+
+// [-]: list a collection, filtered by match and paginated with limit/offset
+func (m *mockDSM) list(c *mockCollection, query url.Values, match func(map[string]interface{}) bool) (int, interface{}) {
+	items := []interface{}{}
+	for _, obj := range c.all() {
+		if match == nil || match(obj) {
+			items = append(items, m.view(c, obj))
+		}
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		if offset > len(items) {
+			offset = len(items)
+		}
+		items = items[offset:]
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return http.StatusOK, items
+}
+
+// [-]: response copy of a stored object, secrets removed
+func (m *mockDSM) view(c *mockCollection, obj map[string]interface{}) map[string]interface{} {
+	out := mockClone(obj).(map[string]interface{})
+	switch c.id_field {
+	case "app_id":
+		delete(out, "credential")
+	case "group_id":
+		if hmg, ok := out["hmg"].(map[string]interface{}); ok {
+			for _, config := range hmg {
+				if config, ok := config.(map[string]interface{}); ok {
+					delete(config, "secret_key")
+				}
+			}
+		}
+	}
+	return out
+}
+
+// [-]: copy the listed fields from a request body, nil removes the field
+func mockMerge(obj map[string]interface{}, body map[string]interface{}, fields ...string) {
+	for _, field := range fields {
+		if value, ok := body[field]; ok {
+			if value == nil {
+				delete(obj, field)
+			} else {
+				obj[field] = mockClone(value)
+			}
+		}
+	}
+}
+
+func mockStrings(in interface{}) []string {
+	var out []string
+	if list, ok := in.([]interface{}); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func mockContains(list interface{}, value string) bool {
+	for _, v := range mockStrings(list) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------------------------------------------------------
+// crypto/v1/keys
+
+var mock_default_key_ops = map[string][]string{
+	"AES":    {"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE"},
+	"DES3":   {"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE"},
+	"ARIA":   {"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE"},
+	"SEED":   {"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE"},
+	"HMAC":   {"MACGENERATE", "MACVERIFY", "APPMANAGEABLE"},
+	"RSA":    {"SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+	"EC":     {"SIGN", "VERIFY", "AGREEKEY", "APPMANAGEABLE"},
+	"SECRET": {"DERIVEKEY", "EXPORT", "APPMANAGEABLE"},
+}
+
+// request fields stored as sent on create and update
+var mock_key_fields = []string{"description", "key_ops", "enabled", "custom_metadata", "deactivation_date",
+	"activation_date", "rotation_policy", "google_access_reason_policy", "fpe", "rsa", "dsa", "kcdsa", "eckcdsa",
+	"bls", "lms", "xmss", "elliptic_curve", "subgroup_size", "key_size", "expirationDate"}
+
+func (m *mockDSM) keys(method string, rest []string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	keys := m.objects["keys"]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(keys, query, func(key map[string]interface{}) bool {
+			if name := query.Get("name"); name != "" && key["name"] != name {
+				return false
+			}
+			if group_id := query.Get("group_id"); group_id != "" && key["group_id"] != group_id {
+				return false
+			}
+			return key["state"] != "Destroyed" || query.Get("show_destroyed") == "true"
+		})
+	case len(rest) == 0 && method == "POST":
+		return m.createKey(body, nil)
+	case len(rest) == 0 && method == "PUT":
+		value, _ := body["value"].(string)
+		material, err := base64.StdEncoding.DecodeString(value)
+		if value == "" || err != nil {
+			return mockError(http.StatusBadRequest, "value must be base64 encoded key material")
+		}
+		return m.createKey(body, material)
+	case len(rest) == 1 && method == "POST":
+		switch rest[0] {
+		case "export", "info":
+			key := m.keyByRef(body)
+			if key == nil {
+				return mockError(http.StatusNotFound, "Sobject does not exist")
+			}
+			out := m.view(keys, key)
+			if rest[0] == "export" {
+				if !mockContains(key["key_ops"], "EXPORT") {
+					return mockError(http.StatusForbidden, "Security object is not exportable")
+				}
+				out["value"] = base64.StdEncoding.EncodeToString(m.values[key["kid"].(string)])
+			}
+			return http.StatusOK, out
+		case "rekey":
+			return m.rekey(body)
+		case "copy":
+			return m.copyKey(body)
+		}
+	case len(rest) >= 1:
+		key := keys.get(rest[0])
+		if key == nil || (key["state"] == "Destroyed" && method == "GET" && query.Get("show_destroyed") != "true") {
+			return mockError(http.StatusNotFound, "Sobject does not exist")
+		}
+		if len(rest) == 2 && method == "POST" {
+			return m.keyAction(key, rest[1], body)
+		}
+		if len(rest) > 1 {
+			break
+		}
+		switch method {
+		case "GET":
+			return http.StatusOK, m.view(keys, key)
+		case "PATCH":
+			return m.updateKey(key, body)
+		case "DELETE":
+			if m.externalKind(key) != "" && key["state"] != "Destroyed" {
+				return mockError(http.StatusBadRequest, "BYOK security object must be destroyed before it is deleted")
+			}
+			kid := key["kid"].(string)
+			keys.remove(kid)
+			delete(m.values, kid)
+			delete(m.signers, kid)
+			return http.StatusNoContent, nil
+		}
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// [-]: POST crypto/v1/wrapkey, exports the subject key wrapped by another key
+func (m *mockDSM) wrapKey(body map[string]interface{}) (int, interface{}) {
+	ref, _ := body["key"].(map[string]interface{})
+	wrapping := m.keyByRef(ref)
+	subject_ref, _ := body["subject"].(map[string]interface{})
+	subject := m.keyByRef(subject_ref)
+	if wrapping == nil || subject == nil {
+		return mockError(http.StatusNotFound, "Sobject does not exist")
+	}
+	if !mockContains(wrapping["key_ops"], "WRAPKEY") {
+		return mockError(http.StatusBadRequest, "Operation not permitted: security object does not have WRAPKEY permission")
+	}
+	if !mockContains(subject["key_ops"], "EXPORT") {
+		return mockError(http.StatusBadRequest, "Operation not permitted: security object is not exportable")
+	}
+	if body["alg"] != wrapping["obj_type"] {
+		return mockError(http.StatusBadRequest, "alg %v does not match the %v wrapping key", body["alg"], wrapping["obj_type"])
+	}
+	material := m.values[subject["kid"].(string)]
+	kid := wrapping["kid"].(string)
+	var wrapped []byte
+	var err error
+	switch mode := body["mode"].(type) {
+	case string:
+		if body["alg"] != "AES" || (mode != "KW" && mode != "KWP") {
+			return mockError(http.StatusBadRequest, "Unsupported mode %v for %v", mode, body["alg"])
+		}
+		wrapped, err = mockKeyWrap(m.values[kid], material, mode == "KWP")
+	case map[string]interface{}:
+		h := mockOAEPHash(mode)
+		signer, ok := m.signers[kid]
+		if body["alg"] != "RSA" || h == nil || !ok {
+			return mockError(http.StatusBadRequest, "Unsupported mode %v for %v", mode, body["alg"])
+		}
+		wrapped, err = rsa.EncryptOAEP(h, rand.Reader, signer.Public().(*rsa.PublicKey), material, nil)
+	default:
+		return mockError(http.StatusBadRequest, "mode is required")
+	}
+	if err != nil {
+		return mockError(http.StatusBadRequest, "Failed to wrap key: %v", err)
+	}
+	return http.StatusOK, map[string]interface{}{"wrapped_key": base64.StdEncoding.EncodeToString(wrapped)}
+}
+
+// [-]: hash of an RSA OAEP mode, nil if unsupported
+func mockOAEPHash(mode map[string]interface{}) hash.Hash {
+	oaep, _ := mode["OAEP"].(map[string]interface{})
+	mgf, _ := oaep["mgf"].(map[string]interface{})
+	mgf1, _ := mgf["mgf1"].(map[string]interface{})
+	switch mgf1["hash"] {
+	case "SHA1":
+		return sha1.New()
+	case "SHA256":
+		return sha256.New()
+	}
+	return nil
+}
+
+// [-]: POST crypto/v1/unwrapkey, imports key material wrapped by a key of the account
+func (m *mockDSM) unwrapKey(body map[string]interface{}) (int, interface{}) {
+	ref, _ := body["key"].(map[string]interface{})
+	wrapping := m.keyByRef(ref)
+	if wrapping == nil {
+		return mockError(http.StatusNotFound, "Unwrapping key does not exist")
+	}
+	if !mockContains(wrapping["key_ops"], "UNWRAPKEY") {
+		return mockError(http.StatusBadRequest, "Operation not permitted: security object does not have UNWRAPKEY permission")
+	}
+	if body["alg"] != wrapping["obj_type"] {
+		return mockError(http.StatusBadRequest, "alg %v does not match the %v unwrapping key", body["alg"], wrapping["obj_type"])
+	}
+	wrapped_key, _ := body["wrapped_key"].(string)
+	wrapped, err := base64.StdEncoding.DecodeString(wrapped_key)
+	if wrapped_key == "" || err != nil {
+		return mockError(http.StatusBadRequest, "wrapped_key must be base64 encoded")
+	}
+	kid := wrapping["kid"].(string)
+	var material []byte
+	switch mode := body["mode"].(type) {
+	case string:
+		if body["alg"] != "AES" || (mode != "KW" && mode != "KWP") {
+			return mockError(http.StatusBadRequest, "Unsupported mode %v for %v", mode, body["alg"])
+		}
+		material, err = mockKeyUnwrap(m.values[kid], wrapped, mode == "KWP")
+	case map[string]interface{}:
+		h := mockOAEPHash(mode)
+		private, ok := m.signers[kid].(*rsa.PrivateKey)
+		if body["alg"] != "RSA" || h == nil || !ok {
+			return mockError(http.StatusBadRequest, "Unsupported mode %v for %v", mode, body["alg"])
+		}
+		material, err = rsa.DecryptOAEP(h, rand.Reader, private, wrapped, nil)
+	default:
+		return mockError(http.StatusBadRequest, "mode is required")
+	}
+	if err != nil {
+		return mockError(http.StatusBadRequest, "Failed to unwrap key: %v", err)
+	}
+	for _, field := range []string{"key", "alg", "mode", "wrapped_key"} {
+		delete(body, field)
+	}
+	return m.createKey(body, material)
+}
+
+// mockKeyWrap wraps key material with AES key wrap (RFC 3394), or key wrap
+// with padding (RFC 5649).
+func mockKeyWrap(kek []byte, plain []byte, pad bool) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	iv := []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+	if pad {
+		iv = []byte{0xA6, 0x59, 0x59, 0xA6, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(iv[4:], uint32(len(plain)))
+		plain = append(append([]byte{}, plain...), make([]byte, (8-len(plain)%8)%8)...)
+		if len(plain) == 8 {
+			out := make([]byte, 16)
+			block.Encrypt(out, append(iv, plain...))
+			return out, nil
+		}
+	}
+	if len(plain)%8 != 0 || len(plain) < 16 {
+		return nil, fmt.Errorf("invalid key length %d", len(plain))
+	}
+	n := len(plain) / 8
+	a := append([]byte{}, iv...)
+	r := append([]byte{}, plain...)
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			block.Encrypt(b, append(append([]byte{}, a...), r[8*i:8*i+8]...))
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b[:8])^uint64(n*j+i+1))
+			copy(r[8*i:], b[8:])
+		}
+	}
+	return append(a, r...), nil
+}
+
+// mockKeyUnwrap reverses mockKeyWrap and checks the integrity of the result.
+func mockKeyUnwrap(kek []byte, wrapped []byte, pad bool) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped)%8 != 0 || len(wrapped) < 16 {
+		return nil, fmt.Errorf("invalid wrapped key length %d", len(wrapped))
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	r := make([]byte, 8*n)
+	b := make([]byte, 16)
+	if n == 1 {
+		block.Decrypt(b, wrapped)
+		copy(a, b[:8])
+		copy(r, b[8:])
+	} else {
+		copy(a, wrapped[:8])
+		copy(r, wrapped[8:])
+		for j := 5; j >= 0; j-- {
+			for i := n - 1; i >= 0; i-- {
+				binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(a)^uint64(n*j+i+1))
+				block.Decrypt(b, append(append([]byte{}, a...), r[8*i:8*i+8]...))
+				copy(a, b[:8])
+				copy(r[8*i:], b[8:])
+			}
+		}
+	}
+	if !pad {
+		if n == 1 || binary.BigEndian.Uint64(a) != 0xA6A6A6A6A6A6A6A6 {
+			return nil, fmt.Errorf("integrity check failed")
+		}
+		return r, nil
+	}
+	size := int(binary.BigEndian.Uint32(a[4:]))
+	if binary.BigEndian.Uint32(a[:4]) != 0xA65959A6 || size > len(r) || size <= len(r)-8 {
+		return nil, fmt.Errorf("integrity check failed")
+	}
+	return r[:size], nil
+}
+
+// [-]: key referenced by kid or name in a request body
+func (m *mockDSM) keyByRef(body map[string]interface{}) map[string]interface{} {
+	if kid, ok := body["kid"].(string); ok {
+		return m.objects["keys"].get(kid)
+	}
+	if name, ok := body["name"].(string); ok {
+		return m.keyByName(name)
+	}
+	return nil
+}
+
+func (m *mockDSM) keyByName(name string) map[string]interface{} {
+	return m.objects["keys"].find(func(key map[string]interface{}) bool {
+		return key["name"] == name
+	})
+}
+
+// [-]: generate key material of the stored type and size
+func (m *mockDSM) generate(key map[string]interface{}) (int, interface{}) {
+	kid := key["kid"].(string)
+	size := 0
+	if key_size, ok := key["key_size"].(float64); ok {
+		size = int(key_size)
+	}
+	switch key["obj_type"] {
+	case "AES", "ARIA", "SEED", "HMAC", "DES3", "SECRET":
+		if key["obj_type"] == "AES" && size != 128 && size != 192 && size != 256 {
+			return mockError(http.StatusBadRequest, "Invalid key size %d for AES", size)
+		}
+		if size <= 0 {
+			return mockError(http.StatusBadRequest, "key_size is required for %s", key["obj_type"])
+		}
+		m.values[kid] = make([]byte, size/8)
+		rand.Read(m.values[kid])
+	case "RSA":
+		if size < 1024 || size > 8192 {
+			return mockError(http.StatusBadRequest, "Invalid key size %d for RSA", size)
+		}
+		// the size only has to be plausible, a smaller key keeps the tests fast
+		private, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			return mockError(http.StatusInternalServerError, "%v", err)
+		}
+		m.setSigner(key, private, private.Public())
+	case "EC":
+		var signer crypto.Signer
+		switch key["elliptic_curve"] {
+		case "NistP256":
+			signer, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		case "NistP384":
+			signer, _ = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		case "NistP521":
+			signer, _ = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		case "Ed25519":
+			_, signer, _ = ed25519.GenerateKey(rand.Reader)
+		default:
+			return mockError(http.StatusBadRequest, "Unsupported elliptic curve %v", key["elliptic_curve"])
+		}
+		m.setSigner(key, signer, signer.Public())
+	default:
+		// other types are accepted, their material stays inside the HSM
+	}
+	return http.StatusOK, nil
+}
+
+func (m *mockDSM) setSigner(key map[string]interface{}, signer crypto.Signer, public crypto.PublicKey) {
+	kid := key["kid"].(string)
+	der, _ := x509.MarshalPKIXPublicKey(public)
+	key["pub_key"] = base64.StdEncoding.EncodeToString(der)
+	private, _ := x509.MarshalPKCS8PrivateKey(signer)
+	m.values[kid] = private
+	m.signers[kid] = signer
+}
+
+// [-]: POST or PUT crypto/v1/keys, material is set for an import
+func (m *mockDSM) createKey(body map[string]interface{}, material []byte) (int, interface{}) {
+	name, _ := body["name"].(string)
+	if name == "" {
+		return mockError(http.StatusBadRequest, "name is required")
+	}
+	if m.keyByName(name) != nil {
+		return mockError(http.StatusConflict, "Sobject with name %q already exists", name)
+	}
+	group_id, _ := body["group_id"].(string)
+	if group_id == "" {
+		group_id = m.defaultGroup()
+	}
+	if m.objects["groups"].get(group_id) == nil {
+		return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+	}
+	obj_type, _ := body["obj_type"].(string)
+	if obj_type == "" {
+		return mockError(http.StatusBadRequest, "obj_type is required")
+	}
+	key := m.newKey(name, group_id, obj_type)
+	mockMerge(key, body, mock_key_fields...)
+	m.normalizeKey(key)
+	if material != nil {
+		key["origin"] = "External"
+		key["key_size"] = float64(len(material) * 8)
+		m.values[key["kid"].(string)] = material
+	} else if status, resp := m.generate(key); status != http.StatusOK {
+		return status, resp
+	}
+	m.objects["keys"].put(key)
+	return http.StatusCreated, m.view(m.objects["keys"], key)
+}
+
+func (m *mockDSM) newKey(name string, group_id string, obj_type string) map[string]interface{} {
+	key_ops := []interface{}{"APPMANAGEABLE"}
+	if ops, ok := mock_default_key_ops[obj_type]; ok {
+		key_ops = mockClone(ops).([]interface{})
+	}
+	now := mockTime(time.Now())
+	return map[string]interface{}{
+		"kid":              mockUUID(),
+		"name":             name,
+		"group_id":         group_id,
+		"acct_id":          m.acct_id,
+		"creator":          map[string]interface{}{"user": m.user_id},
+		"obj_type":         obj_type,
+		"key_ops":          key_ops,
+		"enabled":          true,
+		"state":            "Active",
+		"origin":           "FortanixHSM",
+		"created_at":       now,
+		"lastused_at":      "19700101T000000Z",
+		"activation_date":  now,
+		"never_exportable": false,
+		"links":            map[string]interface{}{},
+		"custom_metadata":  map[string]interface{}{},
+	}
+}
+
+// [-]: drop empty optional fields the way DSM omits them
+func (m *mockDSM) normalizeKey(key map[string]interface{}) {
+	if description, ok := key["description"].(string); ok && description == "" {
+		delete(key, "description")
+	}
+	if _, ok := key["custom_metadata"].(map[string]interface{}); !ok {
+		key["custom_metadata"] = map[string]interface{}{}
+	}
+	if _, ok := key["key_ops"].([]interface{}); !ok {
+		delete(key, "key_ops")
+	}
+	// DSM fills in the options that are not given
+	if rsa, ok := key["rsa"].(map[string]interface{}); ok {
+		mockDefault(rsa, "key_size", key["key_size"])
+		mockDefault(rsa, "public_exponent", float64(65537))
+		mockDefault(rsa, "encryption_policy", []interface{}{map[string]interface{}{"padding": nil}})
+		mockDefault(rsa, "signature_policy", []interface{}{map[string]interface{}{"padding": nil}})
+	}
+	if fpe, ok := key["fpe"].(map[string]interface{}); ok {
+		mockDefault(fpe, "name", key["name"])
+	}
+}
+
+// [-]: set a field of a stored object when it is missing
+func mockDefault(obj map[string]interface{}, field string, value interface{}) {
+	if _, ok := obj[field]; !ok {
+		obj[field] = value
+	}
+}
+
+// [-]: group used when a request has no group_id: the first one
+func (m *mockDSM) defaultGroup() string {
+	if groups := m.objects["groups"].all(); len(groups) > 0 {
+		return groups[0]["group_id"].(string)
+	}
+	return ""
+}
+
+// [-]: PATCH crypto/v1/keys/{kid}
+func (m *mockDSM) updateKey(key map[string]interface{}, body map[string]interface{}) (int, interface{}) {
+	if key["state"] == "Destroyed" {
+		return mockError(http.StatusBadRequest, "Security object is destroyed and can not be modified")
+	}
+	if name, ok := body["name"].(string); ok && name != key["name"] {
+		if m.keyByName(name) != nil {
+			return mockError(http.StatusConflict, "Sobject with name %q already exists", name)
+		}
+		key["name"] = name
+	}
+	if group_id, ok := body["group_id"].(string); ok && group_id != "" {
+		if m.objects["groups"].get(group_id) == nil {
+			return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+		}
+		key["group_id"] = group_id
+	}
+	if _, ok := body["deactivation_date"]; ok && key["deactivation_date"] != nil && body["deactivation_date"] != key["deactivation_date"] {
+		return mockError(http.StatusBadRequest, "deactivation_date is already set")
+	}
+	mockMerge(key, body, "description", "key_ops", "enabled", "custom_metadata", "deactivation_date",
+		"rotation_policy", "google_access_reason_policy", "rsa", "fpe")
+	m.normalizeKey(key)
+	return http.StatusOK, m.view(m.objects["keys"], key)
+}
+
+// [-]: POST crypto/v1/keys/rekey, body name is the key to rotate
+func (m *mockDSM) rekey(body map[string]interface{}) (int, interface{}) {
+	name, _ := body["name"].(string)
+	old := m.keyByName(name)
+	if old == nil {
+		return mockError(http.StatusNotFound, "Sobject does not exist")
+	}
+	return m.rotate(old, body)
+}
+
+// [-]: rotate a key, the new key takes the body name or else the name of the old key
+func (m *mockDSM) rotate(old map[string]interface{}, body map[string]interface{}) (int, interface{}) {
+	name, _ := body["name"].(string)
+	if name == "" {
+		name = old["name"].(string)
+	}
+	key := mockClone(old).(map[string]interface{})
+	key["kid"] = mockUUID()
+	key["name"] = name
+	key["created_at"] = mockTime(time.Now())
+	key["state"] = "Active"
+	delete(key, "pub_key")
+	// a BYOK key is rotated by a new key of the external KMS
+	if external, ok := key["external"].(map[string]interface{}); ok {
+		id := external["id"].(map[string]interface{})
+		switch m.externalKind(key) {
+		case "AWSKMS":
+			id["key_id"] = mockUUID()
+			id["key_arn"] = fmt.Sprintf("arn:aws:kms:us-east-1:000000000000:key/%s", id["key_id"])
+		case "AZUREKEYVAULT":
+			id["version"] = strings.ReplaceAll(mockUUID(), "-", "")
+		}
+	}
+	mockMerge(key, body, mock_key_fields...)
+	m.normalizeKey(key)
+	if status, resp := m.generate(key); status != http.StatusOK {
+		return status, resp
+	}
+	if value, ok := body["value"].(string); ok {
+		m.values[key["kid"].(string)], _ = base64.StdEncoding.DecodeString(value)
+	}
+	key["links"] = map[string]interface{}{"replaced": old["kid"]}
+	old_links, _ := old["links"].(map[string]interface{})
+	if old_links == nil {
+		old_links = map[string]interface{}{}
+	}
+	old_links["replacement"] = key["kid"]
+	old["links"] = old_links
+	// DSM keeps the name for the new key and renames the rotated one
+	old["name"] = fmt.Sprintf("%s_%s", old["name"], old["kid"])
+	m.objects["keys"].put(key)
+	return http.StatusCreated, m.view(m.objects["keys"], key)
+}
+
+// [-]: POST crypto/v1/keys/copy, a copy into a group with an external KMS is a BYOK key
+func (m *mockDSM) copyKey(body map[string]interface{}) (int, interface{}) {
+	ref, _ := body["key"].(map[string]interface{})
+	source := m.keyByRef(ref)
+	if source == nil {
+		return mockError(http.StatusNotFound, "Sobject does not exist")
+	}
+	name, _ := body["name"].(string)
+	if name == "" {
+		return mockError(http.StatusBadRequest, "name is required")
+	}
+	if m.keyByName(name) != nil {
+		return mockError(http.StatusConflict, "Sobject with name %q already exists", name)
+	}
+	group_id, _ := body["group_id"].(string)
+	group := m.objects["groups"].get(group_id)
+	if group == nil {
+		return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+	}
+	key := m.newKey(name, group_id, source["obj_type"].(string))
+	mockMerge(key, source, "key_size", "elliptic_curve", "key_ops", "pub_key")
+	mockMerge(key, body, mock_key_fields...)
+	m.normalizeKey(key)
+	kid := key["kid"].(string)
+	m.values[kid] = m.values[source["kid"].(string)]
+	if signer, ok := m.signers[source["kid"].(string)]; ok {
+		m.signers[kid] = signer
+	}
+	key["links"] = map[string]interface{}{"copiedFrom": source["kid"]}
+	source_links, _ := source["links"].(map[string]interface{})
+	if source_links == nil {
+		source_links = map[string]interface{}{}
+	}
+	source_links["copiedTo"] = append(mockStrings(source_links["copiedTo"]), kid)
+	source["links"] = source_links
+
+	hmg, _ := group["hmg"].(map[string]interface{})
+	for hmg_id, config := range hmg {
+		config, _ := config.(map[string]interface{})
+		metadata := key["custom_metadata"].(map[string]interface{})
+		switch config["kind"] {
+		case "AWSKMS":
+			key_id := mockUUID()
+			key["external"] = map[string]interface{}{
+				"hsm_group_id": hmg_id,
+				"id": map[string]interface{}{
+					"key_arn": fmt.Sprintf("arn:aws:kms:us-east-1:000000000000:key/%s", key_id),
+					"key_id":  key_id,
+				},
+			}
+			metadata["aws-key-state"] = "Enabled"
+			if _, ok := metadata["aws-aliases"]; !ok {
+				metadata["aws-aliases"] = name
+			}
+			if _, ok := metadata["aws-policy"]; !ok {
+				metadata["aws-policy"] = `{"Version":"2012-10-17","Id":"key-default-1","Statement":[{"Sid":"Enable IAM User Permissions","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::000000000000:root"},"Action":"kms:*","Resource":"*"}]}`
+			}
+		case "AZUREKEYVAULT":
+			key["external"] = map[string]interface{}{
+				"hsm_group_id": hmg_id,
+				"id": map[string]interface{}{
+					"version": strings.ReplaceAll(mockUUID(), "-", ""),
+					"label":   name,
+				},
+			}
+			metadata["azure-key-state"] = "enabled"
+			if _, ok := metadata["azure-key-name"]; !ok {
+				metadata["azure-key-name"] = name
+			}
+		case "GCPKEYRING":
+			if _, ok := metadata["gcp-key-id"]; !ok {
+				metadata["gcp-key-id"] = name
+			}
+			key["external"] = map[string]interface{}{
+				"hsm_group_id": hmg_id,
+				"id": map[string]interface{}{
+					"key_id":  metadata["gcp-key-id"],
+					"version": 1,
+				},
+			}
+		}
+	}
+	m.objects["keys"].put(key)
+	return http.StatusCreated, m.view(m.objects["keys"], key)
+}
+
+// [-]: kind of the external KMS holding a BYOK key, "" for a DSM key
+func (m *mockDSM) externalKind(key map[string]interface{}) string {
+	external, ok := key["external"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	group := m.objects["groups"].get(key["group_id"].(string))
+	if group == nil {
+		return ""
+	}
+	hmg, _ := group["hmg"].(map[string]interface{})
+	config, _ := hmg[fmt.Sprint(external["hsm_group_id"])].(map[string]interface{})
+	kind, _ := config["kind"].(string)
+	return kind
+}
+
+// [-]: POST crypto/v1/keys/{kid}/<action>
+func (m *mockDSM) keyAction(key map[string]interface{}, action string, body map[string]interface{}) (int, interface{}) {
+	metadata, _ := key["custom_metadata"].(map[string]interface{})
+	switch action {
+	case "revoke":
+		code, _ := body["code"].(string)
+		switch code {
+		case "KeyCompromise", "CACompromise":
+			key["state"] = "Compromised"
+			key["compromise_date"] = mockTime(time.Now())
+		case "Unspecified", "AffiliationChanged", "Superseded", "CessationOfOperation", "PrivilegeWithdrawn":
+			key["state"] = "Deactivated"
+		default:
+			return mockError(http.StatusBadRequest, "Invalid revocation reason %q", code)
+		}
+		key["deactivation_date"] = mockTime(time.Now())
+		key["revocation_reason"] = mockClone(body)
+		return http.StatusNoContent, nil
+	case "destroy":
+		if key["state"] != "Deactivated" && key["state"] != "Compromised" {
+			return mockError(http.StatusBadRequest, "Security object must be deactivated or compromised before it is destroyed")
+		}
+		key["state"] = "Destroyed"
+		key["destruction_date"] = mockTime(time.Now())
+		delete(m.values, key["kid"].(string))
+		delete(m.signers, key["kid"].(string))
+		return http.StatusNoContent, nil
+	case "rekey":
+		return m.rotate(key, body)
+	case "schedule_deletion":
+		switch m.externalKind(key) {
+		case "AWSKMS":
+			days, _ := body["pending_window_in_days"].(float64)
+			if days < 7 || days > 30 {
+				return mockError(http.StatusBadRequest, "pending_window_in_days must be between 7 and 30")
+			}
+			metadata["aws-key-state"] = "PendingDeletion"
+			metadata["aws-deletion-date"] = mockTime(time.Now().AddDate(0, 0, int(days)))
+		case "AZUREKEYVAULT":
+			metadata["azure-key-state"] = "deleted"
+		default:
+			return mockError(http.StatusBadRequest, "Security object is not a BYOK key")
+		}
+		key["state"] = "Deactivated"
+		return http.StatusNoContent, nil
+	case "delete_key_material":
+		switch m.externalKind(key) {
+		case "AWSKMS":
+			metadata["aws-key-state"] = "PendingImport"
+		case "AZUREKEYVAULT":
+			if metadata["azure-key-state"] != "deleted" {
+				return mockError(http.StatusBadRequest, "Key must be soft deleted before it is purged")
+			}
+			metadata["azure-key-state"] = "purged"
+			key["state"] = "Destroyed"
+		default:
+			return mockError(http.StatusBadRequest, "Security object is not a BYOK key")
+		}
+		return http.StatusNoContent, nil
+	}
+	return mockError(http.StatusNotFound, "No route for POST crypto/v1/keys/%s/%s", key["kid"], action)
+}
+
+// elapsePendingDeletion ends the pending window of a BYOK key scheduled for
+// deletion: the cloud key is gone and DSM marks the copy destroyed.
+func (m *mockDSM) elapsePendingDeletion(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key := m.objects["keys"].get(kid); key != nil {
+		key["state"] = "Destroyed"
+		if metadata, ok := key["custom_metadata"].(map[string]interface{}); ok && metadata["aws-key-state"] != nil {
+			metadata["aws-key-state"] = "Deleted"
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/groups
+
+func (m *mockDSM) groupByName(name string) map[string]interface{} {
+	return m.objects["groups"].find(func(group map[string]interface{}) bool {
+		return group["name"] == name
+	})
+}
+
+func (m *mockDSM) groups(method string, rest []string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	groups := m.objects["groups"]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(groups, query, func(group map[string]interface{}) bool {
+			return query.Get("name") == "" || group["name"] == query.Get("name")
+		})
+	case len(rest) == 0 && method == "POST":
+		name, _ := body["name"].(string)
+		if name == "" {
+			return mockError(http.StatusBadRequest, "name is required")
+		}
+		if m.groupByName(name) != nil {
+			return mockError(http.StatusConflict, "Group with name %q already exists", name)
+		}
+		group := map[string]interface{}{
+			"group_id":   mockUUID(),
+			"name":       name,
+			"acct_id":    m.acct_id,
+			"creator":    map[string]interface{}{"user": m.user_id},
+			"created_at": mockTime(time.Now()),
+		}
+		mockMerge(group, body, "description", "approval_policy", "cryptographic_policy", "key_history_policy",
+			"hmg_redundancy", "key_metadata_policy")
+		if status, resp := m.updateHmg(group, body); status != http.StatusOK {
+			return status, resp
+		}
+		m.normalizeGroup(group)
+		groups.put(group)
+		// the creator administers the new group
+		if user := m.objects["users"].get(m.user_id); user != nil {
+			user["groups"].(map[string]interface{})[group["group_id"].(string)] = []interface{}{"GROUPADMINISTRATOR"}
+		}
+		return http.StatusCreated, m.view(groups, group)
+	}
+	if len(rest) == 0 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	group := groups.get(rest[0])
+	if group == nil {
+		return mockError(http.StatusNotFound, "Group does not exist")
+	}
+	switch {
+	case len(rest) == 1 && method == "GET":
+		return http.StatusOK, m.view(groups, group)
+	case len(rest) == 1 && method == "PATCH":
+		if name, ok := body["name"].(string); ok && name != group["name"] {
+			if m.groupByName(name) != nil {
+				return mockError(http.StatusConflict, "Group with name %q already exists", name)
+			}
+			group["name"] = name
+		}
+		mockMerge(group, body, "description", "approval_policy", "cryptographic_policy", "key_history_policy",
+			"hmg_redundancy", "key_metadata_policy")
+		if status, resp := m.updateHmg(group, body); status != http.StatusOK {
+			return status, resp
+		}
+		m.normalizeGroup(group)
+		return http.StatusOK, m.view(groups, group)
+	case len(rest) == 1 && method == "DELETE":
+		for _, key := range m.objects["keys"].all() {
+			if key["group_id"] == group["group_id"] {
+				return mockError(http.StatusBadRequest, "Group is not empty")
+			}
+		}
+		groups.remove(rest[0])
+		return http.StatusNoContent, nil
+	case len(rest) == 3 && rest[1] == "hmg" && method == "POST" && (rest[2] == "check" || rest[2] == "scan"):
+		if hmg, _ := group["hmg"].(map[string]interface{}); len(hmg) == 0 {
+			return mockError(http.StatusBadRequest, "Group has no external HSM or KMS configured")
+		}
+		return http.StatusNoContent, nil
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// [-]: add_hmg, mod_hmg and del_hmg of a group request
+func (m *mockDSM) updateHmg(group map[string]interface{}, body map[string]interface{}) (int, interface{}) {
+	hmg, _ := group["hmg"].(map[string]interface{})
+	if hmg == nil {
+		hmg = map[string]interface{}{}
+	}
+	if add, ok := body["add_hmg"].([]interface{}); ok {
+		for _, config := range add {
+			config, ok := config.(map[string]interface{})
+			if !ok || config["kind"] == nil {
+				return mockError(http.StatusBadRequest, "hmg kind is required")
+			}
+			hmg[mockUUID()] = mockClone(config)
+		}
+	}
+	if mod, ok := body["mod_hmg"].(map[string]interface{}); ok {
+		for hmg_id, config := range mod {
+			current, ok := hmg[hmg_id].(map[string]interface{})
+			if !ok {
+				return mockError(http.StatusBadRequest, "hmg %s is not configured for this group", hmg_id)
+			}
+			if config, ok := config.(map[string]interface{}); ok {
+				for k, v := range config {
+					current[k] = mockClone(v)
+				}
+			}
+		}
+	}
+	for _, hmg_id := range mockStrings(body["del_hmg"]) {
+		delete(hmg, hmg_id)
+	}
+	group["hmg"] = hmg
+	return http.StatusOK, nil
+}
+
+// [-]: "remove" and empty policies delete a group policy
+func (m *mockDSM) normalizeGroup(group map[string]interface{}) {
+	for _, field := range []string{"cryptographic_policy", "key_history_policy", "approval_policy", "key_metadata_policy"} {
+		switch policy := group[field].(type) {
+		case string:
+			if policy == "remove" {
+				delete(group, field)
+			}
+		case map[string]interface{}:
+			if len(policy) == 0 {
+				delete(group, field)
+			}
+		}
+	}
+	if description, ok := group["description"].(string); ok && description == "" {
+		delete(group, "description")
+	}
+	if hmg, ok := group["hmg"].(map[string]interface{}); ok && len(hmg) == 0 {
+		delete(group, "hmg")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/apps
+
+func (m *mockDSM) apps(method string, rest []string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	apps := m.objects["apps"]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(apps, query, func(app map[string]interface{}) bool {
+			return query.Get("name") == "" || app["name"] == query.Get("name")
+		})
+	case len(rest) == 0 && method == "POST":
+		name, _ := body["name"].(string)
+		if name == "" {
+			return mockError(http.StatusBadRequest, "name is required")
+		}
+		if apps.find(func(app map[string]interface{}) bool { return app["name"] == name }) != nil {
+			return mockError(http.StatusConflict, "App with name %q already exists", name)
+		}
+		default_group, _ := body["default_group"].(string)
+		// administrative apps belong to the account, not to a group
+		if m.objects["groups"].get(default_group) == nil && (body["role"] != "admin" || default_group != "") {
+			return mockError(http.StatusBadRequest, "Group %s does not exist", default_group)
+		}
+		app := map[string]interface{}{
+			"app_id":        mockUUID(),
+			"name":          name,
+			"acct_id":       m.acct_id,
+			"creator":       map[string]interface{}{"user": m.user_id},
+			"default_group": default_group,
+			"groups":        map[string]interface{}{},
+			"enabled":       true,
+			"created_at":    mockTime(time.Now()),
+			"credential":    map[string]interface{}{"secret": mockUUID()},
+		}
+		mockMerge(app, body, "description", "app_type", "interface", "ip_address_policy", "oauth_config", "role")
+		if credential, ok := body["credential"].(map[string]interface{}); ok {
+			app["credential"] = mockAppCredential(credential)
+		}
+		if status, resp := m.appGroups(app, body); status != http.StatusOK {
+			return status, resp
+		}
+		apps.put(app)
+		return http.StatusCreated, m.view(apps, app)
+	}
+	if len(rest) == 0 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	app := apps.get(rest[0])
+	if app == nil {
+		return mockError(http.StatusNotFound, "App does not exist")
+	}
+	switch {
+	case len(rest) == 1 && method == "GET":
+		return http.StatusOK, m.view(apps, app)
+	case len(rest) == 1 && method == "PATCH":
+		if name, ok := body["name"].(string); ok && name != app["name"] {
+			if apps.find(func(other map[string]interface{}) bool { return other["name"] == name }) != nil {
+				return mockError(http.StatusConflict, "App with name %q already exists", name)
+			}
+			app["name"] = name
+		}
+		if default_group, ok := body["default_group"].(string); ok && default_group != "" {
+			if m.objects["groups"].get(default_group) == nil {
+				return mockError(http.StatusBadRequest, "Group %s does not exist", default_group)
+			}
+			app["default_group"] = default_group
+		}
+		mockMerge(app, body, "description", "enabled", "ip_address_policy")
+		if credential, ok := body["credential"].(map[string]interface{}); ok {
+			app["credential"] = mockAppCredential(credential)
+		}
+		if description, ok := app["description"].(string); ok && description == "" {
+			delete(app, "description")
+		}
+		if status, resp := m.appGroups(app, body); status != http.StatusOK {
+			return status, resp
+		}
+		return http.StatusOK, m.view(apps, app)
+	case len(rest) == 1 && method == "DELETE":
+		apps.remove(rest[0])
+		return http.StatusNoContent, nil
+	case len(rest) == 2 && rest[1] == "credential" && method == "GET":
+		return http.StatusOK, map[string]interface{}{"app_id": app["app_id"], "credential": mockClone(app["credential"])}
+	case len(rest) == 2 && rest[1] == "reset_secret" && method == "POST":
+		if _, ok := app["credential"].(map[string]interface{})["secret"]; !ok {
+			return mockError(http.StatusBadRequest, "App does not authenticate with an API key")
+		}
+		app["credential"] = map[string]interface{}{"secret": mockUUID()}
+		return http.StatusOK, m.view(apps, app)
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// [-]: stored credential of an app, DSM generates the AWS XKS keys
+func mockAppCredential(credential map[string]interface{}) interface{} {
+	if cert, ok := credential["certificate"].(string); ok {
+		return map[string]interface{}{"certificate": map[string]interface{}{"certificate": cert}}
+	}
+	if _, ok := credential["awsxks"]; ok {
+		return map[string]interface{}{"awsxks": map[string]interface{}{
+			"access_key_id": "AKIA" + strings.ToUpper(hex.EncodeToString([]byte(mockUUID()))[:16]),
+			"secret_key":    base64.StdEncoding.EncodeToString([]byte(mockUUID())),
+		}}
+	}
+	return mockClone(credential)
+}
+
+// [-]: add_groups, mod_groups and del_groups of an app or user request
+func (m *mockDSM) appGroups(obj map[string]interface{}, body map[string]interface{}) (int, interface{}) {
+	groups, _ := obj["groups"].(map[string]interface{})
+	if groups == nil {
+		groups = map[string]interface{}{}
+	}
+	for _, field := range []string{"add_groups", "mod_groups"} {
+		change, _ := body[field].(map[string]interface{})
+		for group_id, perms := range change {
+			if m.objects["groups"].get(group_id) == nil {
+				return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+			}
+			if field == "mod_groups" && groups[group_id] == nil {
+				return mockError(http.StatusBadRequest, "Not a member of group %s", group_id)
+			}
+			groups[group_id] = mockClone(perms)
+		}
+	}
+	switch del := body["del_groups"].(type) {
+	case []interface{}:
+		for _, group_id := range mockStrings(del) {
+			delete(groups, group_id)
+		}
+	case map[string]interface{}:
+		for group_id := range del {
+			delete(groups, group_id)
+		}
+	}
+	obj["groups"] = groups
+	return http.StatusOK, nil
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/users
+
+func (m *mockDSM) users(method string, rest []string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	users := m.objects["users"]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(users, query, nil)
+	case len(rest) == 1 && rest[0] == "invite" && method == "POST":
+		email, _ := body["user_email"].(string)
+		if !strings.Contains(email, "@") {
+			return mockError(http.StatusBadRequest, "Invalid email address %q", email)
+		}
+		if users.find(func(user map[string]interface{}) bool { return strings.EqualFold(user["user_email"].(string), email) }) != nil {
+			return mockError(http.StatusConflict, "User %s is already a member of this account", email)
+		}
+		user := map[string]interface{}{
+			"user_id":        mockUUID(),
+			"user_email":     email,
+			"account_role":   []interface{}{"ACCOUNTMEMBER"},
+			"groups":         map[string]interface{}{},
+			"email_verified": false,
+			"has_password":   false,
+			"created_at":     mockTime(time.Now()),
+		}
+		mockMerge(user, body, "first_name", "last_name", "account_role", "description")
+		if status, resp := m.appGroups(user, body); status != http.StatusOK {
+			return status, resp
+		}
+		users.put(user)
+		return http.StatusCreated, m.view(users, user)
+	}
+	if len(rest) == 0 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	user := users.get(rest[0])
+	if user == nil {
+		return mockError(http.StatusNotFound, "User does not exist")
+	}
+	switch {
+	case len(rest) == 1 && method == "GET":
+		return http.StatusOK, m.view(users, user)
+	case len(rest) == 1 && method == "PATCH":
+		mockMerge(user, body, "first_name", "last_name", "account_role", "description")
+		if status, resp := m.appGroups(user, body); status != http.StatusOK {
+			return status, resp
+		}
+		return http.StatusOK, m.view(users, user)
+	case len(rest) == 2 && rest[1] == "accounts" && method == "DELETE":
+		if user["user_id"] == m.user_id {
+			return mockError(http.StatusBadRequest, "The last account administrator can not be removed")
+		}
+		users.remove(rest[0])
+		return http.StatusNoContent, nil
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/plugins
+
+func (m *mockDSM) pluginByName(name string) map[string]interface{} {
+	return m.objects["plugins"].find(func(plugin map[string]interface{}) bool {
+		return plugin["name"] == name
+	})
+}
+
+func (m *mockDSM) plugins(method string, rest []string, query url.Values, in interface{}) (int, interface{}) {
+	plugins := m.objects["plugins"]
+	body, _ := in.(map[string]interface{})
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(plugins, query, nil)
+	case len(rest) == 0 && method == "POST":
+		name, _ := body["name"].(string)
+		if name == "" {
+			return mockError(http.StatusBadRequest, "name is required")
+		}
+		if m.pluginByName(name) != nil {
+			return mockError(http.StatusConflict, "Plugin with name %q already exists", name)
+		}
+		if _, ok := body["source"].(map[string]interface{}); !ok {
+			return mockError(http.StatusBadRequest, "source is required")
+		}
+		plugin := map[string]interface{}{
+			"plugin_id":   mockUUID(),
+			"name":        name,
+			"acct_id":     m.acct_id,
+			"creator":     map[string]interface{}{"user": m.user_id},
+			"enabled":     false,
+			"plugin_type": "STANDARD",
+			"groups":      []interface{}{},
+			"created_at":  mockTime(time.Now()),
+		}
+		mockMerge(plugin, body, "description", "default_group", "source", "plugin_type", "enabled")
+		groups := append(mockStrings(body["groups"]), mockStrings(body["add_groups"])...)
+		for _, group_id := range groups {
+			if m.objects["groups"].get(group_id) == nil {
+				return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+			}
+			if !mockContains(plugin["groups"], group_id) {
+				plugin["groups"] = append(plugin["groups"].([]interface{}), group_id)
+			}
+		}
+		plugins.put(plugin)
+		return http.StatusCreated, m.view(plugins, plugin)
+	}
+	if len(rest) != 1 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	plugin := plugins.get(rest[0])
+	if plugin == nil {
+		return mockError(http.StatusNotFound, "Plugin does not exist")
+	}
+	switch method {
+	case "GET":
+		return http.StatusOK, m.view(plugins, plugin)
+	case "PATCH":
+		if name, ok := body["name"].(string); ok && name != plugin["name"] {
+			if m.pluginByName(name) != nil {
+				return mockError(http.StatusConflict, "Plugin with name %q already exists", name)
+			}
+			plugin["name"] = name
+		}
+		mockMerge(plugin, body, "description", "default_group", "source", "enabled")
+		for _, group_id := range mockStrings(body["add_groups"]) {
+			if m.objects["groups"].get(group_id) == nil {
+				return mockError(http.StatusBadRequest, "Group %s does not exist", group_id)
+			}
+			if !mockContains(plugin["groups"], group_id) {
+				plugin["groups"] = append(plugin["groups"].([]interface{}), group_id)
+			}
+		}
+		groups := []interface{}{}
+		for _, group_id := range mockStrings(plugin["groups"]) {
+			if !mockContains(body["del_groups"], group_id) {
+				groups = append(groups, group_id)
+			}
+		}
+		plugin["groups"] = groups
+		return http.StatusOK, m.view(plugins, plugin)
+	case "DELETE":
+		plugins.remove(rest[0])
+		return http.StatusNoContent, nil
+	case "POST":
+		if plugin["enabled"] != true {
+			return mockError(http.StatusBadRequest, "Plugin is disabled")
+		}
+		return m.invokePlugin(plugin, in)
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// [-]: run a plugin, the CSR plugin of this provider is emulated and any
+// other plugin echoes its input
+func (m *mockDSM) invokePlugin(plugin map[string]interface{}, in interface{}) (int, interface{}) {
+	body, _ := in.(map[string]interface{})
+	switch plugin["name"] {
+	case "Terraform Plugin - CSR":
+		// see plugins/Terraform-Plugin-CSR.lua
+		kid, _ := body["kid"].(string)
+		signer, ok := m.signers[kid]
+		if !ok {
+			return mockError(http.StatusBadRequest, "Plugin error: %s is not an asymmetric key", kid)
+		}
+		digest, err := base64.StdEncoding.DecodeString(fmt.Sprint(body["data"]))
+		if err != nil || len(digest) != sha256.Size {
+			return mockError(http.StatusBadRequest, "Plugin error: data must be a base64 SHA256 digest")
+		}
+		signature, err := signer.Sign(rand.Reader, digest, crypto.SHA256)
+		if err != nil {
+			return mockError(http.StatusBadRequest, "Plugin error: %v", err)
+		}
+		return http.StatusOK, map[string]interface{}{"signature": base64.StdEncoding.EncodeToString(signature)}
+	}
+	return http.StatusOK, in
+}
+
+// seedPlugins installs the Lua plugin shipped with this provider, which CSRs
+// are created with.
+func (m *mockDSM) seedPlugins(t *testing.T, group_id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, file := range map[string]string{
+		"Terraform Plugin - CSR": "../plugins/Terraform-Plugin-CSR.lua",
+	} {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", file, err)
+		}
+		m.objects["plugins"].put(map[string]interface{}{
+			"plugin_id":     mockUUID(),
+			"name":          name,
+			"acct_id":       m.acct_id,
+			"creator":       map[string]interface{}{"user": m.user_id},
+			"default_group": group_id,
+			"groups":        []interface{}{group_id},
+			"enabled":       true,
+			"plugin_type":   "STANDARD",
+			"source":        map[string]interface{}{"language": "LUA", "code": string(code)},
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/accounts
+
+func (m *mockDSM) accounts(method string, rest []string, body map[string]interface{}) (int, interface{}) {
+	accounts := m.objects["accounts"]
+	if len(rest) != 1 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	account := accounts.get(rest[0])
+	if account == nil {
+		return mockError(http.StatusNotFound, "Account does not exist")
+	}
+	switch method {
+	case "GET":
+		return http.StatusOK, m.view(accounts, account)
+	case "PATCH":
+		mockMerge(account, body, "name", "description", "cryptographic_policy", "approval_policy", "key_history_policy")
+		m.normalizeGroup(account)
+		return http.StatusOK, m.view(accounts, account)
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// ---------------------------------------------------------------------------
+// sys/v1/approval_requests
+
+func (m *mockDSM) approvals(method string, rest []string, query url.Values, body map[string]interface{}) (int, interface{}) {
+	requests := m.objects["approval_requests"]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return m.list(requests, query, nil)
+	case len(rest) == 0 && method == "POST":
+		operation, _ := body["operation"].(string)
+		if operation == "" {
+			return mockError(http.StatusBadRequest, "operation is required")
+		}
+		request := map[string]interface{}{
+			"request_id": mockUUID(),
+			"acct_id":    m.acct_id,
+			"requester":  map[string]interface{}{"user": m.user_id},
+			"operation":  strings.TrimPrefix(operation, "/"),
+			"method":     "POST",
+			"status":     "PENDING",
+			"polls":      0,
+			"created_at": mockTime(time.Now()),
+			"expiry":     mockTime(time.Now().Add(24 * time.Hour)),
+		}
+		mockMerge(request, body, "method", "body", "description")
+		requests.put(request)
+		return http.StatusCreated, m.approvalView(request)
+	}
+	if len(rest) == 0 {
+		return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+	request := requests.get(rest[0])
+	if request == nil {
+		return mockError(http.StatusNotFound, "Approval request does not exist")
+	}
+	switch {
+	case len(rest) == 1 && method == "GET":
+		if request["status"] == "PENDING" {
+			request["polls"] = request["polls"].(int) + 1
+			if request["polls"].(int) >= m.approval_polls {
+				if m.deny_approvals {
+					request["status"] = "DENIED"
+				} else {
+					request["status"] = "APPROVED"
+				}
+			}
+		}
+		return http.StatusOK, m.approvalView(request)
+	case len(rest) == 1 && method == "DELETE":
+		requests.remove(rest[0])
+		return http.StatusNoContent, nil
+	case len(rest) == 2 && rest[1] == "deny" && method == "POST":
+		request["status"] = "DENIED"
+		return http.StatusOK, m.approvalView(request)
+	case len(rest) == 2 && rest[1] == "approve" && method == "POST":
+		request["status"] = "APPROVED"
+		return http.StatusOK, m.approvalView(request)
+	case len(rest) == 2 && rest[1] == "result" && method == "POST":
+		if request["status"] != "APPROVED" {
+			return mockError(http.StatusBadRequest, "Approval request is %s", request["status"])
+		}
+		if request["result"] == nil {
+			// the approved operation runs once, without the quorum check
+			method, _ := request["method"].(string)
+			operation := request["operation"].(string)
+			path, raw_query, _ := strings.Cut(operation, "?")
+			query, _ := url.ParseQuery(raw_query)
+			status, resp := m.route(method, path, query, mockClone(request["body"]))
+			if message, ok := resp.(mockMessage); ok {
+				resp = map[string]interface{}{"message": string(message)}
+			}
+			request["result"] = map[string]interface{}{"status": status, "body": mockClone(resp)}
+			request["status"] = "RESULT_FETCHED"
+		}
+		return http.StatusOK, mockClone(request["result"])
+	}
+	return mockError(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+func (m *mockDSM) approvalView(request map[string]interface{}) map[string]interface{} {
+	out := mockClone(request).(map[string]interface{})
+	delete(out, "polls")
+	delete(out, "result")
+	if out["status"] == "RESULT_FETCHED" {
+		out["status"] = "APPROVED"
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// test helpers
+
+// get returns a copy of a stored object, or nil.
+func (m *mockDSM) get(collection string, id string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj := m.objects[collection].get(id)
+	if obj == nil {
+		return nil
+	}
+	return mockClone(obj).(map[string]interface{})
+}
+
+// mutate changes a stored object behind the back of terraform, to simulate drift.
+func (m *mockDSM) mutate(collection string, id string, change func(map[string]interface{})) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if obj := m.objects[collection].get(id); obj != nil {
+		change(obj)
+	}
+}
+
+// remove deletes a stored object behind the back of terraform.
+func (m *mockDSM) remove(collection string, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[collection].remove(id)
+}
+
+// find returns a copy of the first stored object with the given name, or nil.
+func (m *mockDSM) find(collection string, name string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	field := "name"
+	if collection == "users" {
+		field = "user_email"
+	}
+	obj := m.objects[collection].find(func(obj map[string]interface{}) bool {
+		return obj[field] == name
+	})
+	if obj == nil {
+		return nil
+	}
+	return mockClone(obj).(map[string]interface{})
+}
+
+// seedGroup creates a group directly in the store and returns its id.
+func (m *mockDSM) seedGroup(name string, fields map[string]interface{}) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body := map[string]interface{}{"name": name}
+	for k, v := range fields {
+		body[k] = v
+	}
+	_, resp := m.groups("POST", nil, url.Values{}, mockClone(body).(map[string]interface{}))
+	return resp.(map[string]interface{})["group_id"].(string)
+}
+
+// seedKey creates a key directly in the store and returns its kid. A base64
+// "value" is imported, otherwise the key is generated.
+func (m *mockDSM) seedKey(t *testing.T, fields map[string]interface{}) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body := mockClone(fields).(map[string]interface{})
+	var material []byte
+	if value, ok := body["value"].(string); ok {
+		delete(body, "value")
+		material, _ = base64.StdEncoding.DecodeString(value)
+	}
+	status, resp := m.createKey(body, material)
+	if status != http.StatusCreated {
+		t.Fatalf("unable to seed key %v: %v", fields["name"], resp)
+	}
+	return resp.(map[string]interface{})["kid"].(string)
+}
+
+// seedApp creates an app directly in the store and returns its id.
+func (m *mockDSM) seedApp(t *testing.T, fields map[string]interface{}) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, resp := m.apps("POST", nil, url.Values{}, mockClone(fields).(map[string]interface{}))
+	if status != http.StatusCreated {
+		t.Fatalf("unable to seed app %v: %v", fields["name"], resp)
+	}
+	return resp.(map[string]interface{})["app_id"].(string)
+}
+
+// seedRotation rotates the key with the given name and returns the kid of
+// the new version.
+func (m *mockDSM) seedRotation(t *testing.T, name string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, resp := m.rekey(map[string]interface{}{"name": name})
+	if status != http.StatusCreated {
+		t.Fatalf("unable to rotate key %s: %v", name, resp)
+	}
+	return resp.(map[string]interface{})["kid"].(string)
+}
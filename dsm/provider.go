@@ -11,13 +11,15 @@ package dsm
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-const debug_output = false
-
 // [-] Define Provider
 func Provider() *schema.Provider {
 	return &schema.Provider{
@@ -83,6 +85,83 @@ func Provider() *schema.Provider {
 				Optional: true,
 				Default:  "",
 			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_pem"},
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_file"},
+			},
+			"server_cert_sha256_fingerprints": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile(`^([0-9A-Fa-f]{2}:?){31}[0-9A-Fa-f]{2}$`), "should be a hex encoded SHA-256 fingerprint"),
+				},
+			},
+			"app_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DSM_APP_ID", ""),
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DSM_CLIENT_CERT", ""),
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("DSM_CLIENT_KEY", ""),
+			},
+			"jwt_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("DSM_JWT_TOKEN", nil),
+				ConflictsWith: []string{"jwt_token_file"},
+			},
+			"jwt_token_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("DSM_JWT_TOKEN_FILE", nil),
+				ConflictsWith: []string{"jwt_token"},
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_wait_min": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_wait_max": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.FloatAtLeast(0.01),
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"dsm_sobject":             resourceSobject(),
@@ -109,17 +188,19 @@ func Provider() *schema.Provider {
 			"dsm_api":                 resourceAPI(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"dsm_aws_group":    dataSourceAWSGroup(),
-			"dsm_azure_group":  dataSourceAzureGroup(),
-			"dsm_secret":       dataSourceSecret(),
-			"dsm_group":        dataSourceGroup(),
-			"dsm_user":         dataSourceUser(),
-			"dsm_role":         dataSourceRole(),
-			"dsm_version":      dataSourceVersion(),
-			"dsm_app":          dataSourceApp(),
-			"dsm_sobject":      dataSourceSobject(),
-			"dsm_sobject_info": dataSourceSobjectInfo(),
-			"dsm_plugin":       dataSourcePlugin(),
+			"dsm_aws_group":        dataSourceAWSGroup(),
+			"dsm_azure_group":      dataSourceAzureGroup(),
+			"dsm_secret":           dataSourceSecret(),
+			"dsm_group":            dataSourceGroup(),
+			"dsm_user":             dataSourceUser(),
+			"dsm_role":             dataSourceRole(),
+			"dsm_version":          dataSourceVersion(),
+			"dsm_app":              dataSourceApp(),
+			"dsm_sobject":          dataSourceSobject(),
+			"dsm_sobject_info":     dataSourceSobjectInfo(),
+			"dsm_plugin":           dataSourcePlugin(),
+			"dsm_wrapped_key":      dataSourceWrappedKey(),
+			"dsm_sobject_versions": dataSourceSobjectVersions(),
 		},
 		ConfigureContextFunc: configureProvider,
 	}
@@ -128,11 +209,60 @@ func Provider() *schema.Provider {
 func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
+	http_options := http_client_options{
+		max_retries:         d.Get("max_retries").(int),
+		retry_wait_min:      d.Get("retry_wait_min").(int),
+		retry_wait_max:      d.Get("retry_wait_max").(int),
+		requests_per_second: d.Get("requests_per_second").(float64),
+		burst:               d.Get("burst").(int),
+		ca_cert_pem:         d.Get("ca_cert_pem").(string),
+	}
+	if ca_cert_file := d.Get("ca_cert_file").(string); len(ca_cert_file) > 0 {
+		ca_cert_pem, err := os.ReadFile(ca_cert_file)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "[DSM SDK]: Unable to configure DSM provider",
+				Detail:   fmt.Sprintf("[E]: SDK: Terraform: unable to read ca_cert_file: %s", err),
+			})
+			return nil, diags
+		}
+		http_options.ca_cert_pem = string(ca_cert_pem)
+	}
+	if client_cert := d.Get("client_cert").(string); len(client_cert) > 0 {
+		client_cert_pem, err := readPEMArgument(client_cert)
+		if err != nil {
+			return nil, invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: SDK: Terraform: unable to read client_cert: %s", err), "[DSM SDK]: Unable to configure DSM provider")
+		}
+		if len(d.Get("client_key").(string)) == 0 {
+			return nil, invokeErrorDiagsWithSummary("[E]: SDK: Terraform: client_key should be specified with client_cert", "[DSM SDK]: Unable to configure DSM provider")
+		}
+		client_key_pem, err := readPEMArgument(d.Get("client_key").(string))
+		if err != nil {
+			return nil, invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: SDK: Terraform: unable to read client_key: %s", err), "[DSM SDK]: Unable to configure DSM provider")
+		}
+		http_options.client_cert_pem = client_cert_pem
+		http_options.client_key_pem = client_key_pem
+	}
+	for _, fingerprint := range d.Get("server_cert_sha256_fingerprints").([]interface{}) {
+		http_options.fingerprints = append(http_options.fingerprints, fingerprint.(string))
+	}
+	if http_options.retry_wait_max < http_options.retry_wait_min {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK]: Unable to configure DSM provider",
+			Detail:   fmt.Sprintf("[E]: SDK: Terraform: retry_wait_max (%d) should not be less than retry_wait_min (%d)", http_options.retry_wait_max, http_options.retry_wait_min),
+		})
+		return nil, diags
+	}
+
 	// Create new API client
-	newclient, err := NewAPIClient(d.Get("endpoint").(string), d.Get("port").(int), d.Get("username").(string),
+	newclient, err := NewAPIClient(ctx, d.Get("endpoint").(string), d.Get("port").(int), d.Get("username").(string),
 	                               d.Get("password").(string), d.Get("api_key").(string), d.Get("acct_id").(string),
 	                               d.Get("aws_profile").(string), d.Get("aws_region").(string), d.Get("azure_region").(string),
-	                               d.Get("insecure").(bool), d.Get("timeout").(int), d.Get("ldap_name").(string))
+	                               d.Get("insecure").(bool), d.Get("timeout").(int), d.Get("ldap_name").(string),
+	                               d.Get("app_id").(string), d.Get("jwt_token").(string), d.Get("jwt_token_file").(string),
+	                               http_options)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -144,3 +274,15 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 
 	return newclient, nil
 }
+
+// PEM arguments can be given inline or as a path to a PEM file
+func readPEMArgument(value string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return value, nil
+	}
+	pem, err := os.ReadFile(value)
+	if err != nil {
+		return "", err
+	}
+	return string(pem), nil
+}
@@ -0,0 +1,131 @@
+// **********
+// Terraform Provider - DSM: plugin framework provider
+// **********
+
+package dsm
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// NewProviderServer serves the SDKv2 provider and the plugin framework
+// provider as one provider. Resources move to the framework one at a time,
+// new kinds of resources (ephemeral resources) only exist there.
+func NewProviderServer(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	sdk_provider := Provider()
+	mux_server, err := tf5muxserver.NewMuxServer(ctx,
+		sdk_provider.GRPCProvider,
+		providerserver.NewProtocol5(NewFrameworkProvider(sdk_provider.Meta)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return mux_server.ProviderServer, nil
+}
+
+// [-] Define Framework Provider
+type frameworkProvider struct {
+	// meta returns the api_client of the SDKv2 provider, which the mux
+	// server configures first: both providers share one DSM session.
+	meta func() interface{}
+}
+
+var _ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+
+func NewFrameworkProvider(meta func() interface{}) provider.Provider {
+	return &frameworkProvider{
+		meta: meta,
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "dsm"
+}
+
+// Schema matches the SDKv2 provider schema, the mux server requires both to
+// be identical. The SDKv2 provider validates the configuration.
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"endpoint":            schema.StringAttribute{Optional: true},
+			"port":                schema.Int64Attribute{Optional: true},
+			"insecure":            schema.BoolAttribute{Optional: true},
+			"username":            schema.StringAttribute{Optional: true},
+			"password":            schema.StringAttribute{Optional: true, Sensitive: true},
+			"api_key":             schema.StringAttribute{Optional: true, Sensitive: true},
+			"acct_id":             schema.StringAttribute{Optional: true},
+			"aws_profile":         schema.StringAttribute{Optional: true},
+			"aws_region":          schema.StringAttribute{Optional: true},
+			"azure_region":        schema.StringAttribute{Optional: true},
+			"timeout":             schema.Int64Attribute{Optional: true},
+			"ldap_name":           schema.StringAttribute{Optional: true},
+			"ca_cert_file":        schema.StringAttribute{Optional: true},
+			"ca_cert_pem":         schema.StringAttribute{Optional: true},
+			"app_id":              schema.StringAttribute{Optional: true},
+			"client_cert":         schema.StringAttribute{Optional: true},
+			"client_key":          schema.StringAttribute{Optional: true, Sensitive: true},
+			"jwt_token":           schema.StringAttribute{Optional: true, Sensitive: true},
+			"jwt_token_file":      schema.StringAttribute{Optional: true},
+			"max_retries":         schema.Int64Attribute{Optional: true},
+			"retry_wait_min":      schema.Int64Attribute{Optional: true},
+			"retry_wait_max":      schema.Int64Attribute{Optional: true},
+			"requests_per_second": schema.Float64Attribute{Optional: true},
+			"burst":               schema.Int64Attribute{Optional: true},
+			"server_cert_sha256_fingerprints": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	// the SDKv2 provider has already reported why it could not be configured
+	client, ok := p.meta().(*api_client)
+	if !ok {
+		return
+	}
+	resp.ResourceData = client
+	resp.DataSourceData = client
+	resp.EphemeralResourceData = client
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newEphemeralSecretValue,
+		newEphemeralAppCredential,
+	}
+}
+
+// frameworkDiags converts the diagnostics of the shared SDKv2 helpers.
+func frameworkDiags(diags diag.Diagnostics) fwdiag.Diagnostics {
+	var fw_diags fwdiag.Diagnostics
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			fw_diags.AddError(d.Summary, d.Detail)
+		} else {
+			fw_diags.AddWarning(d.Summary, d.Detail)
+		}
+	}
+	return fw_diags
+}
@@ -0,0 +1,170 @@
+package dsm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// The terraform CLI of the test harness predates ephemeral resources and
+// write-only arguments, so those are tested through the provider protocol.
+
+// testUnitProtoV5Server returns the muxed provider server configured against
+// the mock DSM, with the schemas it serves.
+func testUnitProtoV5Server(t *testing.T) (tfprotov5.ProviderServer, *tfprotov5.GetProviderSchemaResponse) {
+	ctx := context.Background()
+	factory, err := NewProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("unable to create the provider server: %s", err)
+	}
+	server := factory()
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	testUnitProtoV5Diags(t, "GetProviderSchema", err, schemas.Diagnostics)
+
+	configured, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{
+		Config: testUnitDynamicValue(t, schemas.Provider, nil),
+	})
+	testUnitProtoV5Diags(t, "ConfigureProvider", err, configured.Diagnostics)
+	return server, schemas
+}
+
+// testUnitProtoV5Diags fails the test on an error diagnostic.
+func testUnitProtoV5Diags(t *testing.T, rpc string, err error, diags []*tfprotov5.Diagnostic) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %s", rpc, err)
+	}
+	for _, d := range diags {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Fatalf("%s: %s: %s", rpc, d.Summary, d.Detail)
+		}
+	}
+}
+
+// testUnitProtoV5Error returns the error diagnostics as one string.
+func testUnitProtoV5Error(diags []*tfprotov5.Diagnostic) string {
+	var errors []string
+	for _, d := range diags {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			errors = append(errors, d.Summary+": "+d.Detail)
+		}
+	}
+	return strings.Join(errors, "\n")
+}
+
+// testUnitDynamicValue encodes an object of the schema: unset attributes are
+// null, unset list and set blocks are empty.
+func testUnitDynamicValue(t *testing.T, schema *tfprotov5.Schema, values map[string]tftypes.Value) *tfprotov5.DynamicValue {
+	typ := schema.ValueType()
+	attributes := map[string]tftypes.Value{}
+	for name, attr_type := range typ.(tftypes.Object).AttributeTypes {
+		attributes[name] = tftypes.NewValue(attr_type, nil)
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		}
+	}
+	for _, block := range schema.Block.BlockTypes {
+		if _, ok := values[block.TypeName]; ok {
+			continue
+		}
+		switch block.Nesting {
+		case tfprotov5.SchemaNestedBlockNestingModeList, tfprotov5.SchemaNestedBlockNestingModeSet:
+			attributes[block.TypeName] = tftypes.NewValue(attributes[block.TypeName].Type(), []tftypes.Value{})
+		}
+	}
+	dynamic_value, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, attributes))
+	if err != nil {
+		t.Fatalf("unable to encode %v: %s", values, err)
+	}
+	return &dynamic_value
+}
+
+// testUnitDecode decodes an object of the schema.
+func testUnitDecode(t *testing.T, schema *tfprotov5.Schema, dynamic_value *tfprotov5.DynamicValue) map[string]tftypes.Value {
+	t.Helper()
+	value, err := dynamic_value.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatalf("unable to decode: %s", err)
+	}
+	attributes := map[string]tftypes.Value{}
+	if err := value.As(&attributes); err != nil {
+		t.Fatalf("unable to decode: %s", err)
+	}
+	return attributes
+}
+
+// testUnitString returns a decoded string attribute, or "" when it is null.
+func testUnitString(t *testing.T, value tftypes.Value) string {
+	t.Helper()
+	var s *string
+	if err := value.As(&s); err != nil {
+		t.Fatalf("unable to decode %v: %s", value, err)
+	}
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// testUnitCreate validates, plans and applies the creation of a resource
+// by a client that allows write-only arguments, and returns its new state.
+func testUnitCreate(t *testing.T, server tfprotov5.ProviderServer, schemas *tfprotov5.GetProviderSchemaResponse, type_name string, values map[string]tftypes.Value) map[string]tftypes.Value {
+	ctx := context.Background()
+	schema := schemas.ResourceSchemas[type_name]
+	config := testUnitDynamicValue(t, schema, values)
+	prior_state, err := tfprotov5.NewDynamicValue(schema.ValueType(), tftypes.NewValue(schema.ValueType(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validated, err := server.ValidateResourceTypeConfig(ctx, &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName:           type_name,
+		Config:             config,
+		ClientCapabilities: &tfprotov5.ValidateResourceTypeConfigClientCapabilities{WriteOnlyAttributesAllowed: true},
+	})
+	testUnitProtoV5Diags(t, "ValidateResourceTypeConfig", err, validated.Diagnostics)
+
+	planned, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         type_name,
+		PriorState:       &prior_state,
+		ProposedNewState: config,
+		Config:           config,
+	})
+	testUnitProtoV5Diags(t, "PlanResourceChange", err, planned.Diagnostics)
+
+	applied, err := server.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       type_name,
+		PriorState:     &prior_state,
+		PlannedState:   planned.PlannedState,
+		Config:         config,
+		PlannedPrivate: planned.PlannedPrivate,
+	})
+	testUnitProtoV5Diags(t, "ApplyResourceChange", err, applied.Diagnostics)
+	return testUnitDecode(t, schema, applied.NewState)
+}
+
+func TestUnitProviderServer(t *testing.T) {
+	newMockDSM(t)
+	_, schemas := testUnitProtoV5Server(t)
+
+	for _, name := range []string{"dsm_secret_value", "dsm_app_credential"} {
+		if _, ok := schemas.EphemeralResourceSchemas[name]; !ok {
+			t.Errorf("ephemeral resource %s is not served", name)
+		}
+	}
+	for _, name := range []string{"dsm_sobject", "dsm_secret"} {
+		write_only := false
+		for _, attr := range schemas.ResourceSchemas[name].Block.Attributes {
+			if attr.Name == "value_wo" {
+				write_only = attr.WriteOnly
+			}
+		}
+		if !write_only {
+			t.Errorf("%s.value_wo is not write-only", name)
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package dsm
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const (
@@ -66,3 +72,133 @@ func TestProvider_impl(t *testing.T) {
 		_ *schema.Provider = Provider()
 	)
 }
+
+// API endpoint of the object managed by a resource type, used by testAccCheckDestroy
+var testAccObjectEndpoints = map[string]string{
+	"dsm_sobject":         "crypto/v1/keys",
+	"dsm_secret":          "crypto/v1/keys",
+	"dsm_aws_sobject":     "crypto/v1/keys",
+	"dsm_azure_sobject":   "crypto/v1/keys",
+	"dsm_gcp_sobject":     "crypto/v1/keys",
+	"dsm_group":           "sys/v1/groups",
+	"dsm_aws_group":       "sys/v1/groups",
+	"dsm_azure_group":     "sys/v1/groups",
+	"dsm_app":             "sys/v1/apps",
+	"dsm_admin_app":       "sys/v1/apps",
+	"dsm_app_non_api_key": "sys/v1/apps",
+	"dsm_gcp_ekm_sa":      "sys/v1/apps",
+	"dsm_user":            "sys/v1/users",
+	"dsm_plugin":          "sys/v1/plugins",
+}
+
+// testAccCheckDestroy verifies that DSM no longer has the objects of the
+// destroyed resources. It works against DSM and against the mock DSM.
+func testAccCheckDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*api_client)
+	for name, rs := range s.RootModule().Resources {
+		endpoint, ok := testAccObjectEndpoints[rs.Type]
+		if !ok || strings.HasPrefix(name, "data.") {
+			continue
+		}
+		_, err := client.APICall(context.Background(), "GET", fmt.Sprintf("%s/%s", endpoint, rs.Primary.ID))
+		if err == nil {
+			return fmt.Errorf("%s %s still exists in DSM", name, rs.Primary.ID)
+		}
+		if !IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// testUnitCheckMock runs a check against the object a resource manages in the mock DSM.
+func testUnitCheckMock(m *mockDSM, collection string, name string, check func(obj map[string]interface{}) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s is not in the state", name)
+		}
+		obj := m.get(collection, rs.Primary.ID)
+		if obj == nil {
+			return fmt.Errorf("%s %s does not exist in DSM", name, rs.Primary.ID)
+		}
+		return check(obj)
+	}
+}
+
+// testUnitCheckRotatedMock checks the external state, at custom_metadata state_key,
+// of a BYOK key that an in-place rotation replaced in the mock DSM.
+func testUnitCheckRotatedMock(m *mockDSM, kid *string, state_key string, state string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		key := m.get("keys", *kid)
+		if key == nil {
+			return fmt.Errorf("rotated security object %s does not exist in DSM", *kid)
+		}
+		if metadata, _ := key["custom_metadata"].(map[string]interface{}); metadata[state_key] != state {
+			return fmt.Errorf("rotated security object %s is not %s: %v", *kid, state, metadata)
+		}
+		return nil
+	}
+}
+
+// testUnitID returns the id of a resource from the last state, for drift PreConfigs.
+func testUnitID(id *string, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s is not in the state", name)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+func TestUnitSessionExpiry(t *testing.T) {
+	m := newMockDSM(t)
+	// every session expires after a few calls, in the middle of each run
+	m.session_lifetime = 3
+
+	config := func(description string) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name        = "example_group"
+  description = %q
+}
+
+resource "dsm_sobject" "example_sobject" {
+  name        = "example_sobject"
+  group_id    = dsm_group.example_group.group_id
+  obj_type    = "AES"
+  key_size    = 256
+  description = %q
+}
+`, description, description)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("created by terraform"),
+			},
+			{
+				Config: config("updated by terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckMock(m, "keys", "dsm_sobject.example_sobject", func(key map[string]interface{}) error {
+						if key["description"] != "updated by terraform" {
+							return fmt.Errorf("key was not updated: %v", key)
+						}
+						return nil
+					}),
+					func(s *terraform.State) error {
+						if m.unauthorized == 0 {
+							return fmt.Errorf("no session expired")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
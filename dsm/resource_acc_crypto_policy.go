@@ -2,7 +2,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -12,7 +11,7 @@ import (
 
 // [-] Define Account Cryptographic Policy
 func resourceAccountCryptoPolicy() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateAccountCryptoPolicy,
 		ReadContext:   resourceReadAccountCryptoPolicy,
 		UpdateContext: resourceUpdateAccountCryptoPolicy,
@@ -24,28 +23,28 @@ func resourceAccountCryptoPolicy() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
-			"approval_policy": {
-			    Description: "The Fortanix DSM account object quorum approval policy definition as a JSON string.",
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"cryptographic_policy": {
-			    Description: "The Fortanix DSM account object cryptographic policy definition as a JSON string.",
-				Type:     schema.TypeString,
-				Required: true,
-			},
+			"approval_policy": approvalPolicySchema("The quorum approval policy of the account from Fortanix DSM.", true, true),
+			"cryptographic_policy": cryptoPolicySchema("The cryptographic policy of the account. It restricts the types of security objects of the account, their key sizes and their key operations. An algorithm without a block is not allowed."),
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: validateCryptoPolicyCustomizeDiff,
+		Timeouts:      approvalDeleteTimeouts(),
+		SchemaVersion: 2,
 	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		approvalPolicyStateUpgrader(resource, 0, true),
+		cryptoPolicyStateUpgrader(resource, 1),
+	}
+	return resource
 }
 
 // [C]: Create Account Crypto Policy
 func resourceCreateAccountCryptoPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	cryptographic_policy := json.RawMessage(d.Get("cryptographic_policy").(string))
+	cryptographic_policy := cryptoPolicyWrite(d.Get("cryptographic_policy").([]interface{}))
 
 	accountApprovalPolicyRead(ctx, d, m)
 
@@ -53,25 +52,26 @@ func resourceCreateAccountCryptoPolicy(ctx context.Context, d *schema.ResourceDa
 	acct_id := d.Get("acct_id").(string)
 	operation := "PATCH"
 	url := fmt.Sprintf("sys/v1/accounts/%s", acct_id)
+	requires_approval := false
 
 	if _, ok := d.GetOk("approval_policy"); ok {
-		if debug_output {
-			tflog.Warn(ctx, "[C & U]: Approval policy is present.")
-		}
-		account_crypto_policy_object["method"] = "PATCH"
-		account_crypto_policy_object["operation"] = url
-		account_crypto_policy_object["body"] = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
-		operation = "POST"
-		url = "sys/v1/approval_requests"
+		tflog.Debug(ctx, "[C & U]: Approval policy is present.")
+		account_crypto_policy_object = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
+		requires_approval = true
 	} else {
-		if debug_output {
-			tflog.Warn(ctx, "[C & U]: Approval policy is not set.")
-		}
+		tflog.Debug(ctx, "[C & U]: Approval policy is not set.")
 		account_crypto_policy_object["acct_id"] = acct_id
 		account_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
 	}
 
-	resp, derr := m.(*api_client).APICallBody(operation, url, account_crypto_policy_object)
+	var approval_diags diag.Diagnostics
+	var derr error
+	if requires_approval {
+		_, approval_diags, derr = m.(*api_client).approvedCall(ctx, operation, url, account_crypto_policy_object, approvalTimeout(d))
+	} else {
+		_, approval_diags, derr = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, account_crypto_policy_object, approvalTimeout(d))
+	}
+	diags = append(diags, approval_diags...)
 	if derr != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -81,10 +81,6 @@ func resourceCreateAccountCryptoPolicy(ctx context.Context, d *schema.ResourceDa
 		return diags
 	}
 
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[C & U]: API response for cryptographic policy create operation: %s", resp_json))
-	}
 
 	d.SetId(acct_id)
 	return diags
@@ -103,15 +99,10 @@ func resourceUpdateAccountCryptoPolicy(ctx context.Context, d *schema.ResourceDa
 func resourceReadAccountCryptoPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/accounts/%s", d.Id()))
-	if statuscode == 404 {
+	account, err := m.(*api_client).API().GetAccount(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] DSM provider API client returned not found",
-			Detail:   fmt.Sprintf("[R]: API Call: GET sys/v1/accounts/%s", d.Id()),
-		})
-		return diags
+		return nil
 	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
@@ -122,13 +113,14 @@ func resourceReadAccountCryptoPolicy(ctx context.Context, d *schema.ResourceData
 		return diags
 	}
 
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", account.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["approval_policy"]; ok {
-		if err := d.Set("approval_policy", fmt.Sprintf("%s", req["approval_policy"])); err != nil {
-			return diag.FromErr(err)
-		}
+	if err := d.Set("cryptographic_policy", cryptoPolicyRead(account.Cryptographic_policy)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("approval_policy", approvalPolicyRead(account.Approval_policy, nil, true)); err != nil {
+		return diag.FromErr(err)
 	}
 
 	return diags
@@ -142,28 +134,23 @@ func resourceDeleteAccountCryptoPolicy(ctx context.Context, d *schema.ResourceDa
 
 	account_crypto_policy_object := make(map[string]interface{})
 	acct_id := d.Get("acct_id").(string)
-	cryptographic_policy := "remove"
+	cryptographic_policy := crypto_policy_remove
 	operation := "PATCH"
 	url := fmt.Sprintf("sys/v1/accounts/%s", acct_id)
 
+	var approval_diags diag.Diagnostics
+	var err error
 	if _, ok := d.GetOk("approval_policy"); ok {
-		if debug_output {
-			tflog.Warn(ctx, "[D]: Approval policy is present.")
-		}
-		account_crypto_policy_object["method"] = "PATCH"
-		account_crypto_policy_object["operation"] = url
-		account_crypto_policy_object["body"] = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
-		operation = "POST"
-		url = "sys/v1/approval_requests"
+		tflog.Debug(ctx, "[D]: Approval policy is present.")
+		account_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
+		_, approval_diags, err = m.(*api_client).approvedCall(ctx, operation, url, account_crypto_policy_object, d.Timeout(schema.TimeoutDelete))
 	} else {
-		if debug_output {
-			tflog.Warn(ctx, "[D]: Approval policy is not set.")
-		}
+		tflog.Debug(ctx, "[D]: Approval policy is not set.")
 		account_crypto_policy_object["acct_id"] = acct_id
 		account_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
+		_, approval_diags, err = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, account_crypto_policy_object, d.Timeout(schema.TimeoutDelete))
 	}
-
-	resp, err := m.(*api_client).APICallBody(operation, url, account_crypto_policy_object)
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -173,21 +160,16 @@ func resourceDeleteAccountCryptoPolicy(ctx context.Context, d *schema.ResourceDa
 		return diags
 	}
 
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[D]: API response for cryptographic policy delete operation: %s", resp_json))
-	}
-
-	d.SetId(acct_id)
-	return nil
+	d.SetId("")
+	return diags
 }
 
 // Get account details
 func accountApprovalPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/accounts/%s", d.Get("acct_id").(string)))
-	if statuscode == 404 {
+	account, err := m.(*api_client).API().GetAccount(ctx, d.Get("acct_id").(string))
+	if IsNotFound(err) {
 		d.SetId("")
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -204,17 +186,11 @@ func accountApprovalPolicyRead(ctx context.Context, d *schema.ResourceData, m in
 			})
 			return diags
 		}
-		if debug_output {
-			tflog.Warn(ctx, fmt.Sprintf("[R]: API read account id: %s", req["acct_id"]))
-		}
-		if _, ok := req["approval_policy"]; ok {
-			if err := d.Set("approval_policy", fmt.Sprintf("%s", req["approval_policy"])); err != nil {
-				return diag.FromErr(err)
-			}
+		tflog.Debug(ctx, fmt.Sprintf("[R]: API read account id: %s", account.Acct_id))
+		if err := d.Set("approval_policy", approvalPolicyRead(account.Approval_policy, d.Get("approval_policy").([]interface{}), true)); err != nil {
+			return diag.FromErr(err)
 		}
 	}
-	if debug_output {
-		tflog.Warn(ctx, fmt.Sprintf("[R]: API read account approval policy: %s", req["approval_policy"]))
-	}
+	tflog.Debug(ctx, fmt.Sprintf("[R]: API read account approval policy: %v", account.Approval_policy))
 	return diags
 }
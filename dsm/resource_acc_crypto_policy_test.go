@@ -0,0 +1,88 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_acc_crypto_policy of the mock account
+func testUnitAccCryptoPolicyConfig(acct_id string, key_sizes string) string {
+	return fmt.Sprintf(`
+resource "dsm_acc_crypto_policy" "example_acc_crypto_policy" {
+  acct_id = %q
+  cryptographic_policy {
+    aes {
+      key_sizes = [%s]
+    }
+    hmac {
+      minimum_key_length = 256
+    }
+    secret {}
+  }
+}
+`, acct_id, key_sizes)
+}
+
+func TestUnitResourceAccCryptoPolicy(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+
+	check := func(key_sizes ...float64) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "accounts", "dsm_acc_crypto_policy.example_acc_crypto_policy", func(account map[string]interface{}) error {
+			policy, _ := account["cryptographic_policy"].(map[string]interface{})
+			aes, _ := policy["aes"].(map[string]interface{})
+			if fmt.Sprint(aes["key_sizes"]) != fmt.Sprint(key_sizes) {
+				return fmt.Errorf("account allows AES key sizes %v, expected %v", aes["key_sizes"], key_sizes)
+			}
+			return nil
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			// with a quorum policy the removal waits for the approvers
+			request := m.objects["approval_requests"].find(func(request map[string]interface{}) bool {
+				return request["operation"] == "sys/v1/accounts/"+m.acct_id && request["status"] == "PENDING"
+			})
+			if request != nil {
+				return fmt.Errorf("approval request %s to remove the cryptographic policy is still pending", request["request_id"])
+			}
+			if policy, ok := m.get("accounts", m.acct_id)["cryptographic_policy"]; ok {
+				return fmt.Errorf("cryptographic policy %v was not removed", policy)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAccCryptoPolicyConfig(m.acct_id, "128, 256"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_acc_crypto_policy.example_acc_crypto_policy", "id", m.acct_id),
+					check(128, 256),
+				),
+			},
+			{
+				Config: testUnitAccCryptoPolicyConfig(m.acct_id, "256"),
+				Check:  check(256),
+			},
+			{
+				// the account is put under quorum outside of terraform
+				PreConfig: func() {
+					m.mutate("accounts", m.acct_id, func(account map[string]interface{}) {
+						account["approval_policy"] = m.quorumPolicy()
+					})
+				},
+				Config: testUnitAccCryptoPolicyConfig(m.acct_id, "192, 256"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_acc_crypto_policy.example_acc_crypto_policy", "approval_policy.0.quorum.0.members.0.user", m.user_id),
+					resource.TestCheckResourceAttr("dsm_acc_crypto_policy.example_acc_crypto_policy", "approval_policy.0.manage_groups", "true"),
+					check(192, 256),
+				),
+			},
+		},
+	})
+}
@@ -2,7 +2,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -11,7 +10,7 @@ import (
 
 // [-] Define Account Quorum Policy
 func resourceAccountQuorumPolicy() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateAccountQuorumPolicy,
 		ReadContext:   resourceReadAccountQuorumPolicy,
 		UpdateContext: resourceUpdateAccountQuorumPolicy,
@@ -23,16 +22,21 @@ func resourceAccountQuorumPolicy() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
-			"approval_policy": {
-			    Description: "The Fortanix DSM account object quorum approval policy definition as a JSON string.",
-				Type:     schema.TypeString,
-				Required: true,
-			},
+			"approval_policy": approvalPolicySchema("The quorum approval policy of the account. The protected operations of the account wait for the approval of the quorum.", true, false),
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts:      approvalTimeouts(),
+		SchemaVersion: 1,
+		CustomizeDiff: validateApprovalPolicyCustomizeDiff,
+	}
+	resource.Schema["approval_policy"].Optional = false
+	resource.Schema["approval_policy"].Required = true
+	resource.StateUpgraders = []schema.StateUpgrader{
+		approvalPolicyStateUpgrader(resource, 0, true),
 	}
+	return resource
 }
 
 // [C]: Create Account Quorum Policy
@@ -41,10 +45,11 @@ func resourceCreateAccountQuorumPolicy(ctx context.Context, d *schema.ResourceDa
 
 	policy_object := map[string]interface{}{
 		"acct_id":         d.Get("acct_id").(string),
-		"approval_policy": json.RawMessage(d.Get("approval_policy").(string)),
+		"approval_policy": approvalPolicyWrite(d.Get("approval_policy").([]interface{}), true),
 	}
 
-	req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("sys/v1/accounts/%s", policy_object["acct_id"]), policy_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "PATCH", fmt.Sprintf("sys/v1/accounts/%s", policy_object["acct_id"]), policy_object, d.Timeout(schema.TimeoutCreate))
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -55,7 +60,7 @@ func resourceCreateAccountQuorumPolicy(ctx context.Context, d *schema.ResourceDa
 	}
 
 	d.SetId(req["acct_id"].(string))
-	return resourceReadAccountQuorumPolicy(ctx, d, m)
+	return append(diags, resourceReadAccountQuorumPolicy(ctx, d, m)...)
 
 }
 
@@ -63,8 +68,8 @@ func resourceCreateAccountQuorumPolicy(ctx context.Context, d *schema.ResourceDa
 func resourceReadAccountQuorumPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/accounts/%s", d.Id()))
-	if statuscode == 404 {
+	req, err := m.(*api_client).APICall(ctx, "GET", fmt.Sprintf("sys/v1/accounts/%s", d.Id()))
+	if IsNotFound(err) {
 		d.SetId("")
 	} else {
 		if err != nil {
@@ -79,10 +84,9 @@ func resourceReadAccountQuorumPolicy(ctx context.Context, d *schema.ResourceData
 		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
 			return diag.FromErr(err)
 		}
-		if _, ok := req["approval_policy"]; ok {
-			if err := d.Set("approval_policy", fmt.Sprintf("%v", req["approval_policy"])); err != nil {
-				return diag.FromErr(err)
-			}
+		dsm_policy, _ := req["approval_policy"].(map[string]interface{})
+		if err := d.Set("approval_policy", approvalPolicyRead(dsm_policy, d.Get("approval_policy").([]interface{}), true)); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 	return diags
@@ -90,6 +94,10 @@ func resourceReadAccountQuorumPolicy(ctx context.Context, d *schema.ResourceData
 
 // [U]: Update Account Quorum Policy
 func resourceUpdateAccountQuorumPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if d.HasChange("approval_policy") {
+		return resourceCreateAccountQuorumPolicy(ctx, d, m)
+	}
+
 	return nil
 }
 
@@ -114,7 +122,7 @@ func resourceDeleteAccountQuorumPolicy(ctx context.Context, d *schema.ResourceDa
 	}
 	operation = "POST"
 
-	_, err := m.(*api_client).APICallBody(operation, url, account_quorum_policy_object)
+	_, err := m.(*api_client).APICallBody(ctx, operation, url, account_quorum_policy_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -0,0 +1,97 @@
+package dsm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceAccQuorumPolicy(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	app_id := mockUUID()
+	config := func(members string) string {
+		return fmt.Sprintf(`
+resource "dsm_acc_quorum_policy" "example_acc_quorum_policy" {
+  acct_id = %q
+  approval_policy {
+    manage_groups = true
+    quorum {
+      n = 1
+      members {
+        user = %q
+      }%s
+    }
+  }
+}
+`, m.acct_id, m.user_id, members)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// removing a quorum policy needs the approval of its own quorum
+		CheckDestroy: func(s *terraform.State) error {
+			request := m.objects["approval_requests"].find(func(request map[string]interface{}) bool {
+				return request["operation"] == "sys/v1/accounts/"+m.acct_id && request["status"] == "PENDING"
+			})
+			if request == nil {
+				return fmt.Errorf("no approval request to remove the quorum policy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_acc_quorum_policy.example_acc_quorum_policy", "id", m.acct_id),
+					resource.TestCheckResourceAttr("dsm_acc_quorum_policy.example_acc_quorum_policy", "approval_policy.0.manage_groups", "true"),
+					testUnitCheckMock(m, "accounts", "dsm_acc_quorum_policy.example_acc_quorum_policy", func(account map[string]interface{}) error {
+						if !m.hasQuorum(account) {
+							return fmt.Errorf("account has no quorum policy: %v", account["approval_policy"])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// the account quorum approves the change of its own policy
+				Config: config(fmt.Sprintf(`
+      members {
+        app = %q
+      }`, app_id)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_acc_quorum_policy.example_acc_quorum_policy", "approval_policy.0.quorum.0.members.1.app", app_id),
+					testUnitCheckMock(m, "accounts", "dsm_acc_quorum_policy.example_acc_quorum_policy", func(account map[string]interface{}) error {
+						quorum := account["approval_policy"].(map[string]interface{})["policy"].(map[string]interface{})["quorum"].(map[string]interface{})
+						if members, _ := quorum["members"].([]interface{}); len(members) != 2 {
+							return fmt.Errorf("app was not added to the quorum: %v", quorum)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: config(`
+      members {
+        quorum {
+          n = 2
+          members {
+            app = "54e489ca-f5aa-4e59-869e-281bbd37caa2"
+          }
+        }
+      }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("n is 2 but the quorum has 1 members"),
+			},
+			{
+				ResourceName:      "dsm_acc_quorum_policy.example_acc_quorum_policy",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
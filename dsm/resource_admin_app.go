@@ -116,56 +116,59 @@ func resourceCreateAdminApp(ctx context.Context, d *schema.ResourceData, m inter
 	if am := d.Get("authentication_method").(map[string]interface{}); len(am) > 0 {
 		formCredential(d, app_object, am)
 	}
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/apps", app_object)
+	app, err := m.(*api_client).API().CreateApp(ctx, app_object)
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST sys/v1/apps: %v", err), error_summary)
 	}
 
-	d.SetId(req["app_id"].(string))
+	d.SetId(app.App_id)
 	return resourceReadAdminApp(ctx, d, m)
 }
 
 // [R]: Read App
 func resourceReadAdminApp(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	req, _, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	app, err := m.(*api_client).API().GetApp(ctx, d.Id())
+	if IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/apps: %v", err), error_summary)
 	}
 
-	if err := d.Set("name", req["name"].(string)); err != nil {
+	if err := d.Set("name", app.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("app_id", req["app_id"].(string)); err != nil {
+	if err := d.Set("app_id", app.App_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", app.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("creator", req["creator"]); err != nil {
+	if err := d.Set("creator", app.Creator.Map()); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["description"]; ok {
-		if err := d.Set("description", req["description"].(string)); err != nil {
+	if app.Description != nil {
+		if err := d.Set("description", *app.Description); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	req, _, err = m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s/credential", d.Id()))
+	app_credential, err := m.(*api_client).API().GetAppCredential(ctx, d.Id())
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/apps/-/credential: %v", err), error_summary)
 	}
 
 	credential := make(map[string]interface{})
-	req_credential := req["credential"].(map[string]interface{})
-	if val, ok := req_credential["awsxks"].(map[string]interface{}); ok {
-		credential["access_key_id"] =  base64.StdEncoding.EncodeToString([]byte(val["access_key_id"].(string)))
-		credential["secret_key"] = base64.StdEncoding.EncodeToString([]byte(val["secret_key"].(string)))
+	if val := app_credential.Awsxks; val != nil {
+		credential["access_key_id"] = base64.StdEncoding.EncodeToString([]byte(val.Access_key_id))
+		credential["secret_key"] = base64.StdEncoding.EncodeToString([]byte(val.Secret_key))
 		credential["path_prefix"] = "/crypto/v1/apps/" + d.Id() + "/aws"
-	} else if val, ok := req_credential["certificate"].(map[string]interface{}); ok {
-		credential["certificate"] = val["certificate"].(string)
-	} else if val, ok := req_credential["secret"].(string); ok {
-		credential["secret"] = base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+ val))
+	} else if val := app_credential.Certificate; val != nil {
+		credential["certificate"] = val.Certificate
+	} else if val := app_credential.Secret; val != nil {
+		credential["secret"] = base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+*val))
 	}
 	if err := d.Set("credential", credential); err != nil {
 			return diag.FromErr(err)
@@ -182,7 +185,7 @@ func resourceUpdateAdminApp(ctx context.Context, d *schema.ResourceData, m inter
 		reset_secret := map[string]interface{}{
 			"credential_migration_period": nil,
 		}
-		_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/apps/%s/reset_secret", d.Id()), reset_secret)
+		_, err := m.(*api_client).API().ResetAppSecret(ctx, d.Id(), reset_secret)
 		if err != nil {
 			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/apps/-/credential: %v", err), error_summary)
 		}
@@ -199,11 +202,11 @@ func resourceUpdateAdminApp(ctx context.Context, d *schema.ResourceData, m inter
 		formCredential(d, app_object, am)
 	}
 	if len(app_object) > 0 {
-		req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
+		app, err := m.(*api_client).API().UpdateApp(ctx, d.Id(), app_object)
 		if err != nil {
 			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: PATCH sys/v1/apps/%s: %v", d.Id(), err), error_summary)
 		}
-		d.SetId(req["app_id"].(string))
+		d.SetId(app.App_id)
 	}
 	return resourceReadAdminApp(ctx, d, m)
 }
@@ -211,8 +214,8 @@ func resourceUpdateAdminApp(ctx context.Context, d *schema.ResourceData, m inter
 // [D]: Delete App
 func resourceDeleteAdminApp(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) {
+	err := m.(*api_client).API().DeleteApp(ctx, d.Id())
+	if (err != nil) && !IsNotFound(err) {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: DELETE sys/v1/apps: %v", err), error_summary)
 	}
 
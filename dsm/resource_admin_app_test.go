@@ -0,0 +1,85 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// dsm_admin_app with an API key
+func testUnitAdminAppConfig(name string, description string) string {
+	return fmt.Sprintf(`
+resource "dsm_admin_app" "example_admin_app" {
+  name        = %q
+  description = %q
+  authentication_method = {
+    type = "secret"
+  }
+}
+`, name, description)
+}
+
+func TestUnitResourceAdminApp(t *testing.T) {
+	m := newMockDSM(t)
+	var app_id string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAdminAppConfig("example_admin_app", "created by terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&app_id, "dsm_admin_app.example_admin_app"),
+					resource.TestCheckResourceAttrSet("dsm_admin_app.example_admin_app", "credential.secret"),
+					testUnitCheckMock(m, "apps", "dsm_admin_app.example_admin_app", func(app map[string]interface{}) error {
+						if app["role"] != "admin" {
+							return fmt.Errorf("app is not an administrative app: %v", app)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:            "dsm_admin_app.example_admin_app",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"authentication_method", "new_credential"},
+			},
+			{
+				Config: testUnitAdminAppConfig("example_admin_app_updated", "updated by terraform"),
+				Check: testUnitCheckMock(m, "apps", "dsm_admin_app.example_admin_app", func(app map[string]interface{}) error {
+					if app["name"] != "example_admin_app_updated" || app["description"] != "updated by terraform" {
+						return fmt.Errorf("app was not updated: %v", app)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the app is renamed outside of terraform
+				PreConfig: func() {
+					m.mutate("apps", app_id, func(app map[string]interface{}) { app["name"] = "renamed_in_dsm" })
+				},
+				Config: testUnitAdminAppConfig("example_admin_app_updated", "updated by terraform"),
+				Check: testUnitCheckMock(m, "apps", "dsm_admin_app.example_admin_app", func(app map[string]interface{}) error {
+					if app["name"] != "example_admin_app_updated" {
+						return fmt.Errorf("drift was not corrected: %v", app)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the app is deleted outside of terraform
+				PreConfig: func() { m.remove("apps", app_id) },
+				Config:    testUnitAdminAppConfig("example_admin_app_updated", "updated by terraform"),
+				Check: testUnitCheckMock(m, "apps", "dsm_admin_app.example_admin_app", func(app map[string]interface{}) error {
+					if app["app_id"] == app_id {
+						return fmt.Errorf("app %s was not created again", app_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
@@ -51,6 +51,8 @@ func resourceAPI() *schema.Resource {
 				Description: "JSON body to invoke the request.",
 				Type:     schema.TypeString,
 				Optional: true,
+				DiffSuppressFunc: jsonDiffSuppress,
+				StateFunc:        normalizeJSON,
 			},
 			"api_id_attribute": {
 				Description: "Name of the response field to be used as the resource's unique identifier.",
@@ -128,16 +130,16 @@ func invokeAPI(ctx context.Context, d *schema.ResourceData, m interface{}) (map[
 	if resource_uuid := d.Get("resource_uuid").(string); len(resource_uuid) > 0 {
 		endpoint += "/" + resource_uuid
 	}
-	var err diag.Diagnostics
+	var err error
 	var req map[string]interface{}
 	if http_method == "GET" || http_method == "DELETE" {
-		req, _, err = m.(*api_client).APICall(http_method, endpoint)
+		req, err = m.(*api_client).APICall(ctx, http_method, endpoint)
 	} else {
 		var payload = map[string]interface{}{}
 		if json_body := d.Get("payload").(string); len(json_body) > 0 {
 			payload, _ = ConvertStringToJSONGeneric[map[string]interface{}](json_body)
 		}
-		req, err = m.(*api_client).APICallBody(http_method, endpoint, payload)
+		req, err = m.(*api_client).APICallBody(ctx, http_method, endpoint, payload)
 	}
 	if err != nil {
 		return nil, invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: %s %s: %v", http_method, endpoint, err), error_summary)
@@ -0,0 +1,104 @@
+package dsm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_api creating a group and patching its description
+func testUnitAPIConfig(description string) string {
+	return fmt.Sprintf(`
+resource "dsm_api" "create_group" {
+  method           = "POST"
+  resource_type    = "group"
+  api_id_attribute = "group_id"
+  payload = jsonencode({
+    name = "api_group"
+  })
+}
+
+resource "dsm_api" "patch_group" {
+  method        = "PATCH"
+  resource_type = "group"
+  resource_uuid = dsm_api.create_group.id
+  payload = jsonencode({
+    description = %q
+  })
+}
+`, description)
+}
+
+func TestUnitResourceAPI(t *testing.T) {
+	m := newMockDSM(t)
+
+	check_description := func(description string) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "groups", "dsm_api.create_group", func(group map[string]interface{}) error {
+			if group["name"] != "api_group" || group["description"] != description {
+				return fmt.Errorf("unexpected group: %v", group)
+			}
+			return nil
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// dsm_api never deletes what it created
+		CheckDestroy: func(s *terraform.State) error {
+			if m.groupByName("api_group") == nil {
+				return fmt.Errorf("api_group was deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAPIConfig("created through the API"),
+				Check: resource.ComposeTestCheckFunc(
+					check_description("created through the API"),
+					resource.TestCheckResourceAttrPair("dsm_api.create_group", "id", "dsm_api.patch_group", "resource_uuid"),
+					resource.TestCheckResourceAttrSet("dsm_api.patch_group", "api_response"),
+				),
+			},
+			{
+				Config: testUnitAPIConfig("updated through the API"),
+				Check:  check_description("updated through the API"),
+			},
+			{
+				// recall sends the same request again, it is reset after the call
+				PreConfig: func() {
+					m.mutate("groups", m.groupByName("api_group")["group_id"].(string), func(group map[string]interface{}) {
+						group["description"] = "changed in dsm"
+					})
+				},
+				Config: testUnitAPIConfig("updated through the API") + `
+resource "dsm_api" "get_group" {
+  method        = "GET"
+  resource_type = "group"
+  resource_uuid = dsm_api.create_group.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					check_description("changed in dsm"),
+					resource.TestCheckResourceAttrWith("dsm_api.get_group", "api_response", func(value string) error {
+						if !strings.Contains(value, `"description":"changed in dsm"`) {
+							return fmt.Errorf("unexpected response: %s", value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: strings.Replace(testUnitAPIConfig("updated through the API"), `resource_uuid = dsm_api.create_group.id
+  payload`, `resource_uuid = dsm_api.create_group.id
+  recall        = true
+  payload`, 1),
+				Check: check_description("updated through the API"),
+				// recall is read back as false and plans the call again
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
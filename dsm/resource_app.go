@@ -124,7 +124,7 @@ func resourceCreateApp(ctx context.Context, d *schema.ResourceData, m interface{
 	// add groups and it's permissions
 	formAddGroups(d, app_object)
 
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/apps", app_object)
+	app, err := m.(*api_client).API().CreateApp(ctx, app_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -134,7 +134,7 @@ func resourceCreateApp(ctx context.Context, d *schema.ResourceData, m interface{
 		return diags
 	}
 
-	d.SetId(req["app_id"].(string))
+	d.SetId(app.App_id)
 	return resourceReadApp(ctx, d, m)
 }
 
@@ -142,7 +142,11 @@ func resourceCreateApp(ctx context.Context, d *schema.ResourceData, m interface{
 func resourceReadApp(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, _, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	app, err := m.(*api_client).API().GetApp(ctx, d.Id())
+	if IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -152,28 +156,28 @@ func resourceReadApp(ctx context.Context, d *schema.ResourceData, m interface{})
 		return diags
 	}
 
-	if err := d.Set("name", req["name"].(string)); err != nil {
+	if err := d.Set("name", app.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("app_id", req["app_id"].(string)); err != nil {
+	if err := d.Set("app_id", app.App_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("default_group", req["default_group"].(string)); err != nil {
+	if err := d.Set("default_group", app.Default_group); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", app.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("creator", req["creator"]); err != nil {
+	if err := d.Set("creator", app.Creator.Map()); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["description"]; ok {
-		if err := d.Set("description", req["description"].(string)); err != nil {
+	if app.Description != nil {
+		if err := d.Set("description", *app.Description); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	req, _, err = m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s/credential", d.Id()))
+	credential, err := m.(*api_client).API().GetAppCredential(ctx, d.Id())
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -183,8 +187,10 @@ func resourceReadApp(ctx context.Context, d *schema.ResourceData, m interface{})
 		return diags
 	}
 
-	if err := d.Set("credential", base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+req["credential"].(map[string]interface{})["secret"].(string)))); err != nil {
-		return diag.FromErr(err)
+	if credential.Secret != nil {
+		if err := d.Set("credential", base64.StdEncoding.EncodeToString([]byte(d.Id()+":"+*credential.Secret))); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	if err := d.Set("new_credential", false); err != nil {
@@ -202,7 +208,7 @@ func resourceUpdateApp(ctx context.Context, d *schema.ResourceData, m interface{
 			"credential_migration_period": nil,
 		}
 
-		_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("sys/v1/apps/%s/reset_secret", d.Id()), reset_secret)
+		_, err := m.(*api_client).API().ResetAppSecret(ctx, d.Id(), reset_secret)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -235,7 +241,7 @@ func resourceUpdateApp(ctx context.Context, d *schema.ResourceData, m interface{
 		}
 	}
 	if len(app_object) > 0 {
-		req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
+		app, err := m.(*api_client).API().UpdateApp(ctx, d.Id(), app_object)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -245,7 +251,7 @@ func resourceUpdateApp(ctx context.Context, d *schema.ResourceData, m interface{
 
 			return diags
 		}
-		d.SetId(req["app_id"].(string))
+		d.SetId(app.App_id)
 	}
 	return resourceReadApp(ctx, d, m)
 }
@@ -254,8 +260,8 @@ func resourceUpdateApp(ctx context.Context, d *schema.ResourceData, m interface{
 func resourceDeleteApp(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) {
+	err := m.(*api_client).API().DeleteApp(ctx, d.Id())
+	if (err != nil) && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -58,7 +58,7 @@ func resourceCreateAppAssignGroups(ctx context.Context, d *schema.ResourceData,
 	app_id := ""
 	// Gets the App details through the app name or app id
 	if app_name := d.Get("app_name").(string); len(app_name) > 0 {
-		req, err := m.(*api_client).APICallList("GET", endpoint)
+		req, err := m.(*api_client).APICallList(ctx, "GET", endpoint)
 		if err != nil {
 			return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: GET %s: %v", endpoint, err))
 		}
@@ -87,7 +87,7 @@ func resourceCreateAppAssignGroups(ctx context.Context, d *schema.ResourceData,
 			"app_id": app_id,
 			"add_groups": add_groups,
 		}
-		req, err := m.(*api_client).APICallBody("PATCH", endpoint, app_object)
+		req, err := m.(*api_client).APICallBody(ctx, "PATCH", endpoint, app_object)
 		if err != nil {
 			return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: PATCH %s: %v", endpoint, err))
 		}
@@ -122,7 +122,7 @@ func resourceUpdateAppAssignGroups(ctx context.Context, d *schema.ResourceData,
 				"app_id": d.Id(),
 				"add_groups": add_groups,
 			}
-			_, err := m.(*api_client).APICallBody("PATCH", endpoint, app_object)
+			_, err := m.(*api_client).APICallBody(ctx, "PATCH", endpoint, app_object)
 			if err != nil {
 				d.Set("groups", old_group)
 				return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: PATCH %s: %v", endpoint, err))
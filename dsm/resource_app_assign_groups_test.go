@@ -0,0 +1,64 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// dsm_app_assign_groups adding the listed groups to an app, by name
+func testUnitAppAssignGroupsConfig(groups string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_group" "second_group" {
+  name = "second_group"
+}
+
+resource "dsm_group" "third_group" {
+  name = "third_group"
+}
+
+resource "dsm_app" "example_app" {
+  name          = "example_app"
+  default_group = dsm_group.example_group.group_id
+}
+
+resource "dsm_app_assign_groups" "example_app_assign_groups" {
+  app_name = dsm_app.example_app.name
+  groups   = [%s]
+}
+`, groups)
+}
+
+func TestUnitResourceAppAssignGroups(t *testing.T) {
+	m := newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAppAssignGroupsConfig("dsm_group.second_group.group_id"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dsm_app_assign_groups.example_app_assign_groups", "id", "dsm_app.example_app", "app_id"),
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.second_group", default_permissions...),
+				),
+			},
+			{
+				ResourceName: "dsm_app_assign_groups.example_app_assign_groups",
+				ImportState:  true,
+			},
+			{
+				Config: testUnitAppAssignGroupsConfig("dsm_group.second_group.group_id, dsm_group.third_group.group_id"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.second_group", default_permissions...),
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.third_group", default_permissions...),
+				),
+			},
+		},
+	})
+}
@@ -151,7 +151,7 @@ func resourceCreateAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m i
 	if am := d.Get("authentication_method").(map[string]interface{}); len(am) > 0 {
 		formCredential(d, app_object, am)
 	}
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/apps", app_object)
+	req, err := m.(*api_client).APICallBody(ctx, "POST", "sys/v1/apps", app_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -169,7 +169,11 @@ func resourceCreateAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m i
 func resourceReadAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, _, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	req, err := m.(*api_client).APICall(ctx, "GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	if IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -198,7 +202,7 @@ func resourceReadAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m int
 			return diag.FromErr(err)
 		}
 	}
-	req, _, err = m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s/credential", d.Id()))
+	req, err = m.(*api_client).APICall(ctx, "GET", fmt.Sprintf("sys/v1/apps/%s/credential", d.Id()))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -253,7 +257,7 @@ func resourceUpdateAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m i
 		formCredential(d, app_object, am)
 	}
 	if len(app_object) > 0 {
-		req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
+		req, err := m.(*api_client).APICallBody(ctx, "PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -272,8 +276,8 @@ func resourceUpdateAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m i
 func resourceDeleteAppNonAPIKey(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) {
+	_, err := m.(*api_client).APICall(ctx, "DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	if (err != nil) && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -0,0 +1,88 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testUnitCertificate = "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUX3unit\n-----END CERTIFICATE-----\n"
+
+// dsm_app_non_api_key with the given authentication method
+func testUnitAppNonAPIKeyConfig(name string, authentication_method string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_app_non_api_key" "example_app" {
+  name          = %q
+  default_group = dsm_group.example_group.group_id
+  authentication_method = %s
+}
+`, name, authentication_method)
+}
+
+func TestUnitResourceAppNonAPIKey(t *testing.T) {
+	m := newMockDSM(t)
+	var app_id string
+	certificate := fmt.Sprintf(`{
+    type        = "certificate"
+    certificate = %q
+  }`, testUnitCertificate)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAppNonAPIKeyConfig("example_app", `{ type = "awsxks" }`),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&app_id, "dsm_app_non_api_key.example_app"),
+					resource.TestCheckResourceAttrSet("dsm_app_non_api_key.example_app", "credential.access_key_id"),
+					resource.TestCheckResourceAttrSet("dsm_app_non_api_key.example_app", "credential.secret_key"),
+					resource.TestCheckResourceAttrPair("dsm_app_non_api_key.example_app", "default_group", "dsm_group.example_group", "group_id"),
+				),
+			},
+			{
+				ResourceName:            "dsm_app_non_api_key.example_app",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"authentication_method"},
+			},
+			{
+				Config: testUnitAppNonAPIKeyConfig("example_app_updated", certificate),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_app_non_api_key.example_app", "name", "example_app_updated"),
+					resource.TestCheckResourceAttr("dsm_app_non_api_key.example_app", "credential.certificate", testUnitCertificate),
+					resource.TestCheckNoResourceAttr("dsm_app_non_api_key.example_app", "credential.access_key_id"),
+				),
+			},
+			{
+				// drift: the app is renamed outside of terraform
+				PreConfig: func() {
+					m.mutate("apps", app_id, func(app map[string]interface{}) { app["name"] = "renamed_in_dsm" })
+				},
+				Config: testUnitAppNonAPIKeyConfig("example_app_updated", certificate),
+				Check: testUnitCheckMock(m, "apps", "dsm_app_non_api_key.example_app", func(app map[string]interface{}) error {
+					if app["name"] != "example_app_updated" {
+						return fmt.Errorf("drift was not corrected: %v", app)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the app is deleted outside of terraform
+				PreConfig: func() { m.remove("apps", app_id) },
+				Config:    testUnitAppNonAPIKeyConfig("example_app_updated", certificate),
+				Check: testUnitCheckMock(m, "apps", "dsm_app_non_api_key.example_app", func(app map[string]interface{}) error {
+					if app["app_id"] == app_id {
+						return fmt.Errorf("app %s was not created again", app_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
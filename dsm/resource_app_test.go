@@ -1,23 +1,27 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
+	"fmt"
+	"testing"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
-	"testing"
 )
 
 var (
 	resourceApp_createConfig = `resource "dsm_group" "example_group" {
 		name = "example_group"
 	}
-  
+
 	resource "dsm_app" "example_app" {
 		name = "example_app"
 	    	default_group = "${dsm_group.example_group.group_id}"
 	}`
-	resourceApp_updateConfig = `resource "dsm_app" "example_app {
+	resourceApp_updateConfig = `resource "dsm_group" "example_group" {
+		name = "example_group"
+	}
+
+	resource "dsm_app" "example_app" {
 		name = "example_app_updated"
     		default_group = "${dsm_group.example_group.group_id}"
 	}`
@@ -28,15 +32,152 @@ func TestAccResourceApp(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroyApp,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: resourceApp_createConfig,
 			},
+			{
+				Config: resourceApp_updateConfig,
+			},
 		},
 	})
 }
 
-func testAccCheckDestroyApp(s *terraform.State) (err error) {
-	return err
+// dsm_app in example_group and optionally other_group
+func testUnitAppConfig(name string, description string, other_group bool, extra string) string {
+	other := ""
+	if other_group {
+		other = `
+  other_group = [dsm_group.other_group.group_id]
+  other_group_permissions = {
+    (dsm_group.other_group.group_id) = "SIGN,VERIFY"
+  }`
+	}
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_group" "other_group" {
+  name = "other_group"
+}
+
+resource "dsm_app" "example_app" {
+  name          = %q
+  description   = %q
+  default_group = dsm_group.example_group.group_id
+  %s
+  %s
+}
+`, name, description, other, extra)
+}
+
+// testUnitCheckAppGroup verifies the permissions of an app in a group of the mock DSM.
+func testUnitCheckAppGroup(m *mockDSM, name string, group string, perms ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group_id := s.RootModule().Resources[group].Primary.ID
+		return testUnitCheckMock(m, "apps", name, func(app map[string]interface{}) error {
+			groups, _ := app["groups"].(map[string]interface{})
+			got, ok := groups[group_id]
+			if len(perms) == 0 {
+				if ok {
+					return fmt.Errorf("%s is still in %s", name, group)
+				}
+				return nil
+			}
+			if fmt.Sprint(got) != fmt.Sprint(perms) {
+				return fmt.Errorf("%s has permissions %v in %s, expected %v", name, got, group, perms)
+			}
+			return nil
+		})(s)
+	}
+}
+
+func TestUnitResourceApp(t *testing.T) {
+	m := newMockDSM(t)
+	var app_id, credential string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAppConfig("example_app", "created by terraform", true, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&app_id, "dsm_app.example_app"),
+					resource.TestCheckResourceAttrPair("dsm_app.example_app", "app_id", "dsm_app.example_app", "id"),
+					resource.TestCheckResourceAttr("dsm_app.example_app", "acct_id", m.acct_id),
+					resource.TestCheckResourceAttrSet("dsm_app.example_app", "credential"),
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.example_group", default_permissions...),
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.other_group", "SIGN", "VERIFY"),
+					func(s *terraform.State) error {
+						credential = s.RootModule().Resources["dsm_app.example_app"].Primary.Attributes["credential"]
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "dsm_app.example_app",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// group memberships are not read back
+				ImportStateVerifyIgnore: []string{"other_group", "other_group_permissions", "new_credential"},
+			},
+			{
+				Config: testUnitAppConfig("example_app_updated", "updated by terraform", true, `
+  mod_group_permissions = {
+    (dsm_group.example_group.group_id) = "ENCRYPT,DECRYPT"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_app.example_app", "name", "example_app_updated"),
+					resource.TestCheckResourceAttr("dsm_app.example_app", "description", "updated by terraform"),
+					testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.example_group", "ENCRYPT", "DECRYPT"),
+				),
+			},
+			{
+				Config: testUnitAppConfig("example_app_updated", "updated by terraform", false, ""),
+				Check:  testUnitCheckAppGroup(m, "dsm_app.example_app", "dsm_group.other_group"),
+			},
+			{
+				// drift: the app is renamed outside of terraform
+				PreConfig: func() {
+					m.mutate("apps", app_id, func(app map[string]interface{}) {
+						app["name"] = "renamed_in_dsm"
+						app["description"] = "changed in dsm"
+					})
+				},
+				Config: testUnitAppConfig("example_app_updated", "updated by terraform", false, ""),
+				Check: testUnitCheckMock(m, "apps", "dsm_app.example_app", func(app map[string]interface{}) error {
+					if app["name"] != "example_app_updated" || app["description"] != "updated by terraform" {
+						return fmt.Errorf("drift was not corrected: %v", app)
+					}
+					return nil
+				}),
+			},
+			{
+				Config: testUnitAppConfig("example_app_updated", "updated by terraform", false, "new_credential = true"),
+				Check: func(s *terraform.State) error {
+					if s.RootModule().Resources["dsm_app.example_app"].Primary.Attributes["credential"] == credential {
+						return fmt.Errorf("the API key was not rotated")
+					}
+					return nil
+				},
+				// new_credential is a trigger: it is read back as false and
+				// rotates the API key on every apply while it is set
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// drift: the app is deleted outside of terraform
+				PreConfig: func() { m.remove("apps", app_id) },
+				Config:    testUnitAppConfig("example_app_updated", "updated by terraform", false, ""),
+				Check: testUnitCheckMock(m, "apps", "dsm_app.example_app", func(app map[string]interface{}) error {
+					if app["app_id"] == app_id {
+						return fmt.Errorf("app %s was not created again", app_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
 }
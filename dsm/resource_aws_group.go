@@ -10,7 +10,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -118,7 +117,7 @@ func resourceCreateAWSGroup(ctx context.Context, d *schema.ResourceData, m inter
 		group_object["add_hmg"].([]map[string]interface{})[0]["secret_key"] = secret_key.(string)
 	}
 
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/groups", group_object)
+	req, err := m.(*api_client).APICallBody(ctx, "POST", "sys/v1/groups", group_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -136,59 +135,44 @@ func resourceCreateAWSGroup(ctx context.Context, d *schema.ResourceData, m inter
 func resourceReadAWSGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 404 {
+	group, err := m.(*api_client).API().GetGroup(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %v", err),
-			})
-			return diags
-		} else {
-			// Convert returned call into AWSGroup Map
-			jsonbody, err := json.Marshal(req)
-			if err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-					Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/groups: %s", err),
-				})
-				return diags
-			}
-
-			awsgroup := AWSGroup{}
-			if err := json.Unmarshal(jsonbody, &awsgroup); err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-					Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/groups: %s", err),
-				})
-				return diags
-			}
-			// FYOO: AWSGroup must conform to this JSON struct - if this crashes, then we have DSM issues
-			name := strings.Split(awsgroup.Name, fmt.Sprintf("-aws-%s", m.(*api_client).aws_region))
-			d.Set("name", name[0])
-			d.Set("group_id", awsgroup.Group_id)
-			d.Set("acct_id", awsgroup.Acct_id)
-			var creatorInt map[string]interface{}
-			creatorRec, _ := json.Marshal(awsgroup.Creator)
-			json.Unmarshal(creatorRec, &creatorInt)
-			d.Set("creator", creatorInt)
-			d.Set("region", m.(*api_client).aws_region)
-			// FYOO: there is only one HMG per AWSGroup
-			for _, value := range awsgroup.Hmg {
-				d.Set("access_key", value.Access_key)
-			}
-			// FYOO: remove sensitive information
-			d.Set("secret_key", "")
-			// FYOO: if description is blank, DSM does not return
-			if _, ok := req["description"]; ok {
-				d.Set("description", req["description"].(string))
-			}
-		}
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %v", err),
+		})
+		return diags
+	}
+	awsgroup, err := group.AWS()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %s", err),
+		})
+		return diags
+	}
+	// FYOO: AWSGroup must conform to this JSON struct - if this crashes, then we have DSM issues
+	name := strings.Split(awsgroup.Name, fmt.Sprintf("-aws-%s", m.(*api_client).aws_region))
+	d.Set("name", name[0])
+	d.Set("group_id", awsgroup.Group_id)
+	d.Set("acct_id", awsgroup.Acct_id)
+	d.Set("creator", awsgroup.Creator.Map())
+	d.Set("region", m.(*api_client).aws_region)
+	// FYOO: there is only one HMG per AWSGroup
+	for _, value := range awsgroup.Hmg {
+		d.Set("access_key", value.Access_key)
+	}
+	// FYOO: remove sensitive information
+	d.Set("secret_key", "")
+	// FYOO: if description is blank, DSM does not return
+	if group.Description != nil {
+		d.Set("description", *group.Description)
 	}
 	return diags
 }
@@ -204,8 +188,8 @@ func resourceUpdateAWSGroup(ctx context.Context, d *schema.ResourceData, m inter
 func resourceDeleteAWSGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) && (statuscode != 400) {
+	_, err := m.(*api_client).APICall(ctx, "DELETE", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
+	if (err != nil) && !IsNotFound(err) && !IsBadRequest(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -213,7 +197,7 @@ func resourceDeleteAWSGroup(ctx context.Context, d *schema.ResourceData, m inter
 		})
 		return diags
 	} else {
-		if statuscode == 400 {
+		if IsBadRequest(err) {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Call to DSM provider API client failed",
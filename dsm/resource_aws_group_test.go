@@ -1,11 +1,10 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"fmt"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
@@ -26,7 +25,7 @@ func TestAccResourceAwsGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroyAwsGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             resourceAwsGroup_createConfig,
@@ -36,6 +35,56 @@ func TestAccResourceAwsGroup(t *testing.T) {
 	})
 }
 
-func testAccCheckDestroyAwsGroup(s *terraform.State) (err error) {
-	return err
+func TestUnitResourceAwsGroup(t *testing.T) {
+	m := newMockDSM(t)
+	var group_id string
+
+	config := `
+resource "dsm_aws_group" "example_aws_group" {
+  name        = "example_aws_group"
+  description = "AWS Group Test"
+  access_key  = "AKIAEXAMPLE"
+}
+`
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&group_id, "dsm_aws_group.example_aws_group"),
+					resource.TestCheckResourceAttr("dsm_aws_group.example_aws_group", "region", "us-east-1"),
+					resource.TestCheckResourceAttr("dsm_aws_group.example_aws_group", "access_key", "AKIAEXAMPLE"),
+					resource.TestCheckResourceAttr("dsm_aws_group.example_aws_group", "acct_id", m.acct_id),
+					testUnitCheckMock(m, "groups", "dsm_aws_group.example_aws_group", func(group map[string]interface{}) error {
+						for _, config := range group["hmg"].(map[string]interface{}) {
+							hmg := config.(map[string]interface{})
+							if hmg["kind"] != "AWSKMS" || hmg["url"] != "kms.us-east-1.amazonaws.com" {
+								return fmt.Errorf("unexpected hmg: %v", hmg)
+							}
+							return nil
+						}
+						return fmt.Errorf("group has no hmg: %v", group)
+					}),
+				),
+			},
+			{
+				ResourceName:      "dsm_aws_group.example_aws_group",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// drift: the group is deleted outside of terraform
+				PreConfig: func() { m.remove("groups", group_id) },
+				Config:    config,
+				Check: testUnitCheckMock(m, "groups", "dsm_aws_group.example_aws_group", func(group map[string]interface{}) error {
+					if group["group_id"] == group_id {
+						return fmt.Errorf("group %s was not created again", group_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
 }
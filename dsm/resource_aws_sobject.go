@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -35,11 +36,12 @@ var aws_sobject_lock sync.Mutex
 
 // [-] Define AWS Security Object in Terraform
 func resourceAWSSobject() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateAWSSobject,
 		ReadContext:   resourceReadAWSSobject,
 		UpdateContext: resourceUpdateAWSSobject,
 		DeleteContext: resourceDeleteAWSSobject,
+		CustomizeDiff: sobjectRotationCustomizeDiff("replaced", "external"),
 		Description: "Creates a new security object in AWS KMS. This is a Bring-Your-Own-Key (BYOK) method and copies an existing DSM local security object to AWS KMS as a Customer Managed Key (CMK).The returned resource object contains the UUID of the security object for further references.\n" +
 		"AWS security object can also rotate and enable scheduled deletion. For more examples, refer Guides/dsm_aws_sobject, Guides/rotate_with_AWS_option and rotate_with_DSM_option.\n\n" +
 		"**Temporary Credentials**: AWS security object can also be created using AWS temporary credentials. Please refer the below example for temporary credentials.\n\n" +
@@ -53,6 +55,7 @@ func resourceAWSSobject() *schema.Resource {
 		"   * To know whether it is in a destroyed state or not, sync keys operation should be performed.\n" +
 		"   * Use `dsm_aws_group` data_source to sync the keys. Please refer `Data Sources/dsm_aws_group`.\n\n" +
 		"**Note**: `delete_key_material` can be skipped if `schedule_deletion` is enabled as it deletes the key material as well.",
+		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 			"name": {
 			    Description: "The security object name.",
@@ -120,19 +123,9 @@ func resourceAWSSobject() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-			"rotation_policy": {
-				Description: "Policy to rotate a Security Object, configure the below parameters.\n" +
-				"   * `interval_days`: Rotate the key for every given number of days.\n" +
-				"   * `interval_months`: Rotate the key for every given number of months.\n" +
-				"   * `effective_at`: Start of the rotation policy time.\n" +
+			"rotation_policy": rotationPolicySchema("Policy to rotate a Security Object.\n" +
 				"   * **Note:** Either interval_days or interval_months should be given, but not both.\n" +
-				"   * **Note:** Please refer Guides/dsm_aws_sobject for an example.",
-				Type:     schema.TypeMap,
-				Optional: true,
-				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-				},
-			},
+				"   * **Note:** Please refer Guides/dsm_aws_sobject for an example."),
 			"custom_metadata": {
 			    Description: "AWS KMS key level metadata information.\n" +
 			    "   * `aws-aliases`: Key name within AWS KMS.\n" +
@@ -141,6 +134,7 @@ func resourceAWSSobject() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 				Computed: true,
+				DiffSuppressFunc: jsonMapDiffSuppress("aws-policy"),
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -229,6 +223,40 @@ func resourceAWSSobject() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"rotation_triggers": {
+				Description: "Arbitrary map of values that, when changed, rotates the security object in place: DSM rekeys it and the resource moves to the new key.\n" +
+				"   * Setting the first triggers does not rotate the security object.",
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"rotate_after_days": {
+				Description: "Rotates the security object in place when it is older than the given number of days at plan time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"previous_kid": {
+				Description: "The security object ID that the last in-place rotation replaced.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rotated_kids": {
+				Description: "The security object IDs that in-place rotations replaced, oldest first.\n" +
+				"   * delete_key_material and schedule_deletion apply to them as well, and they are deleted with the security object once destroyed.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"created_at": {
+				Description: "The creation date of the security object in RFC format.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"schedule_deletion": {
 				Description: "Schedule key deletion in AWS KMS. Key is not usable for Sign/Verify, Wrap/Unwrap or Encrypt/Decrypt operations once it is deleted. Minimum value is 7 days.\n" +
 				"**Note:** This can enabled only after creation.",
@@ -247,7 +275,12 @@ func resourceAWSSobject() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
 	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		rotationPolicyStateUpgrader(resource),
+	}
+	return resource
 }
 
 // [C]: Create AWS Security Object
@@ -288,8 +321,12 @@ func resourceCreateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 	if err := d.Get("custom_metadata").(map[string]interface{}); len(err) > 0 {
 		security_object["custom_metadata"] = d.Get("custom_metadata")
 	}
-	if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-		security_object["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+	rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+	if date_error != nil {
+		return date_error
+	}
+	if len(rotation_policy) > 0 {
+		security_object["rotation_policy"] = rotation_policy
 	}
 
 	// FYOO: Get tags
@@ -309,160 +346,137 @@ func resourceCreateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 
-	req, err := invokeAWSCreateAPI(m, security_object, endpoint)
-	if err != nil {
-	    return err
+	req, diags := invokeAWSCreateAPI(ctx, m, security_object, endpoint, approvalTimeout(d))
+	if diags.HasError() {
+	    return diags
 	}
 
 	d.SetId(req["kid"].(string))
-	return resourceReadAWSSobject(ctx, d, m)
+	return append(diags, resourceReadAWSSobject(ctx, d, m)...)
 }
 
 // [R]: Read AWS Security Object
 func resourceReadAWSSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("crypto/v1/keys/%s?show_destroyed=true&show_deleted=true", d.Id()))
-	if statuscode == 404 {
+	sobject, err := m.(*api_client).API().GetKeyAnyState(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
-			})
-			return diags
-		}
-
-		// Convert returned call into AWSSobject Map
-		jsonbody, err := json.Marshal(req)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
-
-		awssobject := AWSSobject{}
-		if err := json.Unmarshal(jsonbody, &awssobject); err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
+		})
+		return diags
+	}
+	awssobject, err := sobject.AWS()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
+		})
+		return diags
+	}
 
-		// Sync DSM and Terraform attributes
-		if err := d.Set("dsm_name", awssobject.Name); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("group_id", req["group_id"].(string)); err != nil {
+	// Sync DSM and Terraform attributes
+	if err := d.Set("dsm_name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if diags := setSobjectLinksTfState(d, sobject.Links); diags != nil {
+		return diags
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	tfstate_custom_metadata := d.Get("custom_metadata").(map[string]interface{})
+	if len(tfstate_custom_metadata) > 0 {
+		if err := d.Set("custom_metadata", tfstate_custom_metadata); err != nil {
 			return diag.FromErr(err)
 		}
-		empty_array := []string{}
-		if _, ok := req["links"]; ok {
-			if links := req["links"].(map[string]interface{}); len(links) > 0 {
-				if _, copiedToExists := req["links"].(map[string]interface{})["copiedTo"]; copiedToExists {
-					if err := d.Set("copied_to", req["links"].(map[string]interface{})["copiedTo"].([]interface{})); err != nil {
-						return diag.FromErr(err)
-					}
-				} else {
-				    d.Set("copied_to", empty_array)
-				}
-				if _, copiedFromExists := req["links"].(map[string]interface{})["copiedFrom"]; copiedFromExists {
-					if err := d.Set("copied_from", req["links"].(map[string]interface{})["copiedFrom"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacementExists := req["links"].(map[string]interface{})["replacement"]; replacementExists {
-					if err := d.Set("replacement", req["links"].(map[string]interface{})["replacement"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacedExists := req["links"].(map[string]interface{})["replaced"]; replacedExists {
-					if err := d.Set("replaced", req["links"].(map[string]interface{})["replaced"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-			}
-		}
-		if err := d.Set("kid", req["kid"].(string)); err != nil {
+	} else {
+		if err := d.Set("custom_metadata", sobject.Custom_metadata); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
-			return diag.FromErr(err)
+	}
+
+	external := &TFAWSSobjectExternal{
+		Key_arn:           awssobject.External.Id.Key_arn,
+		Key_id:            awssobject.External.Id.Key_id,
+		Key_state:         awssobject.Custom_metadata.Aws_key_state,
+		Key_aliases:       awssobject.Custom_metadata.Aws_aliases,
+		Key_deletion_date: awssobject.Custom_metadata.Aws_deletion_date,
+		Key_policy:        awssobject.Custom_metadata.Aws_key_policy,
+	}
+	var externalInt map[string]interface{}
+	externalRec, _ := json.Marshal(external)
+	json.Unmarshal(externalRec, &externalInt)
+	if err := d.Set("external", externalInt); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Key_ops != nil {
+		if err := setKeyOpsTfState(d, stringList(sobject.Key_ops)); err != nil {
+			return err
 		}
-		if err := d.Set("creator", req["creator"]); err != nil {
+	}
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		tfstate_custom_metadata := d.Get("custom_metadata").(map[string]interface{})
-		if len(tfstate_custom_metadata) > 0 {
-			if err := d.Set("custom_metadata", tfstate_custom_metadata); err != nil {
-				return diag.FromErr(err)
-			}
-		} else {
-			if err := d.Set("custom_metadata", req["custom_metadata"]); err != nil {
-				return diag.FromErr(err)
-			}
-		}
-
-		external := &TFAWSSobjectExternal{
-			Key_arn:           awssobject.External.Id.Key_arn,
-			Key_id:            awssobject.External.Id.Key_id,
-			Key_state:         awssobject.Custom_metadata.Aws_key_state,
-			Key_aliases:       awssobject.Custom_metadata.Aws_aliases,
-			Key_deletion_date: awssobject.Custom_metadata.Aws_deletion_date,
-			Key_policy:        awssobject.Custom_metadata.Aws_key_policy,
+	}
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", sobject.State); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(sobject.Created_at) > 0 {
+		sobj_created_at, date_error := parseTimeFromDSM(sobject.Created_at)
+		if date_error != nil {
+			return date_error
 		}
-		var externalInt map[string]interface{}
-		externalRec, _ := json.Marshal(external)
-		json.Unmarshal(externalRec, &externalInt)
-		if err := d.Set("external", externalInt); err != nil {
+		if err := d.Set("created_at", sobj_created_at); err != nil {
 			return diag.FromErr(err)
 		}
-		if key_ops_read, ok := req["key_ops"]; ok {
-		    if err := setKeyOpsTfState(d, key_ops_read); err != nil {
-                return err
-            }
+	}
+	// a state written before rotated_kids only knows the previous_kid
+	if err := d.Set("rotated_kids", rotatedKids(d)); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Deactivation_date != nil {
+		// FYOO: once it's set, you can't remove deactivation date
+		sobj_deactivation_date, date_error := parseTimeFromDSM(*sobject.Deactivation_date)
+		if date_error != nil {
+			return date_error
 		}
-		if _, ok := req["description"]; ok {
-			if err := d.Set("description", req["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+		if newerr := d.Set("expiry_date", sobj_deactivation_date); newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
-			return diag.FromErr(err)
+	}
+	if sobject.Rotation_policy != nil {
+		rotation_policy, date_error := rotationPolicyRead(sobject.Rotation_policy)
+		if date_error != nil {
+			return date_error
 		}
-		if err := d.Set("state", req["state"].(string)); err != nil {
+		if err := d.Set("rotation_policy", rotation_policy); err != nil {
 			return diag.FromErr(err)
 		}
-		if rfcdate, ok := req["deactivation_date"]; ok {
-			// FYOO: once it's set, you can't remove deactivation date
-			sobj_deactivation_date, date_error := parseTimeFromDSM(rfcdate.(string))
-			if date_error != nil {
-				return date_error
-			}
-			if newerr := d.Set("expiry_date", sobj_deactivation_date); newerr != nil {
-				return diag.FromErr(newerr)
-			}
-		}
-		if _, ok := req["rotation_policy"]; ok {
-			rotation_policy := sobj_rotation_policy_read(req["rotation_policy"].(map[string]interface{}))
-			if _, ok := rotation_policy["deactivate_rotated_key"]; ok {
-				delete(rotation_policy, "deactivate_rotated_key")
-			}
-			if err := d.Set("rotation_policy", rotation_policy); err != nil {
-				return diag.FromErr(err)
-			}
-		}
-		// FYOO: clear values that are irrelevant
-		d.Set("rotate", "")
-		d.Set("rotate_from", "")
 	}
+	// FYOO: clear values that are irrelevant
+	d.Set("rotate", "")
+	d.Set("rotate_from", "")
 
 	return diags
 }
@@ -473,10 +487,18 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
     if d.HasChange("key") {
         return undoTFstate("key", d)
     }
+	// rotate in place first, the other changes apply to the new key
+	aws_sobject_lock.Lock()
+	_, rekey_diags := rekeySobject(ctx, d, m)
+	aws_sobject_lock.Unlock()
+	if rekey_diags.HasError() {
+		return rekey_diags
+	}
+	diags = append(diags, rekey_diags...)
 	if d.HasChange("delete_key_material") && d.Get("delete_key_material").(bool){
 		current_key_state := d.Get("external").(map[string]interface{})["Key_state"]
 		if current_key_state != "PendingDeletion" && current_key_state != "PendingImport"{
-			err := deleteKeyMateialBYOKSobject(d, m)
+			err := deleteKeyMateialBYOKSobject(ctx, d, m)
 			if err != nil {
 				d.Set("delete_key_material", nil)
 				// When delete_key_material fails and schedule_deletion is enabled, then schedule_deletion needs to revert
@@ -486,6 +508,9 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 				}
 				return err
 			}
+			if rotated_diags := rotatedBYOKSobjectsAction(ctx, d, m, m.(*api_client).API().DeleteKeyMaterial, "aws-key-state", "PendingDeletion", "PendingImport"); rotated_diags.HasError() {
+				return rotated_diags
+			}
 			if !d.HasChange("schedule_deletion") {
 				return resourceReadAWSSobject(ctx, d, m)
 			}
@@ -500,12 +525,19 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 				"pending_window_in_days": pending_window_in_days,
 			}
 			if d.Get("external").(map[string]interface{})["Key_state"] != "PendingDeletion" {
-				_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("crypto/v1/keys/%s/schedule_deletion", d.Id()), schedule_deletion)
+				_, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", fmt.Sprintf("crypto/v1/keys/%s/schedule_deletion", d.Id()), schedule_deletion, d.Timeout(schema.TimeoutUpdate))
+				diags = append(diags, approval_diags...)
 				if err != nil {
 					d.Set("schedule_deletion", nil)
-					return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s/schedule_deletion, %v", d.Id(), err))
+					return append(diags, invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s/schedule_deletion, %v", d.Id(), err))...)
 				}
-				return resourceReadAWSSobject(ctx, d, m)
+				schedule := func(ctx context.Context, kid string) error {
+					return m.(*api_client).API().ScheduleKeyDeletion(ctx, kid, schedule_deletion)
+				}
+				if rotated_diags := rotatedBYOKSobjectsAction(ctx, d, m, schedule, "aws-key-state", "PendingDeletion"); rotated_diags.HasError() {
+					return append(diags, rotated_diags...)
+				}
+				return append(diags, resourceReadAWSSobject(ctx, d, m)...)
 			} else {
 			    // If the state is already in PendingDeletion, then no need to invoke schedule_deletion API and show a warning.
 				return showWarning("The security object is already scheduled for the deletion.")
@@ -593,8 +625,12 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 		has_change = true
 	}
 	if d.HasChange("rotation_policy") {
-		if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-			update_aws_sobject["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+		rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+		if date_error != nil {
+			return date_error
+		}
+		if len(rotation_policy) > 0 {
+			update_aws_sobject["rotation_policy"] = rotation_policy
 			has_change = true
 		}
 	}
@@ -604,7 +640,8 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 	}
 
 	if has_change {
-		_, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_aws_sobject)
+		_, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_aws_sobject, d.Timeout(schema.TimeoutUpdate))
+		diags = append(diags, approval_diags...)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -617,7 +654,7 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 
-	return resourceReadAWSSobject(ctx, d, m)
+	return append(diags, resourceReadAWSSobject(ctx, d, m)...)
 }
 
 // [D]: Delete AWS Security Object
@@ -625,19 +662,23 @@ func resourceUpdateAWSSobject(ctx context.Context, d *schema.ResourceData, m int
 // It will give an error.
 func resourceDeleteAWSSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	resourceReadAWSSobject(ctx, d, m)
-	return deleteBYOKDestroyedSobject(d, m)
+	if rotated_diags := deleteRotatedSobjects(ctx, d, m); rotated_diags.HasError() {
+		return rotated_diags
+	}
+	return deleteBYOKDestroyedSobject(ctx, d, m)
 }
 
 
 // This function is to call an DSM AWS BYOK copy API and to acquire the lock.
-// Lock is needed as AWS made changes in AWS API limit.
-func invokeAWSCreateAPI(m interface{}, aws_sobject map[string]interface{}, endpoint string) (map[string]interface{}, diag.Diagnostics) {
+// Lock is needed as AWS made changes in AWS API limit. The copy waits for
+// quorum approval up to timeout when DSM requires it.
+func invokeAWSCreateAPI(ctx context.Context, m interface{}, aws_sobject map[string]interface{}, endpoint string, timeout time.Duration) (map[string]interface{}, diag.Diagnostics) {
 	aws_sobject_lock.Lock()
-	req, err := m.(*api_client).APICallBody("POST", endpoint, aws_sobject)
+	req, diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", endpoint, aws_sobject, timeout)
 	// Irrespective of the creation status, lock will be released.
 	aws_sobject_lock.Unlock()
 	if err != nil {
-		return nil, invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err))
+		return nil, append(diags, invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err))...)
 	}
-	return req, nil
+	return req, diags
 }
\ No newline at end of file
@@ -1,13 +1,13 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var (
@@ -60,7 +60,7 @@ func TestAccResourceAwsSobject(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheckAws(t) },
-		CheckDestroy: testAccCheckDestroyAwsSobject,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             fmt.Sprintf(resourceAwsSobject_createConfig, aws_access_key, aws_secret_key),
@@ -70,6 +70,212 @@ func TestAccResourceAwsSobject(t *testing.T) {
 	})
 }
 
-func testAccCheckDestroyAwsSobject(s *terraform.State) (err error) {
-	return err
+// dsm_aws_sobject copied from an exportable AES key, with the given update attributes
+func testUnitAwsSobjectConfig(description string, extra string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_sobject" {
+  name     = "example_sobject"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "AES"
+  key_size = 256
+  key_ops  = ["ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE", "EXPORT"]
+}
+
+resource "dsm_aws_group" "example_aws_group" {
+  name       = "example_aws_group"
+  access_key = "AKIAEXAMPLE"
+}
+
+resource "dsm_aws_sobject" "example_aws_sobject" {
+  name        = "example_aws_sobject"
+  group_id    = dsm_aws_group.example_aws_group.group_id
+  description = %q
+  key = {
+    kid = dsm_sobject.example_sobject.kid
+  }
+  custom_metadata = {
+    aws-aliases = "example_aws_sobject"
+  }
+  %s
+}
+`, description, extra)
+}
+
+func TestUnitResourceAwsSobject(t *testing.T) {
+	m := newMockDSM(t)
+	var kid, rotated_kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// the rotated key is deleted with the security object, or the group could not be deleted
+		CheckDestroy: func(s *terraform.State) error {
+			if m.get("keys", rotated_kid) != nil {
+				return fmt.Errorf("rotated security object %s was not deleted", rotated_kid)
+			}
+			return testAccCheckDestroy(s)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAwsSobjectConfig("created by terraform", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_aws_sobject.example_aws_sobject"),
+					resource.TestCheckResourceAttrPair("dsm_aws_sobject.example_aws_sobject", "copied_from", "dsm_sobject.example_sobject", "kid"),
+					resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "state", "Active"),
+					resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "external.Key_state", "Enabled"),
+					resource.TestCheckResourceAttrSet("dsm_aws_sobject.example_aws_sobject", "external.Key_arn"),
+				),
+			},
+			{
+				ResourceName:      "dsm_aws_sobject.example_aws_sobject",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the source key is not read back and DSM adds aws-* metadata
+				ImportStateVerifyIgnore: []string{"name", "key", "custom_metadata"},
+			},
+			{
+				Config: testUnitAwsSobjectConfig("updated by terraform", ""),
+				Check: testUnitCheckMock(m, "keys", "dsm_aws_sobject.example_aws_sobject", func(key map[string]interface{}) error {
+					if key["description"] != "updated by terraform" {
+						return fmt.Errorf("key was not updated: %v", key)
+					}
+					return nil
+				}),
+			},
+			{
+				Config: testUnitAwsSobjectConfig("updated by terraform", `rotation_triggers = { version = "1" }`),
+				Check:  resource.TestCheckResourceAttrPtr("dsm_aws_sobject.example_aws_sobject", "kid", &kid),
+			},
+			{
+				// a changed trigger rekeys the AWS key in place
+				Config: testUnitAwsSobjectConfig("updated by terraform", `rotation_triggers = { version = "2" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("dsm_aws_sobject.example_aws_sobject", "previous_kid", &kid),
+					resource.TestCheckResourceAttrPtr("dsm_aws_sobject.example_aws_sobject", "replaced", &kid),
+					resource.TestCheckResourceAttrPtr("dsm_aws_sobject.example_aws_sobject", "rotated_kids.0", &kid),
+					func(*terraform.State) error {
+						rotated_kid = kid
+						return nil
+					},
+					testUnitID(&kid, "dsm_aws_sobject.example_aws_sobject"),
+					resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "external.Key_state", "Enabled"),
+				),
+			},
+			{
+				Config: testUnitAwsSobjectConfig("updated by terraform", "rotation_triggers = { version = \"2\" }\n  delete_key_material = true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "external.Key_state", "PendingImport"),
+					testUnitCheckRotatedMock(m, &rotated_kid, "aws-key-state", "PendingImport"),
+				),
+			},
+			{
+				Config: testUnitAwsSobjectConfig("updated by terraform", "rotation_triggers = { version = \"2\" }\n  delete_key_material = true\n  schedule_deletion = 7"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "external.Key_state", "PendingDeletion"),
+					resource.TestCheckResourceAttrSet("dsm_aws_sobject.example_aws_sobject", "external.Key_deletion_date"),
+					testUnitCheckRotatedMock(m, &rotated_kid, "aws-key-state", "PendingDeletion"),
+				),
+			},
+			{
+				// the pending window ends: only a destroyed key can be deleted
+				PreConfig: func() {
+					m.elapsePendingDeletion(kid)
+					m.elapsePendingDeletion(rotated_kid)
+				},
+				Config:    testUnitAwsSobjectConfig("updated by terraform", "rotation_triggers = { version = \"2\" }\n  delete_key_material = true\n  schedule_deletion = 7"),
+				Check:     resource.TestCheckResourceAttr("dsm_aws_sobject.example_aws_sobject", "state", "Destroyed"),
+			},
+		},
+	})
+}
+
+// The copy, the update and the deletion schedule of a key in an AWS group
+// with a quorum policy wait for the approval of the quorum.
+func TestUnitResourceAwsSobjectApproval(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	group_id := m.seedGroup("quorum_aws_group", map[string]interface{}{
+		"approval_policy": m.quorumPolicy(),
+		"add_hmg": []interface{}{
+			map[string]interface{}{"kind": "AWSKMS", "url": "kms.us-east-1.amazonaws.com", "access_key": "AKIAEXAMPLE"},
+		},
+	})
+	config := func(description string, extra string) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_sobject" {
+  name     = "example_sobject"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "AES"
+  key_size = 256
+  key_ops  = ["ENCRYPT", "DECRYPT", "APPMANAGEABLE", "EXPORT"]
+}
+
+resource "dsm_aws_sobject" "quorum_aws_sobject" {
+  name        = "quorum_aws_sobject"
+  group_id    = %q
+  description = %q
+  key = {
+    kid = dsm_sobject.example_sobject.kid
+  }
+  custom_metadata = {
+    aws-aliases = "quorum_aws_sobject"
+  }
+  %s
+}
+`, group_id, description, extra)
+	}
+	var kid string
+	approved := func(method string, operation func() string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			request := m.objects["approval_requests"].find(func(request map[string]interface{}) bool {
+				return request["method"] == method && request["operation"] == operation() && request["status"] == "RESULT_FETCHED"
+			})
+			if request == nil {
+				return fmt.Errorf("%s %s did not wait for the quorum", method, operation())
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("created by terraform", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_aws_sobject.quorum_aws_sobject"),
+					resource.TestCheckResourceAttr("dsm_aws_sobject.quorum_aws_sobject", "external.Key_state", "Enabled"),
+					approved("POST", func() string { return "crypto/v1/keys/copy" }),
+				),
+			},
+			{
+				Config: config("updated by terraform", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_aws_sobject.quorum_aws_sobject", "description", "updated by terraform"),
+					approved("PATCH", func() string { return "crypto/v1/keys/" + kid }),
+				),
+			},
+			{
+				Config: config("updated by terraform", "schedule_deletion = 7"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_aws_sobject.quorum_aws_sobject", "external.Key_state", "PendingDeletion"),
+					approved("POST", func() string { return "crypto/v1/keys/" + kid + "/schedule_deletion" }),
+				),
+			},
+			{
+				// the pending window ends: only a destroyed key can be deleted
+				PreConfig: func() { m.elapsePendingDeletion(kid) },
+				Config:    config("updated by terraform", "schedule_deletion = 7"),
+				Check:     resource.TestCheckResourceAttr("dsm_aws_sobject.quorum_aws_sobject", "state", "Destroyed"),
+			},
+		},
+	})
 }
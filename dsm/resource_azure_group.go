@@ -10,7 +10,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -129,7 +128,7 @@ func resourceCreateAzureGroup(ctx context.Context, d *schema.ResourceData, m int
 		},
 	}
 
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/groups", group_object)
+	req, err := m.(*api_client).APICallBody(ctx, "POST", "sys/v1/groups", group_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -147,63 +146,48 @@ func resourceCreateAzureGroup(ctx context.Context, d *schema.ResourceData, m int
 func resourceReadAzureGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 404 {
+	group, err := m.(*api_client).API().GetGroup(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %v", err),
-			})
-			return diags
-		} else {
-			// Convert returned call into AWSGroup Map
-			jsonbody, err := json.Marshal(req)
-			if err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-					Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/groups: %s", err),
-				})
-				return diags
-			}
-
-			azuregroup := AzureGroup{}
-			if err := json.Unmarshal(jsonbody, &azuregroup); err != nil {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-					Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/groups: %s", err),
-				})
-				return diags
-			}
-			// FYOO: AzureGroup must conform to this JSON struct - if this crashes, then we have DSM issues
-			name := strings.Split(azuregroup.Name, fmt.Sprintf("-azure-%s", m.(*api_client).azure_region))
-			d.Set("name", name[0])
-			d.Set("group_id", azuregroup.Group_id)
-			d.Set("acct_id", azuregroup.Acct_id)
-			var creatorInt map[string]interface{}
-			creatorRec, _ := json.Marshal(azuregroup.Creator)
-			json.Unmarshal(creatorRec, &creatorInt)
-			d.Set("creator", creatorInt)
-			d.Set("region", m.(*api_client).azure_region)
-			// FYOO: there is only one HMG per AzureGroup
-			for _, value := range azuregroup.Hmg {
-				d.Set("subscription_id", value.Subscription_id)
-				d.Set("client_id", value.Client_id)
-				d.Set("tenant_id", value.Tenant_id)
-				d.Set("key_vault_type", value.Key_vault_type)
-				d.Set("url", value.Url)
-			}
-			// FYOO: remove sensitive information
-			d.Set("secret_key", "")
-			// FYOO: if description is blank, DSM does not return
-			if _, ok := req["description"]; ok {
-				d.Set("description", req["description"].(string))
-			}
-		}
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %v", err),
+		})
+		return diags
+	}
+	azuregroup, err := group.Azure()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups: %s", err),
+		})
+		return diags
+	}
+	// FYOO: AzureGroup must conform to this JSON struct - if this crashes, then we have DSM issues
+	name := strings.Split(azuregroup.Name, fmt.Sprintf("-azure-%s", m.(*api_client).azure_region))
+	d.Set("name", name[0])
+	d.Set("group_id", azuregroup.Group_id)
+	d.Set("acct_id", azuregroup.Acct_id)
+	d.Set("creator", azuregroup.Creator.Map())
+	d.Set("region", m.(*api_client).azure_region)
+	// FYOO: there is only one HMG per AzureGroup
+	for _, value := range azuregroup.Hmg {
+		d.Set("subscription_id", value.Subscription_id)
+		d.Set("client_id", value.Client_id)
+		d.Set("tenant_id", value.Tenant_id)
+		d.Set("key_vault_type", value.Key_vault_type)
+		d.Set("url", value.Url)
+	}
+	// FYOO: remove sensitive information
+	d.Set("secret_key", "")
+	// FYOO: if description is blank, DSM does not return
+	if group.Description != nil {
+		d.Set("description", *group.Description)
 	}
 	return diags
 }
@@ -219,8 +203,8 @@ func resourceUpdateAzureGroup(ctx context.Context, d *schema.ResourceData, m int
 func resourceDeleteAzureGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) && (statuscode != 400) {
+	_, err := m.(*api_client).APICall(ctx, "DELETE", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
+	if (err != nil) && !IsNotFound(err) && !IsBadRequest(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -228,7 +212,7 @@ func resourceDeleteAzureGroup(ctx context.Context, d *schema.ResourceData, m int
 		})
 		return diags
 	} else {
-		if statuscode == 400 {
+		if IsBadRequest(err) {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Call to DSM provider API client failed",
@@ -1,13 +1,11 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
@@ -36,7 +34,7 @@ func TestAccResourceAzureGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheckAzure(t) },
-		CheckDestroy: testAccCheckDestroyAzureGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             fmt.Sprintf(resourceAzureGroup_createConfig, azure_tenant_id, azure_secret_key, azure_subscription_id, azure_client_id, azure_url),
@@ -46,6 +44,54 @@ func TestAccResourceAzureGroup(t *testing.T) {
 	})
 }
 
-func testAccCheckDestroyAzureGroup(s *terraform.State) (err error) {
-	return err
+// dsm_azure_group with the given description
+func testUnitAzureGroupConfig(description string) string {
+	return fmt.Sprintf(`
+resource "dsm_azure_group" "example_azure_group" {
+  name            = "example_azure_group"
+  description     = %q
+  url             = "https://example.vault.azure.net"
+  client_id       = "11111111-1111-1111-1111-111111111111"
+  subscription_id = "22222222-2222-2222-2222-222222222222"
+  tenant_id       = "33333333-3333-3333-3333-333333333333"
+  secret_key      = "azure-secret"
+}
+`, description)
+}
+
+func TestUnitResourceAzureGroup(t *testing.T) {
+	m := newMockDSM(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAzureGroupConfig("Azure Group Test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_azure_group.example_azure_group", "url", "https://example.vault.azure.net"),
+					resource.TestCheckResourceAttr("dsm_azure_group.example_azure_group", "key_vault_type", "Standard"),
+					resource.TestCheckResourceAttr("dsm_azure_group.example_azure_group", "acct_id", m.acct_id),
+					testUnitCheckMock(m, "groups", "dsm_azure_group.example_azure_group", func(group map[string]interface{}) error {
+						for _, config := range group["hmg"].(map[string]interface{}) {
+							hmg := config.(map[string]interface{})
+							if hmg["kind"] != "AZUREKEYVAULT" || hmg["secret_key"] != "azure-secret" {
+								return fmt.Errorf("unexpected hmg: %v", hmg)
+							}
+							return nil
+						}
+						return fmt.Errorf("group has no hmg: %v", group)
+					}),
+				),
+				// the secret key is never read back and always plans an update
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				ResourceName:            "dsm_azure_group.example_azure_group",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secret_key"},
+			},
+		},
+	})
 }
@@ -29,11 +29,12 @@ type TFAzureSobjectExternal struct {
 
 // [-] Define Azure Security Object in Terraform
 func resourceAzureSobject() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateAzureSobject,
 		ReadContext:   resourceReadAzureSobject,
 		UpdateContext: resourceUpdateAzureSobject,
 		DeleteContext: resourceDeleteAzureSobject,
+		CustomizeDiff: sobjectRotationCustomizeDiff("links", "external"),
 		Description: "Creates a new security object in Azure key vault. This is a Bring-Your-Own-Key (BYOK) method and copies an existing DSM local security object to Azure KV as a Customer Managed Key (CMK).\n\n" +
 		"Azure sobject can also rotate, enable soft deletion and purge the key. For examples of rotate and soft deletion, refer Guides/dsm_azure_sobject.\n\n" +
 		"**Note**: Once soft deletion is enabled, Azure sobject can't be modified.\n\n" +
@@ -45,6 +46,7 @@ func resourceAzureSobject() *schema.Resource {
 		"   * A dsm_azure_sobject comes to destroyed state when the key is deleted from Azure key vault.\n" +
 		"   * To know whether it is in a destroyed state or not, sync keys operation should be performed.\n" +
 		"   * Use `dsm_azure_group` data_source to sync the keys. Please refer Data `Sources/dsm_azure_group`.",
+		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 			"name": {
 			    Description: "The security object name.",
@@ -97,19 +99,9 @@ func resourceAzureSobject() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-			"rotation_policy": {
-				Description: "Policy to rotate a Security Object, configure the below parameters.\n" +
-				"   * `interval_days`: Rotate the key for every given number of days.\n" +
-				"   * `interval_months`: Rotate the key for every given number of months.\n" +
-				"   * `effective_at`: Start of the rotation policy time.\n" +
-				"   * `deactivate_rotated_key`: Deactivate original key after rotation true/false.\n" +
+			"rotation_policy": rotationPolicySchema("Policy to rotate a Security Object.\n" +
 				"   * **Note:** Either interval_days or interval_months should be given, but not both.",
-				Type:     schema.TypeMap,
-				Optional: true,
-				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-				},
-			},
+				"deactivate_rotated_key"),
 			"custom_metadata": {
 			    Description: "Azure CMK level metadata information.\n" +
 			    "   * `azure-key-name`: Key name within Azure KV.\n" +
@@ -180,6 +172,40 @@ func resourceAzureSobject() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"rotation_triggers": {
+				Description: "Arbitrary map of values that, when changed, rotates the security object in place: DSM rekeys it and the resource moves to the new key.\n" +
+				"   * Setting the first triggers does not rotate the security object.",
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"rotate_after_days": {
+				Description: "Rotates the security object in place when it is older than the given number of days at plan time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"previous_kid": {
+				Description: "The security object ID that the last in-place rotation replaced.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rotated_kids": {
+				Description: "The security object IDs that in-place rotations replaced, oldest first.\n" +
+				"   * soft_deletion and purge_deleted_key apply to them as well, and they are deleted with the security object once purged.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"created_at": {
+				Description: "The creation date of the security object in RFC format.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"soft_deletion": {
 				Description: "Enable soft key deletion in Azure key vault. Key is not usable for Sign/Verify, Wrap/Unwrap or Encrypt/Decrypt operations once it is deleted. The supported values are true/false.\n" +
 				" **Note:**  This should be enabled only after the creation.",
@@ -209,7 +235,12 @@ func resourceAzureSobject() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
 	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		rotationPolicyStateUpgrader(resource),
+	}
+	return resource
 }
 
 // [C]: Create Azure Security Object
@@ -247,8 +278,12 @@ func resourceCreateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 	if err := d.Get("custom_metadata").(map[string]interface{}); len(err) > 0 {
 		security_object["custom_metadata"] = d.Get("custom_metadata")
 	}
-	if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-		security_object["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+	rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+	if date_error != nil {
+		return date_error
+	}
+	if len(rotation_policy) > 0 {
+		security_object["rotation_policy"] = rotation_policy
 	}
 	if rotate := d.Get("rotate").(string); len(rotate) > 0 {
 		security_object["name"] = d.Get("rotate_from").(string)
@@ -256,7 +291,8 @@ func resourceCreateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 			endpoint = "crypto/v1/keys/rekey"
 		}
 	}
-	req, err := m.(*api_client).APICallBody("POST", endpoint, security_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", endpoint, security_object, approvalTimeout(d))
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -267,121 +303,129 @@ func resourceCreateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 	}
 
 	d.SetId(req["kid"].(string))
-	return resourceReadAzureSobject(ctx, d, m)
+	return append(diags, resourceReadAzureSobject(ctx, d, m)...)
 }
 
 // [R]: Read Azure Security Object
 func resourceReadAzureSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("crypto/v1/keys/%s?show_destroyed=true&show_deleted=true", d.Id()))
-	if statuscode == 404 {
+	sobject, err := m.(*api_client).API().GetKeyAnyState(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
-			})
-			return diags
-		}
-
-		// Convert returned call into AWSSobject Map
-		jsonbody, err := json.Marshal(req)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
-
-		azuresobject := AzureSobject{}
-		if err := json.Unmarshal(jsonbody, &azuresobject); err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
+		return nil
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
+		})
+		return diags
+	}
+	azuresobject, err := sobject.Azure()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
+		})
+		return diags
+	}
 
-		// Sync DSM and Terraform attributes
-		if err := d.Set("dsm_name", azuresobject.Name); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("obj_type", req["obj_type"]); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("key_size", req["key_size"]); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("group_id", req["group_id"].(string)); err != nil {
+	// Sync DSM and Terraform attributes
+	if err := d.Set("dsm_name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("obj_type", sobject.Obj_type); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Key_size != nil {
+		if err := d.Set("key_size", *sobject.Key_size); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("links", req["links"]); err != nil {
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Links != nil {
+		if err := d.Set("links", sobject.Links.Map()); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("kid", req["kid"].(string)); err != nil {
-			return diag.FromErr(err)
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("custom_metadata", d.Get("custom_metadata").(map[string]interface{})); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Key_ops != nil {
+		if err := setKeyOpsTfState(d, stringList(sobject.Key_ops)); err != nil {
+			return err
 		}
-		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	}
+	external := &TFAzureSobjectExternal{
+		Version:         azuresobject.External.Id.Version,
+		Azure_key_name:  azuresobject.External.Id.Label,
+		Azure_key_state: azuresobject.Custom_metadata.Azure_key_state,
+		Azure_backup:    azuresobject.Custom_metadata.Azure_backup,
+	}
+	var externalInt map[string]interface{}
+	externalRec, _ := json.Marshal(external)
+	json.Unmarshal(externalRec, &externalInt)
+	if err := d.Set("external", externalInt); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("creator", req["creator"]); err != nil {
-			return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", sobject.State); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(sobject.Created_at) > 0 {
+		sobj_created_at, date_error := parseTimeFromDSM(sobject.Created_at)
+		if date_error != nil {
+			return date_error
 		}
-		if err := d.Set("custom_metadata", d.Get("custom_metadata").(map[string]interface{})); err != nil {
+		if err := d.Set("created_at", sobj_created_at); err != nil {
 			return diag.FromErr(err)
 		}
-		if key_ops_read, ok := req["key_ops"]; ok {
-			if err := setKeyOpsTfState(d, key_ops_read); err != nil {
-				return err
-			}
-		}
-		external := &TFAzureSobjectExternal{
-			Version:           azuresobject.External.Id.Version,
-			Azure_key_name:    azuresobject.External.Id.Label,
-			Azure_key_state:   azuresobject.Custom_metadata.Azure_key_state,
-			Azure_backup:      azuresobject.Custom_metadata.Azure_backup,
-		}
-		var externalInt map[string]interface{}
-		externalRec, _ := json.Marshal(external)
-		json.Unmarshal(externalRec, &externalInt)
-		if err := d.Set("external", externalInt); err != nil {
-			return diag.FromErr(err)
+	}
+	// a state written before rotated_kids only knows the previous_kid
+	if err := d.Set("rotated_kids", rotatedKids(d)); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Deactivation_date != nil {
+		// FYOO: once it's set, you can't remove deactivation date
+		layoutRFC := "2006-01-02T15:04:05Z"
+		layoutDSM := "20060102T150405Z"
+		ddate, newerr := time.Parse(layoutDSM, *sobject.Deactivation_date)
+		if newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if _, ok := req["description"]; ok {
-			if err := d.Set("description", req["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+		if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
-			return diag.FromErr(err)
+	}
+	if sobject.Rotation_policy != nil {
+		rotation_policy, date_error := rotationPolicyRead(sobject.Rotation_policy)
+		if date_error != nil {
+			return date_error
 		}
-		if err := d.Set("state", req["state"].(string)); err != nil {
+		if err := d.Set("rotation_policy", rotation_policy); err != nil {
 			return diag.FromErr(err)
 		}
-		if rfcdate, ok := req["deactivation_date"]; ok {
-			// FYOO: once it's set, you can't remove deactivation date
-			layoutRFC := "2006-01-02T15:04:05Z"
-			layoutDSM := "20060102T150405Z"
-			ddate, newerr := time.Parse(layoutDSM, rfcdate.(string))
-			if newerr != nil {
-				return diag.FromErr(newerr)
-			}
-			if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
-				return diag.FromErr(newerr)
-			}
-		}
-		if _, ok := req["rotation_policy"]; ok {
-			rotation_policy := sobj_rotation_policy_read(req["rotation_policy"].(map[string]interface{}))
-			if err := d.Set("rotation_policy", rotation_policy); err != nil {
-				return diag.FromErr(err)
-			}
-		}
 	}
 
 	return nil
@@ -393,10 +437,17 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 	if d.HasChange("key") {
 		return undoTFstate("key", d)
 	}
+	// rotate in place first, the other changes apply to the new key
+	rekeyed, rekey_diags := rekeySobject(ctx, d, m)
+	if rekey_diags.HasError() {
+		return rekey_diags
+	}
+	diags = append(diags, rekey_diags...)
 	if d.HasChange("soft_deletion") && d.Get("soft_deletion").(bool) {
 		if d.Get("external").(map[string]interface{})["Azure_key_state"] != "deleted" {
 			soft_deletion := map[string]interface{}{}
-			_, err := m.(*api_client).APICallBody("POST", fmt.Sprintf("crypto/v1/keys/%s/schedule_deletion", d.Id()), soft_deletion)
+			_, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", fmt.Sprintf("crypto/v1/keys/%s/schedule_deletion", d.Id()), soft_deletion, d.Timeout(schema.TimeoutUpdate))
+			diags = append(diags, approval_diags...)
 			if err != nil {
 				d.Set("soft_deletion", nil)
 				if d.Get("purge_deleted_key").(bool){
@@ -404,13 +455,19 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 					// If we don't set to nil, user will never be able to purge the key as tf detects no changes.
 					d.Set("purge_deleted_key", nil)
 				}
-				return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s/schedule_deletion, %v", d.Id(), err))
+				return append(diags, invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s/schedule_deletion, %v", d.Id(), err))...)
+			}
+			soft_delete := func(ctx context.Context, kid string) error {
+				return m.(*api_client).API().ScheduleKeyDeletion(ctx, kid, soft_deletion)
+			}
+			if rotated_diags := rotatedBYOKSobjectsAction(ctx, d, m, soft_delete, "azure-key-state", "deleted", "purged"); rotated_diags.HasError() {
+				return append(diags, rotated_diags...)
 			}
 		} else {
 			return showWarning("The security object is already scheduled for the deletion.")
 		}
 		if !d.Get("purge_deleted_key").(bool){
-			return resourceReadAzureSobject(ctx, d, m)
+			return append(diags, resourceReadAzureSobject(ctx, d, m)...)
 		} else {
 			// This is when purge_deleted_key is enabled along with soft_deletion.
 			// When a user has a bunch of azure keys and enables both soft_deletion and purge_deleted_key at a time,
@@ -425,11 +482,14 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 		azure_key_state := d.Get("external").(map[string]interface{})["Azure_key_state"]
 		if azure_key_state != "purged" {
 			if azure_key_state == "deleted" {
-				err := deleteKeyMateialBYOKSobject(d, m)
+				err := deleteKeyMateialBYOKSobject(ctx, d, m)
 				if err != nil {
 					d.Set("purge_deleted_key", nil)
 					return invokeErrorDiagsWithSummary(error_summary, fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s/delete_key_material, %v", d.Id(), err))
 				}
+				if rotated_diags := rotatedBYOKSobjectsAction(ctx, d, m, m.(*api_client).API().DeleteKeyMaterial, "azure-key-state", "purged"); rotated_diags.HasError() {
+					return rotated_diags
+				}
 				return resourceReadAzureSobject(ctx, d, m)
 			}
 			d.Set("purge_deleted_key", nil)
@@ -483,13 +543,18 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 		has_change = true
 	}
 	if d.HasChange("rotation_policy") {
-		if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-			update_azure_sobject["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+		rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+		if date_error != nil {
+			return date_error
+		}
+		if len(rotation_policy) > 0 {
+			update_azure_sobject["rotation_policy"] = rotation_policy
 			has_change = true
 		}
 	}
 	if has_change {
-		_, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_azure_sobject)
+		_, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_azure_sobject, d.Timeout(schema.TimeoutUpdate))
+		diags = append(diags, approval_diags...)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -501,7 +566,10 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 			return diags
 		}
 	}
-	return nil
+	if rekeyed {
+		return append(diags, resourceReadAzureSobject(ctx, d, m)...)
+	}
+	return diags
 }
 
 // [D]: Delete Azure Security Object
@@ -510,5 +578,8 @@ func resourceUpdateAzureSobject(ctx context.Context, d *schema.ResourceData, m i
 func resourceDeleteAzureSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
 	resourceReadAzureSobject(ctx, d, m)
-	return deleteBYOKDestroyedSobject(d, m)
+	if rotated_diags := deleteRotatedSobjects(ctx, d, m); rotated_diags.HasError() {
+		return rotated_diags
+	}
+	return deleteBYOKDestroyedSobject(ctx, d, m)
 }
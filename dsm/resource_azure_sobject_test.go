@@ -1,13 +1,13 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var (
@@ -62,7 +62,7 @@ func TestAccResourceAzureSobject(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheckAzure(t) },
-		CheckDestroy: testAccCheckDestroyAzureSobject,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config:             fmt.Sprintf(resourceAzureSobject_createConfig, azure_tenant_id, azure_secret_key, azure_subscription_id, azure_client_id, azure_url),
@@ -72,6 +72,120 @@ func TestAccResourceAzureSobject(t *testing.T) {
 	})
 }
 
-func testAccCheckDestroyAzureSobject(s *terraform.State) (err error) {
-	return err
+// dsm_azure_sobject copied from an exportable AES key, with the given update attributes
+func testUnitAzureSobjectConfig(description string, extra string) string {
+	return testUnitAzureGroupConfig("Azure Group Test") + fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_sobject" {
+  name     = "example_sobject"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "RSA"
+  key_size = 2048
+  key_ops  = ["SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "APPMANAGEABLE", "EXPORT"]
+}
+
+resource "dsm_azure_sobject" "example_azure_sobject" {
+  name        = "example-azure-sobject"
+  group_id    = dsm_azure_group.example_azure_group.group_id
+  description = %q
+  key = {
+    kid = dsm_sobject.example_sobject.kid
+  }
+  custom_metadata = {
+    azure-key-name = "example-azure-key"
+  }
+  %s
+}
+`, description, extra)
+}
+
+func TestUnitResourceAzureSobject(t *testing.T) {
+	m := newMockDSM(t)
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		// the rotated key is deleted with the security object, or the group could not be deleted
+		CheckDestroy: func(s *terraform.State) error {
+			if m.get("keys", kid) != nil {
+				return fmt.Errorf("rotated security object %s was not deleted", kid)
+			}
+			return testAccCheckDestroy(s)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitAzureSobjectConfig("created by terraform", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "obj_type", "RSA"),
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "external.Azure_key_state", "enabled"),
+					testUnitCheckMock(m, "keys", "dsm_azure_sobject.example_azure_sobject", func(key map[string]interface{}) error {
+						if metadata := key["custom_metadata"].(map[string]interface{}); metadata["azure-key-name"] != "example-azure-key" {
+							return fmt.Errorf("unexpected azure key name: %v", metadata)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrPair("dsm_azure_sobject.example_azure_sobject", "links.copiedFrom", "dsm_sobject.example_sobject", "kid"),
+				),
+				// the azure group secret key is never read back
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testUnitAzureSobjectConfig("updated by terraform", ""),
+				Check: testUnitCheckMock(m, "keys", "dsm_azure_sobject.example_azure_sobject", func(key map[string]interface{}) error {
+					if key["description"] != "updated by terraform" {
+						return fmt.Errorf("key was not updated: %v", key)
+					}
+					return nil
+				}),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:             testUnitAzureSobjectConfig("updated by terraform", `rotate_after_days = 30`),
+				Check:              testUnitID(&kid, "dsm_azure_sobject.example_azure_sobject"),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// a key older than rotate_after_days is rekeyed in place
+				PreConfig: func() {
+					m.mutate("keys", kid, func(key map[string]interface{}) {
+						key["created_at"] = mockTime(time.Now().AddDate(0, 0, -40))
+					})
+				},
+				Config: testUnitAzureSobjectConfig("updated by terraform", `rotate_after_days = 30`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("dsm_azure_sobject.example_azure_sobject", "previous_kid", &kid),
+					resource.TestCheckResourceAttrPtr("dsm_azure_sobject.example_azure_sobject", "links.replaced", &kid),
+					resource.TestCheckResourceAttrPtr("dsm_azure_sobject.example_azure_sobject", "rotated_kids.0", &kid),
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "external.Azure_key_state", "enabled"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// purging before soft deletion only warns and leaves the key alone
+				Config:             testUnitAzureSobjectConfig("updated by terraform", "purge_deleted_key = true"),
+				Check:              resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "external.Azure_key_state", "enabled"),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testUnitAzureSobjectConfig("updated by terraform", "soft_deletion = true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "external.Azure_key_state", "deleted"),
+					testUnitCheckRotatedMock(m, &kid, "azure-key-state", "deleted"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testUnitAzureSobjectConfig("updated by terraform", "soft_deletion = true\n  purge_deleted_key = true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "external.Azure_key_state", "purged"),
+					resource.TestCheckResourceAttr("dsm_azure_sobject.example_azure_sobject", "state", "Destroyed"),
+					testUnitCheckRotatedMock(m, &kid, "azure-key-state", "purged"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
 }
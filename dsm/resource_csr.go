@@ -148,7 +148,7 @@ func resourceCreateCsr(ctx context.Context, d *schema.ResourceData, m interface{
 		emails = []string{}
 	}
 
-	newsigner, err := NewDSMSigner(d.Get("kid").(string), dnsnames, ips, emails, d.Get("cn").(string), d.Get("ou").(string), d.Get("l").(string), d.Get("c").(string), d.Get("o").(string), d.Get("st").(string), d.Get("e").(string), m.(*api_client))
+	newsigner, err := NewDSMSigner(ctx, d.Get("kid").(string), dnsnames, ips, emails, d.Get("cn").(string), d.Get("ou").(string), d.Get("l").(string), d.Get("c").(string), d.Get("o").(string), d.Get("st").(string), d.Get("e").(string), m.(*api_client))
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
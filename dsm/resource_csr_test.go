@@ -0,0 +1,72 @@
+package dsm
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testUnitCheckCsr parses the PEM CSR of a resource and verifies its subject and signature.
+func testUnitCheckCsr(name string, cn string, dnsnames ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s is not in the state", name)
+		}
+		block, _ := pem.Decode([]byte(rs.Primary.Attributes["value"]))
+		if block == nil || block.Type != "CERTIFICATE REQUEST" {
+			return fmt.Errorf("%s is not a PEM CSR: %q", name, rs.Primary.Attributes["value"])
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return err
+		}
+		if err := csr.CheckSignature(); err != nil {
+			return fmt.Errorf("%s is not signed by its key: %v", name, err)
+		}
+		if csr.Subject.CommonName != cn || fmt.Sprint(csr.DNSNames) != fmt.Sprint(dnsnames) {
+			return fmt.Errorf("%s has subject %s and DNS names %v", name, csr.Subject, csr.DNSNames)
+		}
+		return nil
+	}
+}
+
+func TestUnitResourceCsr(t *testing.T) {
+	m := newMockDSM(t)
+	m.seedPlugins(t, m.seedGroup("plugin_group", nil))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_rsa" {
+  name     = "example_rsa"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "RSA"
+  key_size = 2048
+}
+
+resource "dsm_csr" "example_csr" {
+  kid      = dsm_sobject.example_rsa.kid
+  cn       = "www.example.com"
+  o        = "Example"
+  c        = "US"
+  dnsnames = ["www.example.com", "example.com"]
+  ips      = ["10.0.0.1"]
+}
+`,
+				Check: testUnitCheckCsr("dsm_csr.example_csr", "www.example.com", "www.example.com", "example.com"),
+			},
+		},
+	})
+}
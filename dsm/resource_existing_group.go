@@ -54,27 +54,37 @@ func resourceExistingGroup() *schema.Resource {
 			    Description: "The Fortanix DSM group object quorum approval policy definition as a JSON string.",
 				Type:     schema.TypeString,
 				Optional: true,
+				DiffSuppressFunc: jsonDiffSuppress,
+				StateFunc:        normalizeJSON,
 			},
 			"hmg": {
-			    Description: "The Fortanix DSM group object HMS/KMS definition as a JSON string.",
+			    Description: "The Fortanix DSM group object HMS/KMS definition as a JSON string. A change modifies the HMG of the group, it can not be added while updating the group.",
 				Type:     schema.TypeString,
 				Optional: true,
+				DiffSuppressFunc: jsonDiffSuppress,
+				StateFunc:        normalizeJSON,
 			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
 	}
 }
 
 // [C]: Create Group - Not applicable for managing existing groups
 func resourceCreateExistingGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the configured hmg keeps the secrets DSM does not return
+	hmg := d.Get("hmg").(string)
 	read_diags := dataSourceGroupRead(ctx, d, m)
 	if read_diags != nil {
 		return read_diags
 	}
 	group_id := d.Get("group_id").(string)
 	d.SetId(group_id)
+	if len(hmg) > 0 {
+		d.Set("hmg", hmg)
+	}
 	return resourceReadExistingGroup(ctx, d, m)
 }
 
@@ -82,7 +92,6 @@ func resourceCreateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	var approval_policy_new json.RawMessage = nil
-	var hmg_new json.RawMessage
 	hmg_object := make(map[string]interface{})
 	description_new := ""
 	name_new := ""
@@ -107,25 +116,11 @@ func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 		d.Set("name", old)
 	}
 
-	if hmg, ok := d.GetOk("hmg"); ok {
-		hmg_new = json.RawMessage(hmg.(string))
-		d.Set("hmg", nil)
-	}
-
 	read_diags := resourceReadGroup(ctx, d, m)
 	if read_diags != nil {
 		return read_diags
 	}
 
-	if hmg, ok := d.GetOk("hmg"); ok {
-		hmg_id := substr(hmg.(string), 4, 36)
-		if debug_output {
-			tflog.Warn(ctx, fmt.Sprintf("HMG id: %s", hmg_id))
-		}
-		hmg_object[hmg_id] = hmg_new
-		hmg_present = true
-	}
-
 	group_object := make(map[string]interface{})
 	group_id := d.Get("group_id").(string)
 	if group_id == "" {
@@ -136,24 +131,33 @@ func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 		})
 		return diags
 	}
+
+	if hmg, ok := d.GetOk("hmg"); ok && d.HasChange("hmg") {
+		var hmg_new map[string]interface{}
+		if err := json.Unmarshal([]byte(hmg.(string)), &hmg_new); err != nil {
+			return invokeErrorDiagsNoSummary(fmt.Sprintf("hmg should be a JSON object: %v", err))
+		}
+		group, err := m.(*api_client).API().GetGroup(ctx, group_id)
+		if err != nil {
+			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/groups: %v", err), "[DSM SDK] Unable to call DSM provider API client")
+		}
+		hmg_id := existingGroupHmgId(group.Hmg)
+		if hmg_id == "" {
+			return invokeErrorDiagsNoSummary("hmg can not be added while updating the group")
+		}
+		tflog.Debug(ctx, fmt.Sprintf("HMG id: %s", hmg_id))
+		hmg_object[hmg_id] = hmg_new
+		hmg_present = true
+	}
 	operation := "PATCH"
 	url := fmt.Sprintf("sys/v1/groups/%s", group_id)
+	requires_approval := false
 
-	if debug_output {
-		tflog.Warn(ctx, fmt.Sprintf("Update operation group id: %s", group_id))
-		tflog.Warn(ctx, fmt.Sprintf("New Approval Policy: %s", approval_policy_new))
-		tflog.Warn(ctx, fmt.Sprintf("Old Approval Policy: %s", json.RawMessage(d.Get("approval_policy").(string))))
-		tflog.Warn(ctx, fmt.Sprintf("New HMG object: %s", hmg_object))
-		tflog.Warn(ctx, fmt.Sprintf("Old HMG: %s", d.Get("hmg").(string)))
-	}
+	tflog.Debug(ctx, fmt.Sprintf("Update operation group id: %s", group_id))
 
 	if _, ok := d.GetOk("approval_policy"); ok {
-		if debug_output {
-			tflog.Warn(ctx, "[U]: Approval policy is present.")
-		}
+		tflog.Debug(ctx, "[U]: Approval policy is present.")
 		body_object := make(map[string]interface{})
-		group_object["method"] = "PATCH"
-		group_object["operation"] = url
 		if approval_policy_new == nil {
 			body_object["approval_policy"] = make(map[string]interface{})
 		} else {
@@ -168,13 +172,10 @@ func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 		if hmg_present {
 			body_object["mod_hmg"] = hmg_object
 		}
-		group_object["body"] = body_object
-		operation = "POST"
-		url = "sys/v1/approval_requests"
+		group_object = body_object
+		requires_approval = true
 	} else {
-		if debug_output {
-			tflog.Warn(ctx, "[U]: Approval policy is not set.")
-		}
+		tflog.Debug(ctx, "[U]: Approval policy is not set.")
 		group_object["group_id"] = group_id
 		if approval_policy_new == nil {
 			group_object["approval_policy"] = make(map[string]interface{})
@@ -192,12 +193,15 @@ func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 		}
 	}
 
-	if debug_output {
-		jj, _ := json.Marshal(group_object)
-		tflog.Warn(ctx, fmt.Sprintf("Update Group Object: %s", jj))
-	}
 
-	resp, err := m.(*api_client).APICallBody(operation, url, group_object)
+	var approval_diags diag.Diagnostics
+	var err error
+	if requires_approval {
+		_, approval_diags, err = m.(*api_client).approvedCall(ctx, operation, url, group_object, approvalTimeout(d))
+	} else {
+		_, approval_diags, err = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, group_object, approvalTimeout(d))
+	}
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -207,21 +211,17 @@ func resourceUpdateExistingGroup(ctx context.Context, d *schema.ResourceData, m
 		return diags
 	}
 
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[U]: API response for group update operation: %s", resp_json))
-	}
 
 	d.SetId(group_id)
-	return resourceReadExistingGroup(ctx, d, m)
+	return append(diags, resourceReadExistingGroup(ctx, d, m)...)
 }
 
 // [R]: Read Group
 func resourceReadExistingGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 404 {
+	req, err := m.(*api_client).APICall(ctx, "GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
+	if IsNotFound(err) {
 		d.SetId("")
 	} else {
 		if err != nil {
@@ -251,12 +251,20 @@ func resourceReadExistingGroup(ctx context.Context, d *schema.ResourceData, m in
 			}
 		}
 		if _, ok := req["approval_policy"]; ok {
-			if err := d.Set("approval_policy", fmt.Sprintf("%v", req["approval_policy"])); err != nil {
+			approval_policy, err := json.Marshal(req["approval_policy"])
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("approval_policy", normalizeJSON(string(approval_policy))); err != nil {
 				return diag.FromErr(err)
 			}
 		}
-		if _, ok := req["hmg"]; ok {
-			if err := d.Set("hmg", fmt.Sprintf("%v", req["hmg"])); err != nil {
+		if dsm_hmg, ok := req["hmg"].(map[string]interface{}); ok {
+			hmg, err := existingGroupHmgRead(dsm_hmg, d.Get("hmg").(string))
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("hmg", hmg); err != nil {
 				return diag.FromErr(err)
 			}
 		}
@@ -264,6 +272,48 @@ func resourceReadExistingGroup(ctx context.Context, d *schema.ResourceData, m in
 	return diags
 }
 
+// [-]: the ID of the HMG that hmg defines, the first one by hsm_order
+func existingGroupHmgId(dsm_hmg map[string]interface{}) string {
+	hmg_id := ""
+	hsm_order := 0.0
+	for id, config := range dsm_hmg {
+		config, _ := config.(map[string]interface{})
+		order, _ := config["hsm_order"].(float64)
+		if hmg_id == "" || order < hsm_order || (order == hsm_order && id < hmg_id) {
+			hmg_id, hsm_order = id, order
+		}
+	}
+	return hmg_id
+}
+
+// [-]: hmg from the HMGs of a group in DSM, as a normalized JSON string
+// DSM does not return secrets such as secret_key, they are kept from the current hmg.
+func existingGroupHmgRead(dsm_hmg map[string]interface{}, current string) (string, error) {
+	hmg_id := existingGroupHmgId(dsm_hmg)
+	if hmg_id == "" {
+		return "", nil
+	}
+	hmg := map[string]interface{}{}
+	if config, ok := dsm_hmg[hmg_id].(map[string]interface{}); ok {
+		for key, value := range config {
+			hmg[key] = value
+		}
+	}
+	var current_hmg map[string]interface{}
+	if json.Unmarshal([]byte(current), &current_hmg) == nil {
+		for key, value := range current_hmg {
+			if _, ok := hmg[key]; !ok {
+				hmg[key] = value
+			}
+		}
+	}
+	hmg_json, err := json.Marshal(hmg)
+	if err != nil {
+		return "", err
+	}
+	return normalizeJSON(string(hmg_json)), nil
+}
+
 // [D]: Delete Group - Not much helpful for managing existing groups
 func resourceDeleteExistingGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	return nil
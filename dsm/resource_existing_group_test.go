@@ -0,0 +1,123 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceExistingGroup(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("existing_group", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// destroy only stops managing the group
+		CheckDestroy: func(s *terraform.State) error {
+			if m.get("groups", group_id) == nil {
+				return fmt.Errorf("existing group %s was deleted", group_id)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dsm_existing_group" "existing_group" {
+  name = "existing_group"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_existing_group.existing_group", "id", group_id),
+					resource.TestCheckResourceAttr("dsm_existing_group.existing_group", "acct_id", m.acct_id),
+				),
+			},
+			{
+				Config: `
+resource "dsm_existing_group" "existing_group" {
+  name        = "existing_group"
+  description = "managed by terraform"
+}
+`,
+				Check: testUnitCheckMock(m, "groups", "dsm_existing_group.existing_group", func(group map[string]interface{}) error {
+					if group["description"] != "managed by terraform" {
+						return fmt.Errorf("group was not updated: %v", group)
+					}
+					return nil
+				}),
+			},
+			{
+				ResourceName:      "dsm_existing_group.existing_group",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitResourceExistingGroupJSON(t *testing.T) {
+	m := newMockDSM(t)
+	m.seedGroup("quorum_group", map[string]interface{}{"approval_policy": m.quorumPolicy()})
+	hmg_group_id := m.seedGroup("hmg_group", map[string]interface{}{
+		"add_hmg": []interface{}{
+			map[string]interface{}{
+				"kind":       "GCPKEYRING",
+				"url":        "https://cloudkms.googleapis.com",
+				"project_id": "example-project",
+				"location":   "us-east1",
+				"key_ring":   "example-key-ring",
+				"secret_key": "example-secret",
+			},
+		},
+	})
+	var hmg_id string
+	for id := range m.get("groups", hmg_group_id)["hmg"].(map[string]interface{}) {
+		hmg_id = id
+	}
+	config := func(location string) string {
+		return fmt.Sprintf(`
+resource "dsm_existing_group" "quorum_group" {
+  name            = "quorum_group"
+  approval_policy = <<-EOT
+    {
+      "policy": { "quorum": { "members": [{ "user": %q }], "n": 1 } },
+      "manage_groups": true
+    }
+  EOT
+}
+
+resource "dsm_existing_group" "hmg_group" {
+  name = "hmg_group"
+  hmg = jsonencode({
+    url        = "https://cloudkms.googleapis.com"
+    kind       = "GCPKEYRING"
+    key_ring   = "example-key-ring"
+    location   = %q
+    project_id = "example-project"
+    secret_key = "example-secret"
+  })
+}
+`, m.user_id, location)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				// JSON that only differs from DSM in key order and whitespace, and in the
+				// secret_key DSM does not return, has no diff
+				Config: config("us-east1"),
+			},
+			{
+				Config: config("us-west1"),
+				Check: testUnitCheckMock(m, "groups", "dsm_existing_group.hmg_group", func(group map[string]interface{}) error {
+					if config, _ := group["hmg"].(map[string]interface{})[hmg_id].(map[string]interface{}); config["location"] != "us-west1" {
+						return fmt.Errorf("hmg was not modified: %v", group["hmg"])
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
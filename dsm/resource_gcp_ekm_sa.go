@@ -121,7 +121,7 @@ func resourceCreateGcpEkmSa(ctx context.Context, d *schema.ResourceData, m inter
 		"googleserviceaccount": gcp_perm,
 	}
 
-	req, err := m.(*api_client).APICallBody("POST", "sys/v1/apps", app_object)
+	req, err := m.(*api_client).APICallBody(ctx, "POST", "sys/v1/apps", app_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -139,8 +139,8 @@ func resourceCreateGcpEkmSa(ctx context.Context, d *schema.ResourceData, m inter
 func resourceReadGcpEkmSa(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
-	if statuscode == 404 {
+	req, err := m.(*api_client).APICall(ctx, "GET", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	if IsNotFound(err) {
 		d.SetId("")
 	} else {
 		if err != nil {
@@ -202,7 +202,7 @@ func resourceUpdateGcpEkmSa(ctx context.Context, d *schema.ResourceData, m inter
 		app_object["description"] = d.Get("description")
 	}
 	if len(app_object) > 0 {
-		req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
+		req, err := m.(*api_client).APICallBody(ctx, "PATCH", fmt.Sprintf("sys/v1/apps/%s", d.Id()), app_object)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -220,8 +220,8 @@ func resourceUpdateGcpEkmSa(ctx context.Context, d *schema.ResourceData, m inter
 func resourceDeleteGcpEkmSa(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) {
+	_, err := m.(*api_client).APICall(ctx, "DELETE", fmt.Sprintf("sys/v1/apps/%s", d.Id()))
+	if (err != nil) && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -1,20 +1,18 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 var (
 	resourceGcpEKM_createConfig = `resource "dsm_group" "example_group" {
 		name = "example_group"
 	}
-  
+
 	resource "dsm_gcp_ekm_sa" "example_gcp_ekm_sa" {
 		name = "%s"
     		default_group = "${dsm_group.example_group.group_id}"
@@ -26,16 +24,82 @@ func TestAccResourceGcpEKM(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
-		PreCheck:     func() { testAccPreCheckGcp(t) },
-		CheckDestroy: testAccCheckDestroyGcpEKM,
+		PreCheck:     func() { testAccPreCheck(t); testAccPreCheckGcp(t) },
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(resourceAwsSobject_createConfig, google_service_account),
+				Config: fmt.Sprintf(resourceGcpEKM_createConfig, google_service_account),
 			},
 		},
 	})
 }
 
-func testAccCheckDestroyGcpEKM(s *terraform.State) (err error) {
-	return err
+func TestUnitResourceGcpEKM(t *testing.T) {
+	m := newMockDSM(t)
+	var app_id string
+	config := func(description string) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_gcp_ekm_sa" "example_gcp_ekm_sa" {
+  name          = "ekm@example-project.iam.gserviceaccount.com"
+  description   = %q
+  default_group = dsm_group.example_group.group_id
+}
+`, description)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("created by terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&app_id, "dsm_gcp_ekm_sa.example_gcp_ekm_sa"),
+					testUnitCheckMock(m, "apps", "dsm_gcp_ekm_sa.example_gcp_ekm_sa", func(app map[string]interface{}) error {
+						if credential, _ := app["credential"].(map[string]interface{}); credential["googleserviceaccount"] == nil {
+							return fmt.Errorf("app is not a google service account: %v", app["credential"])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:      "dsm_gcp_ekm_sa.example_gcp_ekm_sa",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: config("updated by terraform"),
+				Check:  resource.TestCheckResourceAttr("dsm_gcp_ekm_sa.example_gcp_ekm_sa", "description", "updated by terraform"),
+			},
+			{
+				// drift: the description is changed outside of terraform
+				PreConfig: func() {
+					m.mutate("apps", app_id, func(app map[string]interface{}) { app["description"] = "changed in dsm" })
+				},
+				Config: config("updated by terraform"),
+				Check: testUnitCheckMock(m, "apps", "dsm_gcp_ekm_sa.example_gcp_ekm_sa", func(app map[string]interface{}) error {
+					if app["description"] != "updated by terraform" {
+						return fmt.Errorf("drift was not corrected: %v", app)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the app is deleted outside of terraform
+				PreConfig: func() { m.remove("apps", app_id) },
+				Config:    config("updated by terraform"),
+				Check: testUnitCheckMock(m, "apps", "dsm_gcp_ekm_sa.example_gcp_ekm_sa", func(app map[string]interface{}) error {
+					if app["app_id"] == app_id {
+						return fmt.Errorf("app %s was not created again", app_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
 }
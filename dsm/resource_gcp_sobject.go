@@ -10,7 +10,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -21,12 +20,13 @@ import (
 
 // [-] Define GCP Security Object in Terraform
 func resourceGCPSobject() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateGCPSobject,
 		ReadContext:   resourceReadGCPSobject,
 		UpdateContext: resourceUpdateGCPSobject,
 		DeleteContext: resourceDeleteGCPSobject,
 		Description: "Creates a new security object in GCP CDC Group. This is a Bring-Your-Own-Key (BYOK) method and copies an existing DSM local security object to GCP KMS as a Customer Managed Key (CMK).",
+		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Description: "The security object name.",
@@ -74,18 +74,8 @@ func resourceGCPSobject() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-			"rotation_policy": {
-				Description: "Policy to rotate a security object. Configure the parameters below:\n" +
-				"   * `interval_days`: Rotate the key every given number of days.\n" +
-				"   * `interval_months`: Rotate the key every given number of months.\n" +
-				"   * `effective_at`: Start time of the rotation policy.\n" +
-				"   * **Note:** Either `interval_days` or `interval_months` should be given, but not both.",
-				Type:     schema.TypeMap,
-				Optional: true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
-			},
+			"rotation_policy": rotationPolicySchema("Policy to rotate a security object.\n" +
+				"   * **Note:** Either `interval_days` or `interval_months` should be given, but not both."),
 			"custom_metadata": {
 				Description: "GCP KMS key metadata information:\n" +
 				"   * `gcp-key-id`: Key name within GCP KMS.",
@@ -152,7 +142,12 @@ func resourceGCPSobject() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
+	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		rotationPolicyStateUpgrader(resource),
 	}
+	return resource
 }
 
 // [C]: Create GCP Security Object
@@ -182,10 +177,15 @@ func resourceCreateGCPSobject(ctx context.Context, d *schema.ResourceData, m int
 	if err := d.Get("custom_metadata").(map[string]interface{}); len(err) > 0 {
 		security_object["custom_metadata"] = d.Get("custom_metadata")
 	}
-	if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-		security_object["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+	rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+	if date_error != nil {
+		return date_error
+	}
+	if len(rotation_policy) > 0 {
+		security_object["rotation_policy"] = rotation_policy
 	}
-	req, err := m.(*api_client).APICallBody("POST", "crypto/v1/keys/copy", security_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", "crypto/v1/keys/copy", security_object, approvalTimeout(d))
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -195,118 +195,97 @@ func resourceCreateGCPSobject(ctx context.Context, d *schema.ResourceData, m int
 		return diags
 	}
 	d.SetId(req["kid"].(string))
-	return resourceReadGCPSobject(ctx, d, m)
+	return append(diags, resourceReadGCPSobject(ctx, d, m)...)
 }
 
 // [R]: Read GCP Security Object
 func resourceReadGCPSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
-	if statuscode == 404 {
+	sobject, err := m.(*api_client).API().GetKey(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
-			})
-			return diags
-		}
-		// Convert returned call into gcp Map
-		jsonbody, err := json.Marshal(req)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
-		gcpsobject := GCPSobject{}
-		if err := json.Unmarshal(jsonbody, &gcpsobject); err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %s", err),
-			})
-			return diags
-		}
-		// Sync DSM and Terraform attributes
-		if err := d.Set("name", gcpsobject.Name); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("group_id", req["group_id"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("links", req["links"]); err != nil {
+		return nil
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
+		})
+		return diags
+	}
+	// Sync DSM and Terraform attributes
+	if err := d.Set("name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Links != nil {
+		if err := d.Set("links", sobject.Links.Map()); err != nil {
 			return diag.FromErr(err)
 		}
-		external_data := make(map[string]interface{})
-		response_external, ok := req["external"].(map[string]interface{})
-		if ok {
-			for key, value := range(response_external) {
-				if key == "id" {
-					id := value.(map[string]interface{})
-					for id_key, id_value := range(id){
-						if id_key == "version" {
-							external_data[id_key] = strconv.FormatFloat(id_value.(float64), 'f', -1, 64)
-						} else {
-							external_data[id_key] = id_value
-						}
-					}
-				} else if key == "hsm_group_id" {
-					external_data[key] = value
-				}
+	}
+	external_data := make(map[string]interface{})
+	if sobject.External != nil {
+		for id_key, id_value := range sobject.External.Id {
+			if version, ok := id_value.(float64); ok && id_key == "version" {
+				external_data[id_key] = strconv.FormatFloat(version, 'f', -1, 64)
+			} else {
+				external_data[id_key] = id_value
 			}
 		}
-		if err := d.Set("external", external_data); err != nil {
-			return diag.FromErr(err)
-		}		
-		if err := d.Set("kid", req["kid"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("creator", req["creator"]); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("custom_metadata", req["custom_metadata"]); err != nil {
+		external_data["hsm_group_id"] = sobject.External.Hsm_group_id
+	}
+	if err := d.Set("external", external_data); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("custom_metadata", sobject.Custom_metadata); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("key_ops", sobject.Key_ops); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("key_ops", req["key_ops"]); err != nil {
-			return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", sobject.State); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Deactivation_date != nil {
+		// FYOO: once it's set, you can't remove deactivation date
+		layoutRFC := "2006-01-02T15:04:05Z"
+		layoutDSM := "20060102T150405Z"
+		ddate, newerr := time.Parse(layoutDSM, *sobject.Deactivation_date)
+		if newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if _, ok := req["description"]; ok {
-			if err := d.Set("description", req["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+		if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
-			return diag.FromErr(err)
+	}
+	if sobject.Rotation_policy != nil {
+		rotation_policy, date_error := rotationPolicyRead(sobject.Rotation_policy)
+		if date_error != nil {
+			return date_error
 		}
-		if err := d.Set("state", req["state"].(string)); err != nil {
+		if err := d.Set("rotation_policy", rotation_policy); err != nil {
 			return diag.FromErr(err)
 		}
-		if rfcdate, ok := req["deactivation_date"]; ok {
-			// FYOO: once it's set, you can't remove deactivation date
-			layoutRFC := "2006-01-02T15:04:05Z"
-			layoutDSM := "20060102T150405Z"
-			ddate, newerr := time.Parse(layoutDSM, rfcdate.(string))
-			if newerr != nil {
-				return diag.FromErr(newerr)
-			}
-			if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
-				return diag.FromErr(newerr)
-			}
-		}
-		if _, ok := req["rotation_policy"]; ok {
-			rotation_policy := sobj_rotation_policy_read(req["rotation_policy"].(map[string]interface{}))
-			if err := d.Set("rotation_policy", rotation_policy); err != nil {
-				return diag.FromErr(err)
-			}
-		}
 	}
 	return nil
 }
@@ -329,8 +308,12 @@ func resourceUpdateGCPSobject(ctx context.Context, d *schema.ResourceData, m int
 		update_gcp_key["key_ops"] = d.Get("key_ops")
 	}
 	if d.HasChange("rotation_policy") {
-		if err := d.Get("rotation_policy").(map[string]interface{}); len(err) > 0 {
-			update_gcp_key["rotation_policy"] = sobj_rotation_policy_write(d.Get("rotation_policy").(map[string]interface{}))
+		rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+		if date_error != nil {
+			return date_error
+		}
+		if len(rotation_policy) > 0 {
+			update_gcp_key["rotation_policy"] = rotation_policy
 		}
 	}
 	if d.HasChange("expiry_date") {
@@ -350,7 +333,8 @@ func resourceUpdateGCPSobject(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 	if len(update_gcp_key) > 0 {
-		_, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_gcp_key)
+		_, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), update_gcp_key, d.Timeout(schema.TimeoutUpdate))
+		diags = append(diags, approval_diags...)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -361,7 +345,7 @@ func resourceUpdateGCPSobject(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 
-	return resourceReadGCPSobject(ctx, d, m)
+	return append(diags, resourceReadGCPSobject(ctx, d, m)...)
 }
 
 // [D]: Delete GCP Security Object
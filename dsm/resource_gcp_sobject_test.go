@@ -0,0 +1,87 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_gcp_sobject copied from an exportable AES key into a GCP key ring group
+func testUnitGcpSobjectConfig(gcp_group_id string, description string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_sobject" {
+  name     = "example_sobject"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "AES"
+  key_size = 256
+  key_ops  = ["ENCRYPT", "DECRYPT", "APPMANAGEABLE", "EXPORT"]
+}
+
+resource "dsm_gcp_sobject" "example_gcp_sobject" {
+  name        = "example_gcp_sobject"
+  group_id    = %q
+  description = %q
+  key = {
+    kid = dsm_sobject.example_sobject.kid
+  }
+  key_ops = ["ENCRYPT", "DECRYPT", "APPMANAGEABLE", "EXPORT"]
+  custom_metadata = {
+    gcp-key-id = "example-gcp-key"
+  }
+}
+`, gcp_group_id, description)
+}
+
+func TestUnitResourceGcpSobject(t *testing.T) {
+	m := newMockDSM(t)
+	gcp_group_id := m.seedGroup("example_gcp_group", map[string]interface{}{
+		"add_hmg": []interface{}{
+			map[string]interface{}{
+				"kind":       "GCPKEYRING",
+				"url":        "https://cloudkms.googleapis.com",
+				"project_id": "example-project",
+				"location":   "us-east1",
+				"key_ring":   "example-key-ring",
+			},
+		},
+	})
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// GCP security objects cannot be deleted yet, destroy only drops them from the state
+		CheckDestroy: func(s *terraform.State) error {
+			if m.get("keys", kid) == nil {
+				return fmt.Errorf("GCP security object %s was deleted", kid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitGcpSobjectConfig(gcp_group_id, "created by terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_gcp_sobject.example_gcp_sobject"),
+					resource.TestCheckResourceAttr("dsm_gcp_sobject.example_gcp_sobject", "state", "Active"),
+					resource.TestCheckResourceAttr("dsm_gcp_sobject.example_gcp_sobject", "external.key_id", "example-gcp-key"),
+					resource.TestCheckResourceAttr("dsm_gcp_sobject.example_gcp_sobject", "external.version", "1"),
+					resource.TestCheckResourceAttrPair("dsm_gcp_sobject.example_gcp_sobject", "links.copiedFrom", "dsm_sobject.example_sobject", "kid"),
+				),
+			},
+			{
+				Config: testUnitGcpSobjectConfig(gcp_group_id, "updated by terraform"),
+				Check: testUnitCheckMock(m, "keys", "dsm_gcp_sobject.example_gcp_sobject", func(key map[string]interface{}) error {
+					if key["description"] != "updated by terraform" {
+						return fmt.Errorf("key was not updated: %v", key)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
@@ -2,23 +2,24 @@
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // [-] Define Group
 func resourceGroup() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateGroup,
 		ReadContext:   resourceReadGroup,
 		UpdateContext: resourceUpdateGroup,
 		DeleteContext: resourceDeleteGroup,
 		Description: "Creates a new DSM group. Such groups are act as containers for security objects, like keys or secrets. The returned resource object contains the UUID of the group for further references.\n" +
 		"Besides creating regular DSM groups, this resource may also be used to create DSM groups that are mapped to external resources, e.g. like an Azure Key Vault, an AWS KMS, a GCP Key Ring or a legacy HSM.",
+		SchemaVersion: 2,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -48,21 +49,18 @@ func resourceGroup() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
-			"approval_policy": {
-			    Description: "The Fortanix DSM group object quorum approval policy definition as a JSON string.",
+			"approval_policy": approvalPolicySchema("The quorum approval policy of the group. The protected operations of the group wait for the approval of the quorum.", false, false),
+			"hmg": groupHmgSchema(),
+			"hmg_id": {
+			    Description: "HSM/KMS ID from Fortanix/DSM of the first hmg.",
 				Type:     schema.TypeString,
-				Optional: true,
+				Computed: true,
 			},
-			"hmg": {
-			    Description: "The Fortanix DSM group object HMS/KMS definition as a JSON string. It is only required, " +
-			    "if group is pointing to an external KMS or HSM. For more examples refer Guides/create_BYOK_groups",
+			"hmg_redundancy": {
+			    Description: "The redundancy scheme of the HMGs of the group, e.g. PriorityFailover: DSM tries the HMGs by hsm_order." +
+			    " DSM keeps the scheme once set: removing it from the configuration does not unset it, set another scheme instead.",
 				Type:     schema.TypeString,
 				Optional: true,
-				Sensitive: true,
-			},
-			"hmg_id": {
-			    Description: "HSM/KMS ID from Fortanix/DSM.",
-				Type:     schema.TypeString,
 				Computed: true,
 			},
 			"key_undo_policy_window_time": {
@@ -75,17 +73,19 @@ func resourceGroup() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts:      approvalTimeouts(),
+		CustomizeDiff: customdiff.Sequence(
+			validateGroupHmgCustomizeDiff,
+			validateApprovalPolicyCustomizeDiff,
+		),
 	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		groupHmgStateUpgrader(resource),
+		approvalPolicyStateUpgrader(resource, 1, false),
+	}
+	return resource
 }
 
-// FIX ME
-// Terraform internal state keeping does not have a native struct for JSON input and what they recommend is to
-// keep it as a string. So, what we are keeping locally and what comes as a result is always different. Plus the
-// response may have additional fields or the ordering may change. Since change detection is done by Terraform itself
-// there is only one way out for us and that is to ignore API responses for JSON fields if the request is successful.
-// Since what we get from the yaml is always kept in the internal state without getting overwritten with data from the
-// API, change detection works. This is a very pathetic workaround and should be fixed as soon as Terraform improves its SDK.
-
 // [C]: Create Group
 func resourceCreateGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -98,23 +98,20 @@ func resourceCreateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 		group_object["description"] = description.(string)
 	}
 
-	if approval_policy, ok := d.GetOk("approval_policy"); ok {
-		group_object["approval_policy"] = json.RawMessage(approval_policy.(string))
+	if approval_policy := approvalPolicyWrite(d.Get("approval_policy").([]interface{}), false); approval_policy != nil {
+		group_object["approval_policy"] = approval_policy
 	}
 
-	if hmg, ok := d.GetOk("hmg"); ok {
-		var hmg_object []json.RawMessage
-		hmg_object = append(hmg_object, json.RawMessage(hmg.(string)))
-		group_object["add_hmg"] = hmg_object
+	if add_hmg, _, _ := groupHmgChanges(nil, d.Get("hmg").([]interface{})); len(add_hmg) > 0 {
+		group_object["add_hmg"] = add_hmg
 	}
-
-	if debug_output {
-		jj, _ := json.Marshal(group_object)
-		tflog.Warn(ctx, fmt.Sprintf("Create Group Object: %s", jj))
+	if hmg_redundancy, ok := d.GetOk("hmg_redundancy"); ok {
+		group_object["hmg_redundancy"] = hmg_redundancy.(string)
 	}
+
 	set_key_undo_policy(d, group_object)
 
-	resp, err := m.(*api_client).APICallBody("POST", "sys/v1/groups", group_object)
+	group, err := m.(*api_client).API().CreateGroup(ctx, group_object)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -123,14 +120,8 @@ func resourceCreateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 		})
 		return diags
 	}
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[U]: API response for group create operation: %s", resp_json))
-	}
-	d.SetId(resp["group_id"].(string))
-	d.Set("group_id", resp["group_id"].(string))
-	set_hmg_id(d, resp)
-	return diags
+	d.SetId(group.Group_id)
+	return resourceReadGroup(ctx, d, m)
 }
 
 // [U]: Update Group
@@ -138,48 +129,46 @@ func resourceUpdateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 	var diags diag.Diagnostics
 
 	hmg_object := make(map[string]interface{})
-	hmg_present := false
 
 	if d.HasChange("description") || d.HasChange("name") || d.HasChange("approval_policy") || d.HasChange("hmg") ||
-	                                d.HasChange("key_undo_policy_window_time") {
-		if debug_output {
-			tflog.Warn(ctx, "Group object has changed, calling API")
-		}
-		if hmg, ok := d.GetOk("hmg"); ok {
-			hmg_id := substr(hmg.(string), 4, 36)
-			if debug_output {
-				tflog.Warn(ctx, fmt.Sprintf("HMG id: %s", hmg_id))
+	                                d.HasChange("hmg_redundancy") || d.HasChange("key_undo_policy_window_time") {
+		tflog.Debug(ctx, "Group object has changed, calling API")
+		if d.HasChange("hmg") {
+			old_hmg, new_hmg := d.GetChange("hmg")
+			add_hmg, mod_hmg, del_hmg := groupHmgChanges(old_hmg.([]interface{}), new_hmg.([]interface{}))
+			tflog.Debug(ctx, fmt.Sprintf("HMG changes: %d added, %d modified, %d deleted", len(add_hmg), len(mod_hmg), len(del_hmg)))
+			if len(add_hmg) > 0 {
+				hmg_object["add_hmg"] = add_hmg
+			}
+			if len(mod_hmg) > 0 {
+				hmg_object["mod_hmg"] = mod_hmg
+			}
+			if len(del_hmg) > 0 {
+				hmg_object["del_hmg"] = del_hmg
 			}
-			if d.Get("hmg_id") == nil {
-			    resourceReadGroup(ctx, d, m)
+		}
+		// hmg_redundancy is Optional+Computed: once set, removing it
+		// plans no change and DSM keeps the last scheme.
+		if d.HasChange("hmg_redundancy") {
+			if hmg_redundancy, ok := d.GetOk("hmg_redundancy"); ok {
+				hmg_object["hmg_redundancy"] = hmg_redundancy.(string)
 			}
-			hmg_object[d.Get("hmg_id").(string)] = json.RawMessage(hmg.(string))
-			hmg_present = true
+		}
+		approval_policy := approvalPolicyWrite(d.Get("approval_policy").([]interface{}), false)
+		if approval_policy == nil && d.HasChange("approval_policy") {
+			// an empty approval policy removes the quorum policy of the group
+			approval_policy = make(map[string]interface{})
 		}
 		group_object := make(map[string]interface{})
 		group_id := d.Get("group_id").(string)
 		operation := "PATCH"
 		url := fmt.Sprintf("sys/v1/groups/%s", group_id)
-		if isSetApprovalPolicy(d, m) {
-			if debug_output {
-				tflog.Warn(ctx, "[U]: Approval policy is present.")
-			}
+		requires_approval := isSetApprovalPolicy(ctx, d, m)
+		if requires_approval {
+			tflog.Debug(ctx, "[U]: Approval policy is present.")
 			body_object := make(map[string]interface{})
-			group_object["method"] = "PATCH"
-			group_object["operation"] = url
-			if approval_policy, ok := d.GetOk("approval_policy"); ok {
-				if approval_policy == nil {
-					body_object["approval_policy"] = make(map[string]interface{})
-				} else {
-					body_object["approval_policy"] = json.RawMessage(approval_policy.(string))
-				}
-			} else {
-				// Handle delete quorum policy.
-				// when approval_policy is removed from terraform body, the above 'if' condition fails.
-				// Hence user, will not be able to remove the quorum policy.
-				if (d.HasChange("approval_policy")) {
-					body_object["approval_policy"] = make(map[string]interface{})
-				}
+			if approval_policy != nil {
+				body_object["approval_policy"] = approval_policy
 			}
 			if description, ok := d.GetOk("description"); ok {
 				if description != "" {
@@ -199,24 +188,16 @@ func resourceUpdateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 					return diags
 				}
 			}
-			if hmg_present {
-				body_object["mod_hmg"] = hmg_object
+			for k, v := range hmg_object {
+				body_object[k] = v
 			}
-			group_object["body"] = body_object
-			operation = "POST"
-			url = "sys/v1/approval_requests"
+			group_object = body_object
 		} else {
-			if debug_output {
-				tflog.Warn(ctx, "[U]: Approval policy is not set.")
-			}
+			tflog.Debug(ctx, "[U]: Approval policy is not set.")
 			group_object["group_id"] = group_id
 
-			if approval_policy, ok := d.GetOk("approval_policy"); ok {
-				if approval_policy == nil {
-					group_object["approval_policy"] = make(map[string]interface{})
-				} else {
-					group_object["approval_policy"] = json.RawMessage(approval_policy.(string))
-				}
+			if approval_policy != nil {
+				group_object["approval_policy"] = approval_policy
 			}
 			set_key_undo_policy(d, group_object)
 			if description, ok := d.GetOk("description"); ok {
@@ -238,16 +219,19 @@ func resourceUpdateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 				}
 			}
 
-			if hmg_present {
-				group_object["mod_hmg"] = hmg_object
+			for k, v := range hmg_object {
+				group_object[k] = v
 			}
 		}
 
-		if debug_output {
-			jj, _ := json.Marshal(group_object)
-			tflog.Warn(ctx, fmt.Sprintf("Update Group Object: %s", jj))
+		var approval_diags diag.Diagnostics
+		var err error
+		if requires_approval {
+			_, approval_diags, err = m.(*api_client).approvedCall(ctx, operation, url, group_object, d.Timeout(schema.TimeoutUpdate))
+		} else {
+			_, approval_diags, err = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, group_object, d.Timeout(schema.TimeoutUpdate))
 		}
-		resp, err := m.(*api_client).APICallBody(operation, url, group_object)
+		diags = append(diags, approval_diags...)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -257,49 +241,68 @@ func resourceUpdateGroup(ctx context.Context, d *schema.ResourceData, m interfac
 			return diags
 		}
 
-		if debug_output {
-			resp_json, _ := json.Marshal(resp)
-			tflog.Warn(ctx, fmt.Sprintf("[U]: API response for group update operation: %s", resp_json))
-		}
 	}
 
-	return diags
+	return append(diags, resourceReadGroup(ctx, d, m)...)
 }
 
 // [R]: Read Group
 func resourceReadGroup(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 404 {
+	group, err := m.(*api_client).API().GetGroup(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups/%s: %v", d.Id(), err),
-			})
-			return diags
-		}
-		if err := d.Set("name", req["name"].(string)); err != nil {
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET sys/v1/groups/%s: %v", d.Id(), err),
+		})
+		return diags
+	}
+	if err := d.Set("name", group.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", group.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", group.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", group.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	if group.Description != nil {
+		if err := d.Set("description", *group.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("group_id", req["group_id"].(string)); err != nil {
+	}
+	// dsm_existing_group shares this read and keeps its JSON strings
+	if approval_policy, ok := d.Get("approval_policy").([]interface{}); ok {
+		if err := d.Set("approval_policy", approvalPolicyRead(group.Approval_policy, approval_policy, false)); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	}
+	if hmg_blocks, ok := d.Get("hmg").([]interface{}); ok {
+		hmg := groupHmgState(hmg_blocks, group.Hmg)
+		if err := d.Set("hmg", hmg); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("creator", req["creator"]); err != nil {
+		hmg_id := ""
+		if len(hmg) > 0 {
+			hmg_id, _ = hmg[0].(map[string]interface{})["hmg_id"].(string)
+		}
+		if err := d.Set("hmg_id", hmg_id); err != nil {
 			return diag.FromErr(err)
 		}
-		if description, ok := req["description"]; ok {
-			if err := d.Set("description", description.(string)); err != nil {
+		if len(group.Hmg_redundancy) > 0 {
+			if err := d.Set("hmg_redundancy", group.Hmg_redundancy); err != nil {
 				return diag.FromErr(err)
 			}
 		}
-		set_hmg_id(d, req)
 	}
 	return diags
 }
@@ -311,20 +314,20 @@ func resourceDeleteGroup(ctx context.Context, d *schema.ResourceData, m interfac
 	dataSourceGroupRead(ctx, d, m)
 	group_id := d.Get("group_id").(string)
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("sys/v1/groups/%s", group_id))
-	if err != nil {
+	err := m.(*api_client).API().DeleteGroup(ctx, group_id)
+	if IsBadRequest(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "[DSM SDK] Unable to call DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: DELETE sys/v1/groups/%s %d: %v", group_id, statuscode, err),
+			Summary:  "[DSM SDK] Call to DSM provider API client failed",
+			Detail:   fmt.Sprintf("[E]: API: DELETE sys/v1/groups/%s Group is not empty", group_id),
 		})
 		return diags
 	}
-	if statuscode == 400 {
+	if err != nil && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "[DSM SDK] Call to DSM provider API client failed",
-			Detail:   fmt.Sprintf("[E]: API: DELETE sys/v1/groups/%s Group is not empty", group_id),
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: DELETE sys/v1/groups/%s: %v", group_id, err),
 		})
 		return diags
 	}
@@ -334,14 +337,9 @@ func resourceDeleteGroup(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 // [R]: Check Approval Policy presence
-func isSetApprovalPolicy(d *schema.ResourceData, m interface{}) bool {
-	req, statuscode, _ := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 200 {
-		if _, ok := req["approval_policy"]; ok {
-			return true
-		}
-	}
-	return false
+func isSetApprovalPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) bool {
+	group, err := m.(*api_client).API().GetGroup(ctx, d.Id())
+	return err == nil && group.Approval_policy != nil
 }
 // handle the key undo policy for both create and update
 func set_key_undo_policy(d *schema.ResourceData, obj map[string]interface{}) {
@@ -357,17 +355,3 @@ func set_key_undo_policy(d *schema.ResourceData, obj map[string]interface{}) {
 		}
 	}
 }
-
-func set_hmg_id(d *schema.ResourceData, resp map[string]interface{}) diag.Diagnostics {
-    // set the hmg_id to update the cdc/byok attributes
-    if _, ok := resp["hmg"]; ok {
-        if hmg := resp["hmg"].(map[string]interface{}); len(hmg) > 0 {
-            for k := range hmg {
-                if err := d.Set("hmg_id", k); err != nil {
-                    return diag.FromErr(err)
-                }
-            }
-        }
-    }
-    return nil
-}
\ No newline at end of file
@@ -2,7 +2,6 @@ package dsm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -12,7 +11,7 @@ import (
 
 // [-] Define Group Cryptographic Policy
 func resourceGroupCryptoPolicy() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateGroupCryptoPolicy,
 		ReadContext:   resourceReadGroupCryptoPolicy,
 		UpdateContext: resourceUpdateGroupCryptoPolicy,
@@ -49,30 +48,30 @@ func resourceGroupCryptoPolicy() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-			"approval_policy": {
-			    Description: "The Fortanix DSM group object quorum approval policy definition as a JSON string.",
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"cryptographic_policy": {
-			    Description: "The Fortanix DSM group object cryptographic policy definition as a JSON string",
-				Type:     schema.TypeString,
-				Required: true,
-			},
+			"approval_policy": approvalPolicySchema("The quorum approval policy of the group from Fortanix DSM.", false, true),
+			"cryptographic_policy": cryptoPolicySchema("The cryptographic policy of the group. It restricts the types of security objects of the group, their key sizes and their key operations. An algorithm without a block is not allowed."),
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: validateCryptoPolicyCustomizeDiff,
+		Timeouts:      approvalDeleteTimeouts(),
+		SchemaVersion: 2,
+	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		approvalPolicyStateUpgrader(resource, 0, false),
+		cryptoPolicyStateUpgrader(resource, 1),
 	}
+	return resource
 }
 
 // [C]: Create Group Crypto Policy
 func resourceCreateGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	cryptographic_policy := json.RawMessage(d.Get("cryptographic_policy").(string))
+	cryptographic_policy := cryptoPolicyWrite(d.Get("cryptographic_policy").([]interface{}))
 
-	isSetApprovalPolicy, group_id := dataSourceGroupGetData(d, m)
+	isSetApprovalPolicy, group_id := dataSourceGroupGetData(ctx, d, m)
 
 	group_crypto_policy_object := make(map[string]interface{})
 	if group_id == "" {
@@ -83,30 +82,29 @@ func resourceCreateGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData
 		})
 		return diags
 	}
-	if debug_output {
-		tflog.Warn(ctx, fmt.Sprintf("Group id: ->%s<-", group_id))
-	}
+	tflog.Debug(ctx, fmt.Sprintf("Group id: ->%s<-", group_id))
 	operation := "PATCH"
 	url := fmt.Sprintf("sys/v1/groups/%s", group_id)
+	requires_approval := false
 
 	if isSetApprovalPolicy {
-		if debug_output {
-			tflog.Warn(ctx, "[C & U]: Approval policy is present.")
-		}
-		group_crypto_policy_object["method"] = "PATCH"
-		group_crypto_policy_object["operation"] = url
-		group_crypto_policy_object["body"] = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
-		operation = "POST"
-		url = "sys/v1/approval_requests"
+		tflog.Debug(ctx, "[C & U]: Approval policy is present.")
+		group_crypto_policy_object = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
+		requires_approval = true
 	} else {
-		if debug_output {
-			tflog.Warn(ctx, "[C & U]: Approval policy is not set.")
-		}
+		tflog.Debug(ctx, "[C & U]: Approval policy is not set.")
 		group_crypto_policy_object["group_id"] = group_id
 		group_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
 	}
 
-	resp, err := m.(*api_client).APICallBody(operation, url, group_crypto_policy_object)
+	var approval_diags diag.Diagnostics
+	var err error
+	if requires_approval {
+		_, approval_diags, err = m.(*api_client).approvedCall(ctx, operation, url, group_crypto_policy_object, approvalTimeout(d))
+	} else {
+		_, approval_diags, err = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, group_crypto_policy_object, approvalTimeout(d))
+	}
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -116,13 +114,9 @@ func resourceCreateGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData
 		return diags
 	}
 
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[C & U]: API response for cryptographic policy create operation: %s", resp_json))
-	}
 
 	d.SetId(group_id)
-	return diags
+	return append(diags, resourceReadGroupCryptoPolicy(ctx, d, m)...)
 }
 
 // [U]: Update Group Crypto Policy
@@ -138,15 +132,10 @@ func resourceUpdateGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData
 func resourceReadGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", d.Id()))
-	if statuscode == 404 {
+	group, err := m.(*api_client).API().GetGroup(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] DSM provider API client returned not found",
-			Detail:   fmt.Sprintf("[R]: API Call: GET sys/v1/groups/%s", d.Id()),
-		})
-		return diags
+		return nil
 	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
@@ -157,23 +146,29 @@ func resourceReadGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData,
 		return diags
 	}
 
-	if err := d.Set("name", req["name"].(string)); err != nil {
+	if err := d.Set("name", group.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("group_id", req["group_id"].(string)); err != nil {
+	if err := d.Set("group_id", group.Group_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", group.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("creator", req["creator"]); err != nil {
+	if err := d.Set("creator", group.Creator.Map()); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["description"]; ok {
-		if err := d.Set("description", req["description"].(string)); err != nil {
+	if group.Description != nil {
+		if err := d.Set("description", *group.Description); err != nil {
 			return diag.FromErr(err)
 		}
 	}
+	if err := d.Set("cryptographic_policy", cryptoPolicyRead(group.Cryptographic_policy)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("approval_policy", approvalPolicyRead(group.Approval_policy, nil, false)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return diags
 }
@@ -182,31 +177,31 @@ func resourceReadGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData,
 func resourceDeleteGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	isSetApprovalPolicy, group_id := dataSourceGroupGetData(d, m)
+	isSetApprovalPolicy, group_id := dataSourceGroupGetData(ctx, d, m)
+	if group_id == "" {
+		// the policy was removed along with its group
+		d.SetId("")
+		return nil
+	}
 
 	group_crypto_policy_object := make(map[string]interface{})
-	cryptographic_policy := "remove"
+	cryptographic_policy := crypto_policy_remove
 	operation := "PATCH"
 	url := fmt.Sprintf("sys/v1/groups/%s", group_id)
 
+	var approval_diags diag.Diagnostics
+	var err error
 	if isSetApprovalPolicy {
-		if debug_output {
-			tflog.Warn(ctx, "[D]: Approval policy is present.")
-		}
-		group_crypto_policy_object["method"] = "PATCH"
-		group_crypto_policy_object["operation"] = url
-		group_crypto_policy_object["body"] = map[string]interface{}{"cryptographic_policy": cryptographic_policy}
-		operation = "POST"
-		url = "sys/v1/approval_requests"
+		tflog.Debug(ctx, "[D]: Approval policy is present.")
+		group_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
+		_, approval_diags, err = m.(*api_client).approvedCall(ctx, operation, url, group_crypto_policy_object, d.Timeout(schema.TimeoutDelete))
 	} else {
-		if debug_output {
-			tflog.Warn(ctx, "[D]: Approval policy is not set.")
-		}
+		tflog.Debug(ctx, "[D]: Approval policy is not set.")
 		group_crypto_policy_object["group_id"] = group_id
 		group_crypto_policy_object["cryptographic_policy"] = cryptographic_policy
+		_, approval_diags, err = m.(*api_client).APICallBodyWithApproval(ctx, operation, url, group_crypto_policy_object, d.Timeout(schema.TimeoutDelete))
 	}
-
-	resp, err := m.(*api_client).APICallBody(operation, url, group_crypto_policy_object)
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -216,32 +211,16 @@ func resourceDeleteGroupCryptoPolicy(ctx context.Context, d *schema.ResourceData
 		return diags
 	}
 
-	if debug_output {
-		resp_json, _ := json.Marshal(resp)
-		tflog.Warn(ctx, fmt.Sprintf("[D]: API response for cryptographic policy delete operation: %s", resp_json))
-	}
-
-	d.SetId(group_id)
-	return nil
+	d.SetId("")
+	return diags
 }
 
-func dataSourceGroupGetData(d *schema.ResourceData, m interface{}) (bool, string) {
+func dataSourceGroupGetData(ctx context.Context, d *schema.ResourceData, m interface{}) (bool, string) {
 
-	req, err := m.(*api_client).APICallList("GET", "sys/v1/groups")
-	if err != nil {
+	group, err := m.(*api_client).API().FindGroup(ctx, d.Get("name").(string))
+	if err != nil || group == nil {
 		return false, ""
 	}
 
-	group_id := ""
-	flag := false
-	for _, data := range req {
-		if data.(map[string]interface{})["name"].(string) == d.Get("name").(string) {
-			group_id = data.(map[string]interface{})["group_id"].(string)
-			if _, ok := data.(map[string]interface{})["approval_policy"]; ok {
-				flag = true
-			}
-		}
-	}
-
-	return flag, group_id
+	return group.Approval_policy != nil, group.Group_id
 }
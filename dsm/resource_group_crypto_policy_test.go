@@ -0,0 +1,224 @@
+package dsm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_group_crypto_policy on a group without and a group with a quorum approval policy
+func testUnitGroupCryptoPolicyConfig(key_sizes string) string {
+	return fmt.Sprintf(`
+resource "dsm_group_crypto_policy" "example_group" {
+  name = "example_group"
+  cryptographic_policy {
+    legacy_policy = "unprotect_only"
+    key_ops       = ["ENCRYPT", "DECRYPT", "SIGN", "VERIFY", "APPMANAGEABLE"]
+    aes {
+      key_sizes = [%[1]s]
+    }
+    rsa {
+      minimum_key_length = 2048
+      encryption_policy {
+        padding   = "OAEP"
+        mgf1_hash = "SHA256"
+      }
+      signature_policy {
+        padding = "PKCS1_V15"
+      }
+      signature_policy {
+        padding   = "PSS"
+        mgf1_hash = "SHA384"
+      }
+    }
+    ec {
+      elliptic_curves = ["NistP256", "NistP384"]
+    }
+    opaque {}
+  }
+}
+
+resource "dsm_group_crypto_policy" "quorum_group" {
+  name = "quorum_group"
+  cryptographic_policy {
+    aes {
+      key_sizes = [%[1]s]
+    }
+  }
+}
+`, key_sizes)
+}
+
+// testUnitCheckCryptoPolicy verifies the AES key sizes allowed by the cryptographic policy of a group.
+func testUnitCheckCryptoPolicy(m *mockDSM, name string, key_sizes ...float64) resource.TestCheckFunc {
+	return testUnitCheckMock(m, "groups", name, func(group map[string]interface{}) error {
+		policy, _ := group["cryptographic_policy"].(map[string]interface{})
+		aes, _ := policy["aes"].(map[string]interface{})
+		if fmt.Sprint(aes["key_sizes"]) != fmt.Sprint(key_sizes) {
+			return fmt.Errorf("%s allows AES key sizes %v, expected %v", name, aes["key_sizes"], key_sizes)
+		}
+		return nil
+	})
+}
+
+func TestUnitResourceGroupCryptoPolicy(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	group_id := m.seedGroup("example_group", nil)
+	quorum_group_id := m.seedGroup("quorum_group", map[string]interface{}{"approval_policy": m.quorumPolicy()})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			for _, id := range []string{group_id, quorum_group_id} {
+				if policy, ok := m.get("groups", id)["cryptographic_policy"]; ok {
+					return fmt.Errorf("cryptographic policy %v of %s was not removed", policy, id)
+				}
+			}
+			// removing the policy of a quorum group waits for the approvers
+			request := m.objects["approval_requests"].find(func(request map[string]interface{}) bool {
+				return request["operation"] == "sys/v1/groups/"+quorum_group_id && request["status"] == "PENDING"
+			})
+			if request != nil {
+				return fmt.Errorf("approval request %s to remove the cryptographic policy is still pending", request["request_id"])
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitGroupCryptoPolicyConfig("128, 256"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.example_group", "id", group_id),
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.quorum_group", "id", quorum_group_id),
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.quorum_group", "approval_policy.0.quorum.0.members.0.user", m.user_id),
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.example_group", "approval_policy.#", "0"),
+					testUnitCheckCryptoPolicy(m, "dsm_group_crypto_policy.example_group", 128, 256),
+					testUnitCheckCryptoPolicy(m, "dsm_group_crypto_policy.quorum_group", 128, 256),
+				),
+			},
+			{
+				Config: testUnitGroupCryptoPolicyConfig("256"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCryptoPolicy(m, "dsm_group_crypto_policy.example_group", 256),
+					testUnitCheckCryptoPolicy(m, "dsm_group_crypto_policy.quorum_group", 256),
+					// DSM receives each algorithm rule of the blocks
+					testUnitCheckMock(m, "groups", "dsm_group_crypto_policy.example_group", func(group map[string]interface{}) error {
+						policy, _ := group["cryptographic_policy"].(map[string]interface{})
+						expected := `map[aes:map[key_sizes:[256]] ec:map[elliptic_curves:[NistP256 NistP384]] key_ops:[APPMANAGEABLE DECRYPT ENCRYPT SIGN VERIFY] legacy_policy:unprotect_only opaque:map[] ` +
+							`rsa:map[encryption_policy:[map[padding:map[OAEP:map[mgf:map[mgf1:map[hash:SHA256]]]]]] minimum_key_length:2048 signature_policy:[map[padding:map[PKCS1_V15:map[]]] map[padding:map[PSS:map[mgf:map[mgf1:map[hash:SHA384]]]]]]]]`
+						if fmt.Sprint(policy) != expected {
+							return fmt.Errorf("unexpected cryptographic policy %v", policy)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// the policy read from DSM does not differ from the configuration
+				Config:   testUnitGroupCryptoPolicyConfig("256"),
+				PlanOnly: true,
+			},
+			{
+				// drift: the policy is changed outside of terraform, and the read reports the changed rules
+				PreConfig: func() {
+					m.mutate("groups", group_id, func(group map[string]interface{}) {
+						policy := group["cryptographic_policy"].(map[string]interface{})
+						policy["aes"] = map[string]interface{}{"key_sizes": []interface{}{128}}
+						delete(policy, "opaque")
+					})
+				},
+				RefreshState: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.example_group", "cryptographic_policy.0.aes.0.key_sizes.#", "1"),
+					resource.TestCheckTypeSetElemAttr("dsm_group_crypto_policy.example_group", "cryptographic_policy.0.aes.0.key_sizes.*", "128"),
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.example_group", "cryptographic_policy.0.opaque.#", "0"),
+					resource.TestCheckResourceAttr("dsm_group_crypto_policy.example_group", "cryptographic_policy.0.rsa.0.minimum_key_length", "2048"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testUnitGroupCryptoPolicyConfig("256"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCryptoPolicy(m, "dsm_group_crypto_policy.example_group", 256),
+					testUnitCheckMock(m, "groups", "dsm_group_crypto_policy.example_group", func(group map[string]interface{}) error {
+						if _, ok := group["cryptographic_policy"].(map[string]interface{})["opaque"]; !ok {
+							return fmt.Errorf("opaque objects are not allowed again")
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testUnitGroupCryptoPolicyConfig("100"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`expected cryptographic_policy.0.aes.0.key_sizes.\d+ to be one of \[128 192 256\]`),
+			},
+			{
+				Config: `
+resource "dsm_group_crypto_policy" "example_group" {
+  name = "example_group"
+  cryptographic_policy {
+    rsa {
+      encryption_policy {
+        padding   = "PKCS1_V15"
+        mgf1_hash = "SHA256"
+      }
+    }
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`mgf1_hash SHA256 is only allowed with the OAEP and PSS paddings, not with PKCS1_V15`),
+			},
+			{
+				Config: testUnitGroupCryptoPolicyConfig("256"),
+			},
+		},
+	})
+}
+
+func TestUnitCryptoPolicyStateUpgrade(t *testing.T) {
+	crypto_policy := resourceGroupCryptoPolicy()
+	for _, upgrader := range crypto_policy.StateUpgraders {
+		if !upgrader.Type.AttributeType("cryptographic_policy").Equals(cty.String) {
+			t.Errorf("unexpected schema version %d type: %#v", upgrader.Version, upgrader.Type)
+		}
+	}
+
+	state, err := crypto_policy.StateUpgraders[1].Upgrade(context.Background(), map[string]interface{}{
+		"name":                 "example_group",
+		"cryptographic_policy": `{"aes":{"key_sizes":[128,256]},"rsa":{"encryption_policy":[{"padding":{"OAEP":{"mgf":{"mgf1":{}}}}}]},"des":null,"secret":{},"legacy_policy":"prohibited"}`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{
+		"legacy_policy": "prohibited",
+		"key_ops":       []interface{}{},
+		"aes":           []interface{}{map[string]interface{}{"key_sizes": []interface{}{128, 256}}},
+		"rsa": []interface{}{map[string]interface{}{
+			"minimum_key_length": 0,
+			"encryption_policy":  []interface{}{map[string]interface{}{"padding": "OAEP", "mgf1_hash": ""}},
+			"signature_policy":   []interface{}{},
+		}},
+		"secret": []interface{}{map[string]interface{}{}},
+	}}
+	if !reflect.DeepEqual(state["cryptographic_policy"], expected) {
+		t.Errorf("unexpected upgraded cryptographic_policy: %#v", state["cryptographic_policy"])
+	}
+
+	state, err = crypto_policy.StateUpgraders[1].Upgrade(context.Background(), map[string]interface{}{"name": "example_group", "cryptographic_policy": "remove"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy := state["cryptographic_policy"].([]interface{}); len(policy) != 0 {
+		t.Errorf("unexpected upgraded cryptographic_policy: %#v", policy)
+	}
+}
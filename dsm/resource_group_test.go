@@ -1,11 +1,17 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
-	"testing"
 )
 
 var (
@@ -22,15 +28,475 @@ func TestAccResourceGroup(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroyGroup,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: resourceGroup_createConfig,
 			},
+			{
+				Config: resourceGroup_updateConfig,
+			},
+		},
+	})
+}
+
+func TestUnitResourceGroup(t *testing.T) {
+	m := newMockDSM(t)
+	var group_id string
+
+	config := func(name string, description string, undo int) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name                        = %q
+  description                 = %q
+  key_undo_policy_window_time = %d
+}
+`, name, description, undo)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("example_group", "created by terraform", 3600),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&group_id, "dsm_group.example_group"),
+					resource.TestCheckResourceAttrPair("dsm_group.example_group", "id", "dsm_group.example_group", "group_id"),
+					testUnitCheckMock(m, "groups", "dsm_group.example_group", func(group map[string]interface{}) error {
+						if policy, _ := group["key_history_policy"].(map[string]interface{}); policy["undo_time_window"] != float64(3600) {
+							return fmt.Errorf("key_history_policy is %v", group["key_history_policy"])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:            "dsm_group.example_group",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"key_undo_policy_window_time"},
+			},
+			{
+				Config: config("example_group_updated", "updated by terraform", 7200),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group.example_group", "name", "example_group_updated"),
+					resource.TestCheckResourceAttr("dsm_group.example_group", "acct_id", m.acct_id),
+					resource.TestCheckResourceAttr("dsm_group.example_group", "creator.user", m.user_id),
+					testUnitCheckMock(m, "groups", "dsm_group.example_group", func(group map[string]interface{}) error {
+						if group["description"] != "updated by terraform" {
+							return fmt.Errorf("description is %v", group["description"])
+						}
+						if policy, _ := group["key_history_policy"].(map[string]interface{}); policy["undo_time_window"] != float64(7200) {
+							return fmt.Errorf("key_history_policy is %v", group["key_history_policy"])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// drift: the group is renamed outside of terraform
+				PreConfig: func() {
+					m.mutate("groups", group_id, func(group map[string]interface{}) {
+						group["name"] = "renamed_in_dsm"
+						group["description"] = "changed in dsm"
+					})
+				},
+				Config: config("example_group_updated", "updated by terraform", 7200),
+				Check: testUnitCheckMock(m, "groups", "dsm_group.example_group", func(group map[string]interface{}) error {
+					if group["name"] != "example_group_updated" || group["description"] != "updated by terraform" {
+						return fmt.Errorf("drift was not corrected: %v", group)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the group is deleted outside of terraform
+				PreConfig: func() { m.remove("groups", group_id) },
+				Config:    config("example_group_updated", "updated by terraform", 7200),
+				Check: testUnitCheckMock(m, "groups", "dsm_group.example_group", func(group map[string]interface{}) error {
+					if group["group_id"] == group_id {
+						return fmt.Errorf("group %s was not created again", group_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestUnitResourceGroupHmg(t *testing.T) {
+	m := newMockDSM(t)
+	aws := `
+  hmg {
+    kind       = "AWSKMS"
+    url        = "kms.us-east-1.amazonaws.com"
+    access_key = "AKIAEXAMPLE"
+    secret_key = "aws-secret"
+    region     = %q
+    service    = "kms"
+    hsm_order  = 0
+    tls {
+      mode = "required"
+    }
+  }`
+	azure := `
+  hmg {
+    kind            = "AZUREKEYVAULT"
+    url             = "https://sampleakv.vault.azure.net/"
+    secret_key      = "azure-secret"
+    tenant_id       = "tenant"
+    client_id       = "client"
+    subscription_id = "subscription"
+    key_vault_type  = "STANDARD"
+    hsm_order       = 1
+  }`
+	gcp := `
+  hmg {
+    kind                  = "GCPKEYRING"
+    project_id            = "project"
+    location              = "us-east1"
+    key_ring              = "key_ring"
+    service_account_email = "test@test.iam.gserviceaccount.com"
+    private_key           = "Z2NwLXByaXZhdGUta2V5"
+    hsm_order             = 2
+  }`
+	config := func(hmg ...string) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "failover" {
+  name           = "failover"
+  hmg_redundancy = "PriorityFailover"
+  %s
+}
+`, strings.Join(hmg, ""))
+	}
+	var group_id string
+	hmg_ids := map[string]string{}
+	hmg_id := func(name string, index int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			hmg_ids[name] = s.RootModule().Resources["dsm_group.failover"].Primary.Attributes[fmt.Sprintf("hmg.%d.hmg_id", index)]
+			return nil
+		}
+	}
+	same_hmg_id := func(name string, index int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			if id := s.RootModule().Resources["dsm_group.failover"].Primary.Attributes[fmt.Sprintf("hmg.%d.hmg_id", index)]; id != hmg_ids[name] {
+				return fmt.Errorf("hmg %s was replaced by %s", hmg_ids[name], id)
+			}
+			return nil
+		}
+	}
+	dsm_hmg := func(check func(hmg map[string]interface{}) error) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "groups", "dsm_group.failover", func(group map[string]interface{}) error {
+			hmg, _ := group["hmg"].(map[string]interface{})
+			return check(hmg)
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(fmt.Sprintf(aws, "us-east-1"), azure),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&group_id, "dsm_group.failover"),
+					hmg_id("aws", 0),
+					hmg_id("azure", 1),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.#", "2"),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg_redundancy", "PriorityFailover"),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.0.secret_key", "aws-secret"),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.0.tls.0.ca_set", "global_roots"),
+					resource.TestCheckResourceAttrPair("dsm_group.failover", "hmg_id", "dsm_group.failover", "hmg.0.hmg_id"),
+					dsm_hmg(func(hmg map[string]interface{}) error {
+						aws, _ := hmg[hmg_ids["aws"]].(map[string]interface{})
+						azure, _ := hmg[hmg_ids["azure"]].(map[string]interface{})
+						if len(hmg) != 2 || aws["kind"] != "AWSKMS" || aws["secret_key"] != "aws-secret" || azure["kind"] != "AZUREKEYVAULT" {
+							return fmt.Errorf("unexpected hmg: %v", hmg)
+						}
+						if tls, _ := aws["tls"].(map[string]interface{}); !reflect.DeepEqual(tls["ca"], map[string]interface{}{"ca_set": "global_roots"}) {
+							return fmt.Errorf("unexpected tls: %v", aws["tls"])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// a change of a block modifies its HMG only
+				Config: config(fmt.Sprintf(aws, "us-west-2"), azure),
+				Check: resource.ComposeTestCheckFunc(
+					same_hmg_id("aws", 0),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.0.region", "us-west-2"),
+					dsm_hmg(func(hmg map[string]interface{}) error {
+						aws, _ := hmg[hmg_ids["aws"]].(map[string]interface{})
+						if len(hmg) != 2 || aws["region"] != "us-west-2" {
+							return fmt.Errorf("hmg %s was not modified: %v", hmg_ids["aws"], hmg)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// the blocks are matched by identity when they move
+				Config: config(azure, gcp),
+				Check: resource.ComposeTestCheckFunc(
+					hmg_id("gcp", 1),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.#", "2"),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.1.private_key", "Z2NwLXByaXZhdGUta2V5"),
+					same_hmg_id("azure", 0),
+					dsm_hmg(func(hmg map[string]interface{}) error {
+						_, aws := hmg[hmg_ids["aws"]]
+						gcp, _ := hmg[hmg_ids["gcp"]].(map[string]interface{})
+						if len(hmg) != 2 || aws || gcp["kind"] != "GCPKEYRING" {
+							return fmt.Errorf("unexpected hmg: %v", hmg)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// drift: an HMG is deleted outside of terraform
+				PreConfig: func() {
+					m.mutate("groups", group_id, func(group map[string]interface{}) {
+						delete(group["hmg"].(map[string]interface{}), hmg_ids["gcp"])
+					})
+				},
+				Config: config(azure, gcp),
+				Check: dsm_hmg(func(hmg map[string]interface{}) error {
+					if len(hmg) != 2 {
+						return fmt.Errorf("deleted hmg was not added again: %v", hmg)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      config(azure, azure),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("hmg 1 has the same kind, url, project_id, location and key_ring"),
+			},
+			{
+				Config: config(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg.#", "0"),
+					resource.TestCheckResourceAttr("dsm_group.failover", "hmg_id", ""),
+					dsm_hmg(func(hmg map[string]interface{}) error {
+						if len(hmg) != 0 {
+							return fmt.Errorf("hmg was not deleted: %v", hmg)
+						}
+						return nil
+					}),
+				),
+			},
 		},
 	})
 }
 
-func testAccCheckDestroyGroup(s *terraform.State) (err error) {
-	return err
+func TestUnitGroupHmgStateUpgrade(t *testing.T) {
+	if upgrader := resourceGroup().StateUpgraders[0]; !upgrader.Type.AttributeType("hmg").Equals(cty.String) {
+		t.Errorf("unexpected schema version 0 type: %#v", upgrader.Type)
+	}
+
+	state, err := groupHmgStateUpgradeV0(context.Background(), map[string]interface{}{
+		"name":   "aws_group",
+		"hmg_id": "5b3b9b52-3c86-4b8e-8b8e-2a2b3c4d5e6f",
+		"hmg":    `{"kind":"AWSKMS","url":"kms.us-east-1.amazonaws.com","secret_key":"aws-secret","tls":{"mode":"required","validate_hostname":false,"ca":{"ca_set":"global_roots"}}}`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{
+		"hmg_id":     "5b3b9b52-3c86-4b8e-8b8e-2a2b3c4d5e6f",
+		"kind":       "AWSKMS",
+		"url":        "kms.us-east-1.amazonaws.com",
+		"secret_key": "aws-secret",
+		"tls": []interface{}{map[string]interface{}{
+			"mode":              "required",
+			"validate_hostname": false,
+			"ca_set":            "global_roots",
+		}},
+	}}
+	if !reflect.DeepEqual(state["hmg"], expected) {
+		t.Errorf("unexpected upgraded hmg: %#v", state["hmg"])
+	}
+
+	state, err = groupHmgStateUpgradeV0(context.Background(), map[string]interface{}{"name": "group"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hmg := state["hmg"].([]interface{}); len(hmg) != 0 {
+		t.Errorf("unexpected upgraded hmg: %#v", hmg)
+	}
+}
+
+func TestUnitResourceGroupApprovalPolicy(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	app_ids := []string{mockUUID(), mockUUID()}
+	config := func(n int, member string) string {
+		return fmt.Sprintf(`
+resource "dsm_group" "quorum_group" {
+  name = "quorum_group"
+  approval_policy {
+    quorum {
+      n = %d
+      members {
+        %s
+      }
+      members {
+        quorum {
+          n                = 1
+          require_password = true
+          members {
+            app = %q
+          }
+          members {
+            app = %q
+          }
+        }
+      }
+    }
+    protect_permissions       = ["ROTATE_SOBJECTS", "EXPORT_SOBJECTS"]
+    protect_crypto_operations = true
+  }
+}
+`, n, member, app_ids[0], app_ids[1])
+	}
+	user := fmt.Sprintf("user = %q", m.user_id)
+	dsm_quorum := func(check func(quorum map[string]interface{}) error) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "groups", "dsm_group.quorum_group", func(group map[string]interface{}) error {
+			policy, _ := group["approval_policy"].(map[string]interface{})
+			quorum, _ := policy["quorum"].(map[string]interface{})
+			return check(quorum)
+		})
+	}
+	var group_id string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(2, user),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&group_id, "dsm_group.quorum_group"),
+					resource.TestCheckResourceAttr("dsm_group.quorum_group", "approval_policy.0.quorum.0.members.#", "2"),
+					resource.TestCheckResourceAttr("dsm_group.quorum_group", "approval_policy.0.quorum.0.members.0.user", m.user_id),
+					resource.TestCheckResourceAttr("dsm_group.quorum_group", "approval_policy.0.quorum.0.members.1.quorum.0.members.1.app", app_ids[1]),
+					resource.TestCheckResourceAttr("dsm_group.quorum_group", "approval_policy.0.protect_permissions.#", "2"),
+					dsm_quorum(func(quorum map[string]interface{}) error {
+						members, _ := quorum["members"].([]interface{})
+						if quorum["n"] != float64(2) || len(members) != 2 {
+							return fmt.Errorf("unexpected quorum: %v", quorum)
+						}
+						nested, _ := members[1].(map[string]interface{})["quorum"].(map[string]interface{})
+						if nested["require_password"] != true {
+							return fmt.Errorf("unexpected nested quorum: %v", members[1])
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// DSM returns the members in another order: no change
+				PreConfig: func() {
+					m.mutate("groups", group_id, func(group map[string]interface{}) {
+						quorum := group["approval_policy"].(map[string]interface{})["quorum"].(map[string]interface{})
+						members := quorum["members"].([]interface{})
+						nested := members[1].(map[string]interface{})["quorum"].(map[string]interface{})
+						nested_members := nested["members"].([]interface{})
+						nested["members"] = []interface{}{nested_members[1], nested_members[0]}
+						quorum["members"] = []interface{}{members[1], members[0]}
+					})
+				},
+				Config:   config(2, user),
+				PlanOnly: true,
+			},
+			{
+				// drift: the quorum is lowered outside of terraform, which
+				// the quorum has to approve back
+				PreConfig: func() {
+					m.mutate("groups", group_id, func(group map[string]interface{}) {
+						group["approval_policy"].(map[string]interface{})["quorum"].(map[string]interface{})["n"] = 1
+					})
+				},
+				Config: config(2, user),
+				Check: dsm_quorum(func(quorum map[string]interface{}) error {
+					if quorum["n"] != float64(2) {
+						return fmt.Errorf("quorum was not restored: %v", quorum)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      config(3, user),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("n is 3 but the quorum has 2 members"),
+			},
+			{
+				Config:      config(2, user+"\n        app = "+fmt.Sprintf("%q", app_ids[0])),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("member 0 of the quorum should be exactly one of user, app or quorum"),
+			},
+			{
+				Config:      config(2, `user = "not-a-uuid"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`to be a valid UUID`),
+			},
+			{
+				Config: `
+resource "dsm_group" "quorum_group" {
+  name = "quorum_group"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group.quorum_group", "approval_policy.#", "0"),
+					testUnitCheckMock(m, "groups", "dsm_group.quorum_group", func(group map[string]interface{}) error {
+						if policy, ok := group["approval_policy"]; ok {
+							return fmt.Errorf("approval policy was not removed: %v", policy)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitGroupApprovalPolicyStateUpgrade(t *testing.T) {
+	group := resourceGroup()
+	for _, upgrader := range group.StateUpgraders {
+		if !upgrader.Type.AttributeType("approval_policy").Equals(cty.String) {
+			t.Errorf("unexpected schema version %d type: %#v", upgrader.Version, upgrader.Type)
+		}
+	}
+
+	state, err := group.StateUpgraders[1].Upgrade(context.Background(), map[string]interface{}{
+		"name":            "quorum_group",
+		"approval_policy": `{"quorum":{"n":1,"members":[{"user":"54e489ca-f5aa-4e59-869e-281bbd37caa2"}],"require_2fa":true},"protect_crypto_operations":true}`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{
+		"quorum": []interface{}{map[string]interface{}{
+			"n":                1,
+			"members":          []interface{}{map[string]interface{}{"user": "54e489ca-f5aa-4e59-869e-281bbd37caa2"}},
+			"require_2fa":      true,
+			"require_password": false,
+		}},
+		"protect_crypto_operations": true,
+		"protect_manage_operations": false,
+		"protect_permissions":       []interface{}(nil),
+	}}
+	if !reflect.DeepEqual(state["approval_policy"], expected) {
+		t.Errorf("unexpected upgraded approval_policy: %#v", state["approval_policy"])
+	}
+
+	state, err = group.StateUpgraders[1].Upgrade(context.Background(), map[string]interface{}{"name": "group", "approval_policy": ""}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy := state["approval_policy"].([]interface{}); len(policy) != 0 {
+		t.Errorf("unexpected upgraded approval_policy: %#v", policy)
+	}
 }
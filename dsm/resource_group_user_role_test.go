@@ -0,0 +1,63 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_group_user_role binding an invited user to example_group
+func testUnitGroupUserRoleConfig(role_name string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_user" "example_user" {
+  user_email = "user@example.com"
+  role       = "ACCOUNTMEMBER"
+}
+
+resource "dsm_group_user_role" "example_group_user_role" {
+  group_name = dsm_group.example_group.name
+  user_email = dsm_user.example_user.user_email
+  role_name  = %q
+}
+`, role_name)
+}
+
+func TestUnitResourceGroupUserRole(t *testing.T) {
+	m := newMockDSM(t)
+	custodian := m.find("roles", "Key Custodian")["role_id"].(string)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			if user := m.find("users", "user@example.com"); user != nil {
+				return fmt.Errorf("user %v still exists", user)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitGroupUserRoleConfig("GROUPAUDITOR"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dsm_group_user_role.example_group_user_role", "user_id", "dsm_user.example_user", "user_id"),
+					resource.TestCheckResourceAttrPair("dsm_group_user_role.example_group_user_role", "group_id", "dsm_group.example_group", "group_id"),
+					resource.TestCheckResourceAttr("dsm_group_user_role.example_group_user_role", "role_id", "GROUPAUDITOR"),
+					testUnitCheckUserGroup(m, "dsm_user.example_user", "dsm_group.example_group", "GROUPAUDITOR"),
+				),
+			},
+			{
+				// custom roles are looked up by name
+				Config: testUnitGroupUserRoleConfig("Key Custodian"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_group_user_role.example_group_user_role", "role_id", custodian),
+					testUnitCheckUserGroup(m, "dsm_user.example_user", "dsm_group.example_group", custodian),
+				),
+			},
+		},
+	})
+}
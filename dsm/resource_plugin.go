@@ -15,6 +15,8 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 /*
@@ -24,26 +26,18 @@ redirected to approval_requests API.
 
 Algorithm:
 
-Create Plugin:
+Create/Update Plugin:
 
 1) Read the inputs
 2) Read each group and check if there is approval_policy
 	* if approval_policy is configured in any given groups configured,then it will redirect to approval_requests API.
-		1) ID will set as approval_request_id and plugin_id as req["request_id"]. User should approve from UI currently
-		2) Once user is approved, when user tries to do any change or apply it will read all the plugins
-		   and gets the plugin that matches the plugin name.
-		3) Adds the plugin_id, Id as req["plugin_id"] and approval_request_id as null.
-	* if approval_policy is not configured in any given groups configured,then it will create a plugin.
-
-Update Plugin:
+		1) The approval request is polled until the required users approve or deny it,
+		   or the create/update timeout is reached.
+		2) Once it is approved, the result of the request is the created/updated plugin.
+	* if approval_policy is not configured in any given groups configured,then it will create/update the plugin.
 
-1) Read the inputs
-2) Read each group and check if there is approval_policy
-	* if approval_policy is configured in any given groups configured,then it will redirect to approval_requests API.
-		1) approval_request_id will set as request_id. User should approve from UI currently
-		2) Once user is approved, when user tries to do any change or apply it will apply the new changes in state
-		   and changes approval_request_id as null
-	* if approval_policy is not configured in any given groups configured,then it will create a plugin.
+Plugins created by older versions keep their approval_request_id until the request is
+approved or denied, the read below resolves it.
 
 
 */
@@ -125,7 +119,7 @@ func resourcePlugin() *schema.Resource {
 				},
 			},
 			"approval_request_id": {
-			    Description: "If a plugin creation requires approval, then request id will be stored here.",
+			    Description: "Approval request of a plugin filed by an older provider version that is not yet approved. The provider now waits for the approval during apply.",
 				Type:     schema.TypeString,
 				Computed: true,
 			},
@@ -133,6 +127,7 @@ func resourcePlugin() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
 	}
 }
 
@@ -162,13 +157,23 @@ func resourceCreatePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 	if err := d.Get("enabled").(bool); err {
 		plugin["enabled"] = d.Get("enabled")
 	}
+	plugin_id := ""
+	var err error
 	// Checks if any group has approval policy
-	isapprovalPolicy := isApprovalPolicy(d.Get("groups").([]interface{}), m)
-	// If approval policy exists then it redirects to approval_request API
-	if isapprovalPolicy {
-		return approvalRequestCall(plugin, d, m, "POST")
+	// If approval policy exists then it waits for the approval of the quorum
+	if isApprovalPolicy(ctx, d.Get("groups").([]interface{}), m) {
+		var req map[string]interface{}
+		var approval_diags diag.Diagnostics
+		req, approval_diags, err = m.(*api_client).approvedCall(ctx, "POST", plugin_endpoint, plugin, d.Timeout(schema.TimeoutCreate))
+		diags = append(diags, approval_diags...)
+		plugin_id, _ = req["plugin_id"].(string)
+	} else {
+		var dsm_plugin *dsmclient.Plugin
+		dsm_plugin, err = m.(*api_client).API().CreatePlugin(ctx, plugin)
+		if err == nil {
+			plugin_id = dsm_plugin.Plugin_id
+		}
 	}
-	req, err := m.(*api_client).APICallBody("POST", plugin_endpoint, plugin)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -177,8 +182,16 @@ func resourceCreatePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 		})
 		return diags
 	}
-	d.SetId(req["plugin_id"].(string))
-	return resourceReadPlugin(ctx, d, m)
+	if len(plugin_id) == 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: POST %s: response without plugin_id", plugin_endpoint),
+		})
+		return diags
+	}
+	d.SetId(plugin_id)
+	return append(diags, resourceReadPlugin(ctx, d, m)...)
 }
 
 // Read
@@ -187,20 +200,16 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 	// This will be the case when approval_request API is triggered during create
 	if pid := d.Get("plugin_id").(string); len(pid) == 0 && len(d.Get("approval_request_id").(string)) > 0 {
 		// Checks whether approval_request_id is approved or not
-		req, statuscode, _ := m.(*api_client).APICall("GET", fmt.Sprintf(approval_endpoint+"/%s", d.Id()))
-		if statuscode == 200 {
-			if req["status"] == "APPROVED" {
-				req, _ := m.(*api_client).APICallList("GET", plugin_endpoint)
-				for _, data := range req {
-					if data.(map[string]interface{})["name"].(string) == d.Get("name").(string) {
-						// If plugin available the changes ID as plugin_id
-						// and approval_request_id as ""
-						d.SetId(data.(map[string]interface{})["plugin_id"].(string))
-						d.Set("approval_request_id", "")
-						break
-					}
+		approval, err := m.(*api_client).API().GetApprovalRequest(ctx, d.Id())
+		if err == nil {
+			if approval.Status == dsmclient.ApprovalApproved {
+				if dsm_plugin, _ := m.(*api_client).API().FindPlugin(ctx, d.Get("name").(string)); dsm_plugin != nil {
+					// If plugin available the changes ID as plugin_id
+					// and approval_request_id as ""
+					d.SetId(dsm_plugin.Plugin_id)
+					d.Set("approval_request_id", "")
 				}
-			} else if req["status"] == "PENDING" {
+			} else if approval.Status == dsmclient.ApprovalPending {
 				diags = append(diags, diag.Diagnostic{
 					Severity: diag.Warning,
 					Summary: "Plugin " + d.Get("name").(string) + " is not yet approved or denied from the required users." +
@@ -208,7 +217,7 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 					Detail: fmt.Sprintf("[W]: API: GET %s: %s", plugin_endpoint, d.Get("name").(string)),
 				})
 				return diags
-			} else if req["status"] == "DENIED" {
+			} else if approval.Status == dsmclient.ApprovalDenied {
 				diags = append(diags, diag.Diagnostic{
 					Severity: diag.Error,
 					Summary: "Plugin " + d.Get("name").(string) + " is denied from the required user." +
@@ -228,11 +237,11 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	// This will be executed during update
 	if approval_rq_id := d.Get("approval_request_id").(string); len(approval_rq_id) > 0 {
-		req, statuscode, _ := m.(*api_client).APICall("GET", fmt.Sprintf(approval_endpoint+"/%s", approval_rq_id))
-		if statuscode == 200 {
+		approval, err := m.(*api_client).API().GetApprovalRequest(ctx, approval_rq_id)
+		if err == nil {
 			// When it is approved or denied it will make approval_request_id as null
 			// And reads the plugin
-			if req["status"] != "PENDING" {
+			if approval.Status != dsmclient.ApprovalPending {
 				d.Set("approval_request_id", "")
 			} else {
 				diags = append(diags, diag.Diagnostic{
@@ -248,7 +257,11 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 		}
 	}
 	// reads the plugin
-	req, _, err := m.(*api_client).APICall("GET", fmt.Sprintf(plugin_endpoint+"/%s", d.Id()))
+	dsm_plugin, err := m.(*api_client).API().GetPlugin(ctx, d.Id())
+	if IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -257,27 +270,27 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 		})
 		return diags
 	}
-	if err := d.Set("name", req["name"].(string)); err != nil {
+	if err := d.Set("name", dsm_plugin.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("description", req["description"].(string)); err != nil {
+	if err := d.Set("description", dsm_plugin.Description); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("plugin_id", req["plugin_id"].(string)); err != nil {
+	if err := d.Set("plugin_id", dsm_plugin.Plugin_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("default_group", req["default_group"].(string)); err != nil {
+	if err := d.Set("default_group", dsm_plugin.Default_group); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
+	if err := d.Set("enabled", dsm_plugin.Enabled); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+	if err := d.Set("acct_id", dsm_plugin.Acct_id); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["groups"]; ok {
-		resp_groups := req["groups"].([]interface{})
-		tf_state_groups, is_tf_state_groups  := d.GetOk("groups")
+	if dsm_plugin.Groups != nil {
+		resp_groups := stringList(dsm_plugin.Groups)
+		tf_state_groups, is_tf_state_groups := d.GetOk("groups")
 		var is_same_groups bool
 		if is_tf_state_groups {
 			is_same_groups = compTwoArrays(tf_state_groups, resp_groups)
@@ -290,23 +303,21 @@ func resourceReadPlugin(ctx context.Context, d *schema.ResourceData, m interface
 			return diag.FromErr(err)
 		}
 	}
-	if err := d.Set("creator", req["creator"]); err != nil {
+	if err := d.Set("creator", dsm_plugin.Creator.Map()); err != nil {
 		return diag.FromErr(err)
 	}
-	if _, ok := req["source"]; ok {
-		if source := req["source"].(map[string]interface{}); len(source) > 0 {
-			language := ""
-			if strings.EqualFold(source["language"].(string), d.Get("language").(string)) {
-				language = d.Get("language").(string)
-			} else {
-				language = source["language"].(string)
-			}
-			if err := d.Set("language", language); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set("code", source["code"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+	if source := dsm_plugin.Source; source != nil {
+		language := ""
+		if strings.EqualFold(source.Language, d.Get("language").(string)) {
+			language = d.Get("language").(string)
+		} else {
+			language = source.Language
+		}
+		if err := d.Set("language", language); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("code", source.Code); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 	return diags
@@ -347,11 +358,14 @@ func resourceUpdatePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 	if d.HasChange("plugin_type") {
 		plugin["plugin_type"] = d.Get("plugin_type")
 	}
-	isapprovalPolicy := isApprovalPolicy(d.Get("groups").([]interface{}), m)
-	if isapprovalPolicy {
-		return approvalRequestCall(plugin, d, m, "PATCH")
+	var err error
+	if isApprovalPolicy(ctx, d.Get("groups").([]interface{}), m) {
+		var approval_diags diag.Diagnostics
+		_, approval_diags, err = m.(*api_client).approvedCall(ctx, "PATCH", fmt.Sprintf(plugin_endpoint+"/%s", d.Id()), plugin, d.Timeout(schema.TimeoutUpdate))
+		diags = append(diags, approval_diags...)
+	} else {
+		_, err = m.(*api_client).API().UpdatePlugin(ctx, d.Id(), plugin)
 	}
-	_, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf(plugin_endpoint+"/%s", d.Id()), plugin)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -360,7 +374,7 @@ func resourceUpdatePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 		})
 		return diags
 	}
-	return resourceReadPlugin(ctx, d, m)
+	return append(diags, resourceReadPlugin(ctx, d, m)...)
 }
 
 // Delete
@@ -368,11 +382,11 @@ func resourceDeletePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 	var diags diag.Diagnostics
 
 	if len(d.Get("approval_request_id").(string)) > 0 {
-		m.(*api_client).APICall("POST", fmt.Sprintf(approval_endpoint+"/%s/deny", d.Id()))
+		m.(*api_client).API().DenyApprovalRequest(ctx, d.Id())
 	}
 	if len(d.Get("plugin_id").(string)) > 0 {
-		_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf(plugin_endpoint+"/%s", d.Id()))
-		if (err != nil) && (statuscode != 404) {
+		err := m.(*api_client).API().DeletePlugin(ctx, d.Id())
+		if (err != nil) && !IsNotFound(err) {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -385,53 +399,16 @@ func resourceDeletePlugin(ctx context.Context, d *schema.ResourceData, m interfa
 	return nil
 }
 
-// Approval request call
-func approvalRequestCall(body interface{}, d *schema.ResourceData, m interface{}, method string) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	operation := plugin_endpoint
-	if method == "PATCH" {
-		operation = plugin_endpoint + "/" + d.Id()
-	}
-	approval_request_body := map[string]interface{}{
-		"operation": operation,
-		"body":      body,
-		"method":    method,
-	}
-	req, err := m.(*api_client).APICallBody("POST", approval_endpoint, approval_request_body)
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] Unable to call DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: POST %s %v", approval_endpoint, err),
-		})
-	} else {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Warning,
-			Summary:  "This plugin creation requires approval request. Please get the approval from required users in UI.",
-			Detail:   fmt.Sprintf("[W]: API: POST %s, request_id for the plugin: %s", plugin_endpoint, req["request_id"]),
-		})
-		// sets ID as request_id
-		if method == "POST" {
-			d.SetId(req["request_id"].(string))
-		}
-		d.Set("approval_request_id", req["request_id"])
-	}
-	return diags
-}
-
 // Read each group and check if there is an approval policy
-func isApprovalPolicy(group_ids []interface{}, m interface{}) bool {
+func isApprovalPolicy(ctx context.Context, group_ids []interface{}, m interface{}) bool {
 	group_ids_arr := make([]string, len(group_ids))
 	for i, v := range group_ids {
 		group_ids_arr[i] = v.(string)
 	}
 	for _, group_id := range group_ids_arr {
-		req, statuscode, _ := m.(*api_client).APICall("GET", fmt.Sprintf("sys/v1/groups/%s", group_id))
-		if statuscode == 200 {
-			if _, ok := req["approval_policy"]; ok {
-				return true
-			}
+		group, err := m.(*api_client).API().GetGroup(ctx, group_id)
+		if err == nil && group.Approval_policy != nil {
+			return true
 		}
 	}
 	return false
@@ -0,0 +1,109 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// dsm_plugin in a plain group and in a group under a quorum policy
+func testUnitPluginConfig(quorum_group_id string, description string, result string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_plugin" "example_plugin" {
+  name          = "example_plugin"
+  description   = %[2]q
+  default_group = dsm_group.example_group.group_id
+  groups        = [dsm_group.example_group.group_id]
+  code          = "function run(input) return '%[3]s' end"
+}
+
+resource "dsm_plugin" "quorum_plugin" {
+  name          = "quorum_plugin"
+  description   = %[2]q
+  default_group = %[1]q
+  groups        = [%[1]q]
+  code          = "function run(input) return '%[3]s' end"
+}
+`, quorum_group_id, description, result)
+}
+
+func TestUnitResourcePlugin(t *testing.T) {
+	m := newMockDSM(t)
+	approval_poll_interval = 10 * time.Millisecond
+	quorum_group_id := m.seedGroup("quorum_group", map[string]interface{}{"approval_policy": m.quorumPolicy()})
+	var plugin_id string
+
+	check_code := func(name string, result string) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "plugins", name, func(plugin map[string]interface{}) error {
+			source, _ := plugin["source"].(map[string]interface{})
+			if source["code"] != fmt.Sprintf("function run(input) return '%s' end", result) {
+				return fmt.Errorf("%s has code %v", name, source["code"])
+			}
+			return nil
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitPluginConfig(quorum_group_id, "created by terraform", "v1"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&plugin_id, "dsm_plugin.example_plugin"),
+					resource.TestCheckResourceAttrPair("dsm_plugin.example_plugin", "plugin_id", "dsm_plugin.example_plugin", "id"),
+					resource.TestCheckResourceAttr("dsm_plugin.example_plugin", "acct_id", m.acct_id),
+					resource.TestCheckNoResourceAttr("dsm_plugin.quorum_plugin", "approval_request_id"),
+					check_code("dsm_plugin.example_plugin", "v1"),
+					check_code("dsm_plugin.quorum_plugin", "v1"),
+				),
+			},
+			{
+				ResourceName:      "dsm_plugin.example_plugin",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the plugin type is not read back
+				ImportStateVerifyIgnore: []string{"plugin_type"},
+			},
+			{
+				Config: testUnitPluginConfig(quorum_group_id, "updated by terraform", "v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_plugin.example_plugin", "description", "updated by terraform"),
+					resource.TestCheckResourceAttr("dsm_plugin.quorum_plugin", "description", "updated by terraform"),
+					check_code("dsm_plugin.example_plugin", "v2"),
+					check_code("dsm_plugin.quorum_plugin", "v2"),
+				),
+			},
+			{
+				// drift: the plugin is disabled outside of terraform
+				PreConfig: func() {
+					m.mutate("plugins", plugin_id, func(plugin map[string]interface{}) { plugin["enabled"] = false })
+				},
+				Config: testUnitPluginConfig(quorum_group_id, "updated by terraform", "v2"),
+				Check: testUnitCheckMock(m, "plugins", "dsm_plugin.example_plugin", func(plugin map[string]interface{}) error {
+					if plugin["enabled"] != true {
+						return fmt.Errorf("drift was not corrected: %v", plugin)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the plugin is deleted outside of terraform
+				PreConfig: func() { m.remove("plugins", plugin_id) },
+				Config:    testUnitPluginConfig(quorum_group_id, "updated by terraform", "v2"),
+				Check: testUnitCheckMock(m, "plugins", "dsm_plugin.example_plugin", func(plugin map[string]interface{}) error {
+					if plugin["plugin_id"] == plugin_id {
+						return fmt.Errorf("plugin %s was not created again", plugin_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
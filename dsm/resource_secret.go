@@ -15,7 +15,11 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 // [-] Define Security Object
@@ -92,11 +96,54 @@ func resourceSecret() *schema.Resource {
 				Optional: true,
 			},
 			"value": {
-			    Description: "The value of the secret security object Base64 encoded.",
+			    Description: "The value of the secret security object Base64 encoded. At most one of value, value_wo, wrapped_value or generate should be specified: without any of them, the secret is generated.",
 				Type:      schema.TypeString,
-				Required:  true,
+				Optional:  true,
 				Sensitive: true,
+				ConflictsWith: []string{"value_wo", "wrapped_value", "generate"},
+			},
+			"value_wo": {
+				Description: "The value of the secret security object Base64 encoded, as value but write-only: it is never stored in the plan or the state.\n" +
+				"   * Requires Terraform 1.11 or later. Change value_wo_version to import a new value_wo.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				WriteOnly: true,
+				ConflictsWith: []string{"value", "wrapped_value", "generate"},
+			},
+			"value_wo_version": {
+				Description: "Version of value_wo. Changing it replaces the secret with one importing the current value_wo.",
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"wrapped_value": {
+				Description: "The value of the secret wrapped (encrypted) by the wrapping_kid security object, in base64 format. DSM unwraps it, so the plaintext never appears in the configuration or the state. It is only sent on create: changing it later does not replace the secret.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				DiffSuppressFunc: wrappedValueDiffSuppress,
+				ConflictsWith: []string{"value", "value_wo", "generate"},
+				RequiredWith: []string{"wrapping_kid", "wrapping_alg"},
+			},
+			"wrapping_kid": {
+				Description: "The ID of the security object that wrapped the wrapped_value. It should have the UNWRAPKEY permission.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{"wrapped_value"},
 			},
+			"wrapping_alg": {
+				Description: "The algorithm that wrapped the wrapped_value.\n" +
+				"   * `AES-KW`, `AES-KWP`: key wrap (RFC 3394) and key wrap with padding (RFC 5649) with an AES wrapping key.\n" +
+				"   * `RSA-OAEP`, `RSA-OAEP-256`: RSA OAEP with SHA-1 or SHA-256 with an RSA wrapping key.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{"wrapped_value"},
+				ValidateFunc: validation.StringInSlice(wrapping_alg_names, false),
+			},
+			"generate": secretGenerateSchema(),
 			"state": {
 				Description: "The state of the secret security object.\n" +
 				"   * Allowed states are: None, PreActive, Active, Deactivated, Compromised, Destroyed, Deleted.",
@@ -170,6 +217,11 @@ func resourceSecret() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts:      approvalTimeouts(),
+		CustomizeDiff: customdiff.Sequence(
+			validateSecretGenerateCustomizeDiff,
+			secretGenerateExistingCustomizeDiff,
+		),
 	}
 }
 
@@ -193,6 +245,14 @@ func resourceCreateSecret(ctx context.Context, d *schema.ResourceData, m interfa
 		plugin_object["deactivation_date"] = sobj_deactivation_date
 	}
 
+	if custom_metadata := d.Get("custom_metadata").(map[string]interface{}); len(custom_metadata) > 0 {
+		plugin_object["custom_metadata"] = custom_metadata
+	}
+
+	if enabled, ok := d.GetOkExists("enabled"); ok {
+		plugin_object["enabled"] = enabled.(bool)
+	}
+
 	if d.Get("rotate").(bool) {
 		plugin_object["operation"] = "rotate"
 		plugin_object["name"] = d.Get("rotate_from").(string)
@@ -200,16 +260,40 @@ func resourceCreateSecret(ctx context.Context, d *schema.ResourceData, m interfa
 		operation = "POST"
 	}
 
-	if err := d.Get("value").(string); len(err) > 0 {
+	value_wo, wo_diags := writeOnlyString(d, "value_wo")
+	if wo_diags.HasError() {
+		return wo_diags
+	}
+	if _, ok := d.GetOk("wrapped_value"); ok {
+		if d.Get("rotate").(bool) {
+			return invokeErrorDiagsNoSummary("rotate is not supported while importing a wrapped secret")
+		}
+		delete(plugin_object, "operation")
+		plugin_object["obj_type"] = "SECRET"
+		setUnwrapKeyRequest(d, plugin_object)
+		endpoint = "crypto/v1/unwrapkey"
+		operation = "POST"
+	} else if len(value_wo) > 0 {
+		plugin_object["value"] = value_wo
+		plugin_object["obj_type"] = "SECRET"
+	} else if err := d.Get("value").(string); len(err) > 0 {
 		plugin_object["value"] = d.Get("value").(string)
 		plugin_object["obj_type"] = "SECRET"
 	} else {
-		reqfpi, err := m.(*api_client).FindPluginId("Terraform Plugin")
-		if err != nil {
-			return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET sys/v1/plugins: %v", err))
+		// without a value, the secret is generated with the default options
+		generate := secretGenerateDefaults()
+		if generate_list := d.Get("generate").([]interface{}); len(generate_list) > 0 && generate_list[0] != nil {
+			generate = generate_list[0].(map[string]interface{})
+		}
+		value, generate_diags := generateSecret(generate)
+		if generate_diags.HasError() {
+			return generate_diags
+		}
+		plugin_object["value"] = value
+		plugin_object["obj_type"] = "SECRET"
+		if err := d.Set("generate", []interface{}{generate}); err != nil {
+			return diag.FromErr(err)
 		}
-		endpoint = fmt.Sprintf("sys/v1/plugins/%s", string(reqfpi))
-		operation = "POST"
 	}
 	allowed_key_justifications_policy, allow_exists := d.GetOk("allowed_key_justifications_policy")
 	allowed_missing_justifications, allow_missing_justifications_exists := d.GetOk("allowed_missing_justifications")
@@ -229,131 +313,116 @@ func resourceCreateSecret(ctx context.Context, d *schema.ResourceData, m interfa
 		plugin_object["google_access_reason_policy" ] = policy_data
 	}
 
-	req, err := m.(*api_client).APICallBody(operation, endpoint, plugin_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, operation, endpoint, plugin_object, approvalTimeout(d))
 	if err != nil {
-		return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: POST sys/v1/plugins: %v", err))
+		return append(approval_diags, invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: %s %s: %v", operation, endpoint, err))...)
 	}
 
-	d.SetId(req["kid"].(string))
-	return resourceReadSecret(ctx, d, m)
+	kid, ok := req["kid"].(string)
+	if !ok {
+		return append(approval_diags, invokeErrorDiagsWithSummary("[DSM SDK] Unable to parse DSM provider API client output", fmt.Sprintf("[E]: API: %s %s: response without kid", operation, endpoint))...)
+	}
+	d.SetId(kid)
+	return append(approval_diags, resourceReadSecret(ctx, d, m)...)
 }
 
 // [R]: Read Security Object
 func resourceReadSecret(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	res, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
-	if statuscode == 404 {
+	sobject, err := m.(*api_client).API().GetKey(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err))
-		}
+		return diags
+	}
+	if err != nil {
+		return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err))
+	}
 
-		if err := d.Set("name", res["name"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("group_id", res["group_id"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("obj_type", res["obj_type"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("kid", res["kid"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("acct_id", res["acct_id"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("creator", res["creator"]); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("custom_metadata", res["custom_metadata"]); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("key_ops", res["key_ops"]); err != nil {
+	if err := d.Set("name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("obj_type", sobject.Obj_type); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("custom_metadata", sobject.Custom_metadata); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("key_ops", sobject.Key_ops); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if _, ok := res["description"]; ok {
-			if err := d.Set("description", res["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+	}
+	if google_access_reason_policy := sobject.Google_access_reason_policy; google_access_reason_policy != nil {
+		allow := stringList(google_access_reason_policy.Allow)
+		tf_state_garp, is_tf_state_garp := d.GetOk("allowed_key_justifications_policy")
+		var is_same_garp bool
+		if is_tf_state_garp {
+			is_same_garp = compTwoArrays(tf_state_garp, allow)
 		}
-		if _, ok := res["google_access_reason_policy"]; ok {
-			google_access_reason_policy := res["google_access_reason_policy"].(map[string]interface{})
-			tf_state_garp, is_tf_state_garp  := d.GetOk("allowed_key_justifications_policy")
-			var is_same_garp bool
-			if is_tf_state_garp {
-				is_same_garp = compTwoArrays(tf_state_garp, google_access_reason_policy["allow"])
-			}
-			if is_same_garp {
-				if err := d.Set("allowed_key_justifications_policy", tf_state_garp); err != nil {
-					return diag.FromErr(err)
-				}
-			} else if err := d.Set("allowed_key_justifications_policy", google_access_reason_policy["allow"]); err != nil {
+		if is_same_garp {
+			if err := d.Set("allowed_key_justifications_policy", tf_state_garp); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("allowed_missing_justifications", google_access_reason_policy["allow_missing_reason"]); err != nil {
-				return diag.FromErr(err)
-			}
-		} else{
-		    /*
-		        allowed_key_justifications_policy is either Optional or Computed.
-		        It is being made as Computed, because when a key is copied, KAJ will also get copied.
-		        In this case, it will become a computed value.
-
-		        If allowed_key_justifications_policy is not set, while updating it shows a difference as it will set to null value.
-		        Hence, it needs to be set as an empty value.
-		    */
-		    empty_array := []string{}
-		    d.Set("allowed_key_justifications_policy", empty_array)
-		}
-		if err := d.Set("enabled", res["enabled"].(bool)); err != nil {
+		} else if err := d.Set("allowed_key_justifications_policy", allow); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("state", res["state"].(string)); err != nil {
+		if err := d.Set("allowed_missing_justifications", google_access_reason_policy.Allow_missing_reason); err != nil {
 			return diag.FromErr(err)
 		}
-		if rfcdate, ok := res["deactivation_date"].(string); ok {
-			// FYOO: once it's set, you can't remove deactivation date
-			layoutRFC := "2006-01-02T15:04:05Z"
-			layoutDSM := "20060102T150405Z"
-			ddate, newerr := time.Parse(layoutDSM, rfcdate)
-			if newerr != nil {
-				return diag.FromErr(newerr)
-			}
-			if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
-				return diag.FromErr(newerr)
-			}
-		}
-		if err := d.Set("copied_to", res["copied_to"]); err != nil {
-			return diag.FromErr(err)
+	} else {
+		/*
+			allowed_key_justifications_policy is either Optional or Computed.
+			It is being made as Computed, because when a key is copied, KAJ will also get copied.
+			In this case, it will become a computed value.
+
+			If allowed_key_justifications_policy is not set, while updating it shows a difference as it will set to null value.
+			Hence, it needs to be set as an empty value.
+		*/
+		empty_array := []string{}
+		d.Set("allowed_key_justifications_policy", empty_array)
+	}
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", sobject.State); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Deactivation_date != nil {
+		// FYOO: once it's set, you can't remove deactivation date
+		layoutRFC := "2006-01-02T15:04:05Z"
+		layoutDSM := "20060102T150405Z"
+		ddate, newerr := time.Parse(layoutDSM, *sobject.Deactivation_date)
+		if newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if _, ok := res["links"]; ok {
-			if links := res["links"].(map[string]interface{}); len(links) > 0 {
-				if _, copiedToExists := res["links"].(map[string]interface{})["copiedTo"]; copiedToExists {
-					if err := d.Set("copied_to", res["links"].(map[string]interface{})["copiedTo"].([]interface{})); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, copiedFromExists := res["links"].(map[string]interface{})["copiedFrom"]; copiedFromExists {
-					if err := d.Set("copied_from", res["links"].(map[string]interface{})["copiedFrom"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacementExists := res["links"].(map[string]interface{})["replacement"]; replacementExists {
-					if err := d.Set("replacement", res["links"].(map[string]interface{})["replacement"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacedExists := res["links"].(map[string]interface{})["replaced"]; replacedExists {
-					if err := d.Set("replaced", res["links"].(map[string]interface{})["replaced"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-			}
+		if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
+			return diag.FromErr(newerr)
 		}
 	}
+	// a secret without links has not been copied
+	links := sobject.Links
+	if links == nil {
+		links = &dsmclient.DSMSobjectLinks{}
+	}
+	if diags := setSobjectLinksTfState(d, links); diags != nil {
+		return diags
+	}
 	return diags
 }
 
@@ -421,10 +490,8 @@ func resourceUpdateSecret(ctx context.Context, d *schema.ResourceData, m interfa
 	}
 
 	if has_changed {
-		if debug_output {
-			tflog.Warn(ctx, "Secret has changed, calling API.")
-		}
-		_, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), plugin_object)
+		tflog.Debug(ctx, "Secret has changed, calling API.")
+		_, err := m.(*api_client).API().UpdateKey(ctx, d.Id(), plugin_object)
 		if err != nil {
 			return invokeErrorDiagsWithSummary("[DSM SDK] Unable to call DSM provider API client", fmt.Sprintf("[E]: API: PATCH crypto/v1/keys: %v", err))
 		}
@@ -437,8 +504,8 @@ func resourceUpdateSecret(ctx context.Context, d *schema.ResourceData, m interfa
 func resourceDeleteSecret(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
-	if (err != nil) && (statuscode != 404) {
+	err := m.(*api_client).API().DeleteKey(ctx, d.Id())
+	if (err != nil) && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
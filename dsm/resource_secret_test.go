@@ -0,0 +1,358 @@
+package dsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// dsm_secret with the given attributes
+func testUnitSecretConfig(name string, description string, enabled bool) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_secret" "example_secret" {
+  name        = %q
+  group_id    = dsm_group.example_group.group_id
+  value       = "c2VjcmV0IHZhbHVl"
+  description = %q
+  enabled     = %t
+  custom_metadata = {
+    owner = "terraform"
+  }
+}
+`, name, description, enabled)
+}
+
+func TestUnitResourceSecret(t *testing.T) {
+	m := newMockDSM(t)
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitSecretConfig("example_secret", "created by terraform", true),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_secret.example_secret"),
+					resource.TestCheckResourceAttr("dsm_secret.example_secret", "obj_type", "SECRET"),
+					resource.TestCheckResourceAttr("dsm_secret.example_secret", "state", "Active"),
+					resource.TestCheckResourceAttr("dsm_secret.example_secret", "acct_id", m.acct_id),
+					testUnitCheckMock(m, "keys", "dsm_secret.example_secret", func(key map[string]interface{}) error {
+						if key["origin"] != "External" || key["key_size"] != float64(96) {
+							return fmt.Errorf("secret was not imported: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:      "dsm_secret.example_secret",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// DSM never returns the value of a secret
+				ImportStateVerifyIgnore: []string{"value"},
+			},
+			{
+				Config: testUnitSecretConfig("example_secret_updated", "updated by terraform", false),
+				Check: testUnitCheckMock(m, "keys", "dsm_secret.example_secret", func(key map[string]interface{}) error {
+					if key["name"] != "example_secret_updated" || key["description"] != "updated by terraform" || key["enabled"] != false {
+						return fmt.Errorf("secret was not updated: %v", key)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the secret is changed outside of terraform
+				PreConfig: func() {
+					m.mutate("keys", kid, func(key map[string]interface{}) {
+						key["name"] = "renamed_in_dsm"
+						key["custom_metadata"] = map[string]interface{}{}
+					})
+				},
+				Config: testUnitSecretConfig("example_secret_updated", "updated by terraform", false),
+				Check: testUnitCheckMock(m, "keys", "dsm_secret.example_secret", func(key map[string]interface{}) error {
+					metadata, _ := key["custom_metadata"].(map[string]interface{})
+					if key["name"] != "example_secret_updated" || metadata["owner"] != "terraform" {
+						return fmt.Errorf("drift was not corrected: %v", key)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the secret is deleted outside of terraform
+				PreConfig: func() { m.remove("keys", kid) },
+				Config:    testUnitSecretConfig("example_secret_updated", "updated by terraform", false),
+				Check: testUnitCheckMock(m, "keys", "dsm_secret.example_secret", func(key map[string]interface{}) error {
+					if key["kid"] == kid {
+						return fmt.Errorf("secret %s was not created again", kid)
+					}
+					return nil
+				}),
+			},
+			{
+				Config: testUnitSecretConfig("example_secret_updated", "updated by terraform", false) + `
+resource "dsm_secret" "rotated" {
+  name        = "rotated"
+  group_id    = dsm_group.example_group.group_id
+  value       = "cm90YXRlZCB2YWx1ZQ=="
+  rotate      = true
+  rotate_from = dsm_secret.example_secret.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_secret.rotated", "name", "example_secret_updated"),
+					resource.TestCheckResourceAttrPair("dsm_secret.rotated", "replaced", "dsm_secret.example_secret", "kid"),
+				),
+				// the new secret takes the name of the rotated one, which DSM
+				// renames: both names differ from the configuration afterwards
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestUnitResourceSecretWriteOnly(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	server, schemas := testUnitProtoV5Server(t)
+
+	values := map[string]tftypes.Value{
+		"name":             tftypes.NewValue(tftypes.String, "example_secret"),
+		"group_id":         tftypes.NewValue(tftypes.String, group_id),
+		"value_wo":         tftypes.NewValue(tftypes.String, "d3JpdGUtb25seSBzZWNyZXQ="),
+		"value_wo_version": tftypes.NewValue(tftypes.Number, 1),
+	}
+	state := testUnitCreate(t, server, schemas, "dsm_secret", values)
+	if !state["value_wo"].IsNull() || !state["value"].IsNull() {
+		t.Errorf("the value is stored in the state: %v", state)
+	}
+	key := m.find("keys", "example_secret")
+	if key == nil || key["origin"] != "External" || key["key_size"] != float64(8*len("write-only secret")) {
+		t.Errorf("secret was not imported: %v", key)
+	}
+
+	// a client without write-only support cannot configure value_wo
+	validated, err := server.ValidateResourceTypeConfig(context.Background(), &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName: "dsm_secret",
+		Config:   testUnitDynamicValue(t, schemas.ResourceSchemas["dsm_secret"], values),
+	})
+	if err != nil || !strings.Contains(testUnitProtoV5Error(validated.Diagnostics), "Write-only Attribute Not Allowed") {
+		t.Errorf("value_wo is allowed without client support: %v %s", err, testUnitProtoV5Error(validated.Diagnostics))
+	}
+}
+
+func TestUnitResourceSecretWrappedImport(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	kek := []byte("0123456789abcdef")
+	kid := m.seedKey(t, map[string]interface{}{
+		"name":     "wrapping_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_ops":  []interface{}{"WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+		"value":    base64.StdEncoding.EncodeToString(kek),
+	})
+	wrapped, err := mockKeyWrap(kek, []byte("a secret of 24 bytes....."[:24]), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "dsm_secret" "example_secret" {
+  name          = "example_secret"
+  group_id      = %q
+  wrapped_value = %q
+  wrapping_kid  = %q
+  wrapping_alg  = "AES-KW"
+  enabled       = true
+}
+`, group_id, base64.StdEncoding.EncodeToString(wrapped), kid),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_secret.example_secret", "obj_type", "SECRET"),
+					resource.TestCheckNoResourceAttr("dsm_secret.example_secret", "value"),
+					testUnitCheckMock(m, "keys", "dsm_secret.example_secret", func(key map[string]interface{}) error {
+						if string(m.values[key["kid"].(string)]) != "a secret of 24 bytes...." {
+							return fmt.Errorf("secret was not unwrapped: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitResourceSecretGenerate(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	config := func(generate string) string {
+		return fmt.Sprintf(`
+resource "dsm_secret" "generated" {
+  name     = "generated"
+  group_id = %q
+  enabled  = true
+  generate {
+    %s
+  }
+}
+`, group_id, generate)
+	}
+	generated := func(check func(secret string) error) resource.TestCheckFunc {
+		return testUnitCheckMock(m, "keys", "dsm_secret.generated", func(key map[string]interface{}) error {
+			if key["obj_type"] != "SECRET" {
+				return fmt.Errorf("secret was not imported: %v", key)
+			}
+			return check(string(m.values[key["kid"].(string)]))
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("length             = 40\n    min_special        = 5\n    min_numeric        = 5\n    exclude_characters = \"aeiou\""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("dsm_secret.generated", "value"),
+					resource.TestCheckResourceAttr("dsm_secret.generated", "generate.0.output", "characters"),
+					generated(func(secret string) error {
+						special := 0
+						for _, c := range secret {
+							if strings.ContainsRune(secret_generate_classes[3].chars, c) {
+								special++
+							}
+						}
+						if len(secret) != 40 || special < 5 || strings.ContainsAny(secret, "aeiou") {
+							return fmt.Errorf("unexpected generated secret %q", secret)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: config("length    = 16\n    output    = \"hex\""),
+				Check: generated(func(secret string) error {
+					if decoded, err := hex.DecodeString(secret); err != nil || len(decoded) != 16 {
+						return fmt.Errorf("unexpected generated secret %q", secret)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      config("numeric     = false\n    min_numeric = 2"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("min_numeric is 2 but numeric characters are disabled"),
+			},
+			{
+				Config:      config("length      = 4\n    min_special = 3\n    min_numeric = 3"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("length 4 is less than the sum 6 of the minimum character counts"),
+			},
+			{
+				Config:      config("lowercase          = false\n    uppercase          = false\n    special            = false\n    exclude_characters = \"0123456789\""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("exclude_characters excludes all the characters of the enabled classes"),
+			},
+			{
+				Config: config("length = 8\n    output = \"raw\""),
+				Check: generated(func(secret string) error {
+					if len(secret) != 8 {
+						return fmt.Errorf("unexpected generated secret %q", secret)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+// A secret without a value, as an older provider required value = "" for it,
+// is generated with the default options, and adding a generate block to it
+// does not replace it.
+func TestUnitResourceSecretGenerateDefault(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	kid := m.seedKey(t, map[string]interface{}{
+		"name":     "baseline",
+		"group_id": group_id,
+		"obj_type": "SECRET",
+		"value":    "YmFzZWxpbmUgc2VjcmV0",
+	})
+	config := func(value string) string {
+		return fmt.Sprintf(`
+resource "dsm_secret" "baseline" {
+  name     = "baseline"
+  group_id = %q
+  enabled  = true
+  %s
+}
+`, group_id, value)
+	}
+	rotated := `
+resource "dsm_secret" "rotated" {
+  name        = "rotated"
+  group_id    = dsm_secret.baseline.group_id
+  rotate      = true
+  rotate_from = dsm_secret.baseline.name
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the state of a secret created by an older provider has no generate block
+				Config:             config(`value    = ""`),
+				ResourceName:       "dsm_secret.baseline",
+				ImportState:        true,
+				ImportStateId:      kid,
+				ImportStatePersist: true,
+			},
+			{
+				Config:   config(`value    = ""`),
+				PlanOnly: true,
+			},
+			{
+				Config:   config("generate {}"),
+				PlanOnly: true,
+			},
+			{
+				Config:   config("generate {\n    length = 16\n  }"),
+				PlanOnly: true,
+			},
+			{
+				Config: config(`value    = ""`) + rotated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dsm_secret.rotated", "replaced", "dsm_secret.baseline", "kid"),
+					resource.TestCheckResourceAttr("dsm_secret.rotated", "generate.0.length", "32"),
+					testUnitCheckMock(m, "keys", "dsm_secret.rotated", func(key map[string]interface{}) error {
+						if secret := m.values[key["kid"].(string)]; len(secret) != 32 {
+							return fmt.Errorf("unexpected generated secret %q", secret)
+						}
+						return nil
+					}),
+				),
+				// the new secret takes the name of the rotated one
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
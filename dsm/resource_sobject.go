@@ -9,20 +9,29 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"terraform-provider-dsm/internal/dsmclient"
 )
 
 // [-] Define Security Object
 func resourceSobject() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceCreateSobject,
 		ReadContext:   resourceReadSobject,
 		UpdateContext: resourceUpdateSobject,
 		DeleteContext: resourceDeleteSobject,
+		CustomizeDiff: customdiff.Sequence(
+			validateSobjectCustomizeDiff,
+			sobjectRotationCustomizeDiff("replaced", "pub_key", "ssh_pub_key"),
+		),
 		Description: "Creates a new security object. The returned resource object contains the UUID of the security object for further references.\n" +
 		"A key value can be imported as a security object. This resource also can rotate or copy a security object.\n" +
+		"The obj_type, key_size, elliptic_curve, hash_alg, subgroup_size, bls, lms and key_ops combinations are validated during plan.\n" +
 		"For more examples, please refer Guides/dsm_security_object",
+		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 			"name": {
 			    Description: "The security object name.",
@@ -89,6 +98,39 @@ func resourceSobject() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"rotation_triggers": {
+				Description: "Arbitrary map of values that, when changed, rotates the security object in place: DSM rekeys it and the resource moves to the new key.\n" +
+				"   * Setting the first triggers does not rotate the security object.",
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"rotate_after_days": {
+				Description: "Rotates the security object in place when it is older than the given number of days at plan time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"previous_kid": {
+				Description: "The security object ID that the last in-place rotation replaced.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rotated_kids": {
+				Description: "The security object IDs that in-place rotations replaced, oldest first. They are deleted with the security object.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"created_at": {
+				Description: "The creation date of the security object in RFC format.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"creator": {
 			    Description: "The creator of the security object from Fortanix DSM.\n" +
 			    "   * `user`: If the security object was created by a user, the computed value will be the matching user id.\n" +
@@ -99,20 +141,9 @@ func resourceSobject() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-			"rotation_policy": {
-				Description: "Policy to rotate a Security Object, configure the below parameters. This is not supported while importing the security object.\n" +
-				"   * `interval_days`: Rotate the key for every given number of days.\n" +
-				"   * `interval_months`: Rotate the key for every given number of months.\n" +
-				"   * `effective_at`: Start of the rotation policy time.\n" +
-				"   * `rotate_copied_keys`: Enable key rotation for copied keys.\n" +
-				"   * `deactivate_rotated_key`: Deactivate original key after rotation true/false.\n" +
+			"rotation_policy": rotationPolicySchema("Policy to rotate a Security Object. This is not supported while importing the security object.\n" +
 				"   * **Note:** Either interval_days or interval_months should be given, but not both.",
-				Type:     schema.TypeMap,
-				Optional: true,
-				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-				},
-			},
+				"rotate_copied_keys", "deactivate_rotated_key"),
 			// Unable to define links
 			//"links": {
 			//	Type:     schema.TypeMap,
@@ -195,10 +226,12 @@ func resourceSobject() *schema.Resource {
 				"    }\n" +
 				"\nThis is how we can reference this fpeOptions:\n" +
 				"      fpe = var.fpeOptionsExample\n" +
-				"\nRefer to the fpeOptions schema in https://www.fortanix.com/fortanix-restful-api-references/dsm for a better understanding of the fpe body.\n" +
+				"\nRefer to the fpeOptions schema in https://www.fortanix.com/fortanix-restful-api-references/dsm for a better understanding of the fpe body. Options that DSM adds when they are not given, e.g. name, do not differ from the configuration.\n" +
 				"```",
 				Type:     schema.TypeString,
 				Optional: true,
+				DiffSuppressFunc: jsonDiffSuppressWithDefaults(sobject_fpe_defaults),
+				StateFunc:        normalizeJSON,
 			},
 			"key_ops": {
 			    Description: " The security object key permission from Fortanix DSM.\n" +
@@ -212,9 +245,12 @@ func resourceSobject() *schema.Resource {
 			},
 			"rsa": {
 			    Description: "rsaOptions passed as a string (if ”RSA” obj_type is specified). The string should match the 'rsa' value in Post body while working with Fortanix Rest API. For Example:\n" +
-			    "\n`rsa = " + "\"{\\" + "\"encryption_policy\\\"" + ":[{\\" + "\"padding\\\"" + ":{\\" + "\"RAW_DECRYPT\\\"" + ":{}}},{\\" + "\"padding\\\"" + ":{\\" + "\"OAEP\\\"" + ":{\\" + "\"mgf\\\"" + ":{\\" + "\"mgf1\\\"" + ":{\\" + "\"hash\\\"" + ":\\"+ "\"SHA1\\\""+ "}}}}}],\\"+ "\"signature_policy\\\"" + ":[{\\" + "\"padding\\\"" + ":{\\" + "\"PKCS1_V15\\\"" + ":{}}},{\\" + "\"padding\\\"" + ":{\\" + "\"PSS\\\"" + ":{\\" + "\"mgf\\\"" + ":{\\" + "\"mgf1\\\"" + ":{\\" + "\"hash\\\"" + ":\\" + "\"SHA384\\\"" + "}}}}}]}" + "\"" + "`",
+			    "\n`rsa = " + "\"{\\" + "\"encryption_policy\\\"" + ":[{\\" + "\"padding\\\"" + ":{\\" + "\"RAW_DECRYPT\\\"" + ":{}}},{\\" + "\"padding\\\"" + ":{\\" + "\"OAEP\\\"" + ":{\\" + "\"mgf\\\"" + ":{\\" + "\"mgf1\\\"" + ":{\\" + "\"hash\\\"" + ":\\"+ "\"SHA1\\\""+ "}}}}}],\\"+ "\"signature_policy\\\"" + ":[{\\" + "\"padding\\\"" + ":{\\" + "\"PKCS1_V15\\\"" + ":{}}},{\\" + "\"padding\\\"" + ":{\\" + "\"PSS\\\"" + ":{\\" + "\"mgf\\\"" + ":{\\" + "\"mgf1\\\"" + ":{\\" + "\"hash\\\"" + ":\\" + "\"SHA384\\\"" + "}}}}}]}" + "\"" + "`\n\n" +
+			    "Options that DSM adds when they are not given, e.g. public_exponent, do not differ from the configuration.",
 				Type:     schema.TypeString,
 				Optional: true,
+				DiffSuppressFunc: jsonDiffSuppressWithDefaults(sobject_rsa_defaults),
+				StateFunc:        normalizeJSON,
 			},
 			"allowed_key_justifications_policy": {
 			    Description: "The security object key justification policies for GCP External Key Manager. The allowed permissions are:\n" +
@@ -298,6 +334,50 @@ func resourceSobject() *schema.Resource {
 				"| `Opaque` | - | APPMANAGEABLE, EXPORT |\n",
 				Type:     schema.TypeString,
 				Optional: true,
+				ConflictsWith: []string{"value_wo"},
+			},
+			"value_wo": {
+				Description: "Sobject content when importing content, as value but write-only: it is never stored in the plan or the state.\n" +
+				"   * Requires Terraform 1.11 or later. Change value_wo_version to import a new value_wo.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				WriteOnly: true,
+				ConflictsWith: []string{"value"},
+			},
+			"value_wo_version": {
+				Description: "Version of value_wo. Changing it replaces the security object with one importing the current value_wo.",
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"wrapped_value": {
+				Description: "Sobject content when importing content wrapped (encrypted) by the wrapping_kid security object, in base64 format. DSM unwraps it, so the plaintext never appears in the configuration or the state.\n" +
+				"   * The obj_type of the imported security object should be specified.\n" +
+				"   * It is only sent on create: changing it later does not replace the security object.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				DiffSuppressFunc: wrappedValueDiffSuppress,
+				ConflictsWith: []string{"value", "value_wo", "key"},
+				RequiredWith: []string{"wrapping_kid", "wrapping_alg"},
+			},
+			"wrapping_kid": {
+				Description: "The ID of the security object that wrapped the wrapped_value. It should have the UNWRAPKEY permission.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{"wrapped_value"},
+			},
+			"wrapping_alg": {
+				Description: "The algorithm that wrapped the wrapped_value.\n" +
+				"   * `AES-KW`, `AES-KWP`: key wrap (RFC 3394) and key wrap with padding (RFC 5649) with an AES wrapping key.\n" +
+				"   * `RSA-OAEP`, `RSA-OAEP-256`: RSA OAEP with SHA-1 or SHA-256 with an RSA wrapping key.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{"wrapped_value"},
+				ValidateFunc: validation.StringInSlice(wrapping_alg_names, false),
 			},
 			"subgroup_size": {
 				Description: "Subgroup Size for DSA and ECKCDSA. The allowed Subgroup Sizes are 224 and 256.\n\n" +
@@ -313,7 +393,7 @@ func resourceSobject() *schema.Resource {
 			    Description: "Hashing Algorithm for KCDSA and ECKCDSA.\n\n" +
 				"| obj_type | hash_alg |\n" +
 				"| -------- | -------- |\n"+
-				"| `ECKCDSA` | SHA1,SHA224, SHA256, SHA384, SHA521|\n" +
+				"| `ECKCDSA` | SHA1,SHA224, SHA256, SHA384, SHA512|\n" +
 				"| `KCDSA` | SHA224, SHA256 |\n",
 				Type:     schema.TypeString,
 				Optional: true,
@@ -364,11 +444,31 @@ func resourceSobject() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: approvalTimeouts(),
+	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		rotationPolicyStateUpgrader(resource),
 	}
+	return resource
 }
 
 // global variables
 var error_summary = "[DSM SDK] Unable to call DSM provider API client:"
+
+// Options that DSM adds to the rsa and fpe of a security object when they are
+// not given, with their value or nil for any value.
+var (
+	sobject_rsa_defaults = map[string]interface{}{
+		"key_size":          nil,
+		"public_exponent":   65537,
+		"encryption_policy": []interface{}{map[string]interface{}{"padding": nil}},
+		"signature_policy":  []interface{}{map[string]interface{}{"padding": nil}},
+	}
+	sobject_fpe_defaults = map[string]interface{}{
+		"name": nil,
+	}
+)
+
 // [-]: Custom Functions
 // contains: Need to validate whether a string exists in a []string
 func contains(s []string, str string) bool {
@@ -392,6 +492,15 @@ func unmarshalStringToJson(inputString string) (interface{}, error) {
 	return inputMap, nil
 }
 
+// [-]: rsa or fpe options of a security object as a normalized JSON string
+func sobjectOptionsJSON(options map[string]interface{}) string {
+	b, err := json.Marshal(options)
+	if err != nil {
+		return ""
+	}
+	return normalizeJSON(string(b))
+}
+
 // createSO: Create Security Object
 func createSO(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
@@ -415,9 +524,26 @@ func createSO(ctx context.Context, d *schema.ResourceData, m interface{}) diag.D
 		"description": d.Get("description").(string),
 	}
 
-	if _, ok := d.GetOk("value"); ok {
+	value_wo, wo_diags := writeOnlyString(d, "value_wo")
+	if wo_diags.HasError() {
+		return wo_diags
+	}
+	if _, ok := d.GetOk("wrapped_value"); ok {
+		if len(obj_type) == 0 {
+			return invokeErrorDiagsNoSummary("obj_type should be specified while importing a wrapped security object")
+		}
+		if len(d.Get("rotate").(string)) > 0 {
+			return invokeErrorDiagsNoSummary("rotate is not supported while importing a wrapped security object")
+		}
+		security_object["obj_type"] = obj_type
+		setUnwrapKeyRequest(d, security_object)
+		endpoint = "crypto/v1/unwrapkey"
+	} else if _, ok := d.GetOk("value"); ok || len(value_wo) > 0 {
 		security_object["obj_type"] = obj_type
 		security_object["value"] = d.Get("value").(string)
+		if len(value_wo) > 0 {
+			security_object["value"] = value_wo
+		}
 		method = "PUT"
 	} else if _, ok := d.GetOk("key"); ok{
 		// copy a key logic
@@ -550,8 +676,12 @@ func createSO(ctx context.Context, d *schema.ResourceData, m interface{}) diag.D
 	if err := d.Get("custom_metadata").(map[string]interface{}); len(err) > 0 {
 		security_object["custom_metadata"] = err
 	}
-	if rotation_policy := d.Get("rotation_policy").(map[string]interface{}); len(rotation_policy) > 0 {
-		security_object["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+	rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+	if date_error != nil {
+		return date_error
+	}
+	if len(rotation_policy) > 0 {
+		security_object["rotation_policy"] = rotation_policy
 	}
 	if len(hash_alg) > 0 && obj_type == "KCDSA" {
 		kcdsa := make(map[string]interface{})
@@ -574,7 +704,8 @@ func createSO(ctx context.Context, d *schema.ResourceData, m interface{}) diag.D
 		security_object["name"] = d.Get("rotate_from").(string)
 		endpoint = "crypto/v1/keys/rekey"
 	}
-	req, err := m.(*api_client).APICallBody(method, endpoint, security_object)
+	req, approval_diags, err := m.(*api_client).APICallBodyWithApproval(ctx, method, endpoint, security_object, approvalTimeout(d))
+	diags = append(diags, approval_diags...)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -584,7 +715,16 @@ func createSO(ctx context.Context, d *schema.ResourceData, m interface{}) diag.D
 		return diags
 	}
 
-	d.SetId(req["kid"].(string))
+	kid, ok := req["kid"].(string)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to parse DSM provider API client output",
+			Detail:   fmt.Sprintf("[E]: API: %s %s: response without kid", method, endpoint),
+		})
+		return diags
+	}
+	d.SetId(kid)
 	return diags
 }
 
@@ -603,270 +743,253 @@ func resourceCreateSobject(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
-	if err := createSO(ctx, d, m); err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "[DSM SDK] Unable to call DSM provider API client",
-			Detail:   fmt.Sprintf("[E]: API: POST crypto/v1/keys: %v", err),
-		})
-		return diags
+	// createSO returns the progress of a quorum approval as warnings
+	create_diags := createSO(ctx, d, m)
+	if create_diags.HasError() {
+		return append(diags, create_diags...)
 	}
 
-	return resourceReadSobject(ctx, d, m)
+	return append(create_diags, resourceReadSobject(ctx, d, m)...)
 }
 
 // [R]: Terraform Func: resourceReadSobject
 func resourceReadSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("crypto/v1/keys/%s?show_destroyed=true&show_deleted=true", d.Id()))
-	if statuscode == 404 {
+	sobject, err := m.(*api_client).API().GetKeyAnyState(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "[DSM SDK] Unable to call DSM provider API client",
-				Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
-			})
-			return diags
-		}
-
-		if err := d.Set("dsm_name", req["name"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "[DSM SDK] Unable to call DSM provider API client",
+			Detail:   fmt.Sprintf("[E]: API: GET crypto/v1/keys: %v", err),
+		})
+		return diags
+	}
 
-		if err := d.Set("group_id", req["group_id"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("obj_type", req["obj_type"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		obj_type := req["obj_type"].(string)
-		if req["origin"] != "External" {
-			if _, ok := req["key_size"]; ok {
-				if err := d.Set("key_size", int(req["key_size"].(float64))); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-			if _, ok := req["elliptic_curve"]; ok {
-				if err := d.Set("elliptic_curve", req["elliptic_curve"].(string)); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-		}
-		if _, ok := req["google_access_reason_policy"]; ok {
-			google_access_reason_policy := req["google_access_reason_policy"].(map[string]interface{})
-			tf_state_garp, is_tf_state_garp  := d.GetOk("allowed_key_justifications_policy")
-			var is_same_garp bool
-			if is_tf_state_garp {
-				is_same_garp = compTwoArrays(tf_state_garp, google_access_reason_policy["allow"])
-			}
-			if is_same_garp {
-				if err := d.Set("allowed_key_justifications_policy", tf_state_garp); err != nil {
-					return diag.FromErr(err)
-				}
-			} else if err := d.Set("allowed_key_justifications_policy", google_access_reason_policy["allow"]); err != nil {
+	if err := d.Set("dsm_name", sobject.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("group_id", sobject.Group_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("obj_type", sobject.Obj_type); err != nil {
+		return diag.FromErr(err)
+	}
+	obj_type := sobject.Obj_type
+	if sobject.Origin != "External" {
+		if sobject.Key_size != nil {
+			if err := d.Set("key_size", *sobject.Key_size); err != nil {
 				return diag.FromErr(err)
 			}
-			if err := d.Set("allowed_missing_justifications", google_access_reason_policy["allow_missing_reason"]); err != nil {
+		}
+		if sobject.Elliptic_curve != nil {
+			if err := d.Set("elliptic_curve", *sobject.Elliptic_curve); err != nil {
 				return diag.FromErr(err)
 			}
-		} else{
-		    /*
-		        allowed_key_justifications_policy is either Optional or Computed.
-		        It is being made as Computed, because when a key is copied, KAJ will also get copied.
-		        In this case, it will become a computed value.
-
-		        If allowed_key_justifications_policy is not set, while updating it shows a difference as it will set to null value.
-		        Hence, it needs to set as an empty value.
-		    */
-		    empty_array := []string{}
-		    d.Set("allowed_key_justifications_policy", empty_array)
 		}
-		if err := d.Set("kid", req["kid"].(string)); err != nil {
-			return diag.FromErr(err)
+	}
+	if google_access_reason_policy := sobject.Google_access_reason_policy; google_access_reason_policy != nil {
+		allow := stringList(google_access_reason_policy.Allow)
+		tf_state_garp, is_tf_state_garp := d.GetOk("allowed_key_justifications_policy")
+		var is_same_garp bool
+		if is_tf_state_garp {
+			is_same_garp = compTwoArrays(tf_state_garp, allow)
 		}
-		if _, ok := req["pub_key"]; ok {
-			if err := d.Set("pub_key", req["pub_key"].(string)); err != nil {
+		if is_same_garp {
+			if err := d.Set("allowed_key_justifications_policy", tf_state_garp); err != nil {
 				return diag.FromErr(err)
 			}
-		}
-		if err := d.Set("acct_id", req["acct_id"].(string)); err != nil {
+		} else if err := d.Set("allowed_key_justifications_policy", allow); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("creator", req["creator"]); err != nil {
+		if err := d.Set("allowed_missing_justifications", google_access_reason_policy.Allow_missing_reason); err != nil {
 			return diag.FromErr(err)
 		}
-		//if err := d.Set("links", req["links"]); err != nil {
-		//	return diag.FromErr(err)
-		//}
-		// FYOO: Fix this later - some wierd reaction to TypeList/TypeMap within TF
-		if err := d.Set("copied_to", req["copied_to"]); err != nil {
+	} else {
+		/*
+			allowed_key_justifications_policy is either Optional or Computed.
+			It is being made as Computed, because when a key is copied, KAJ will also get copied.
+			In this case, it will become a computed value.
+
+			If allowed_key_justifications_policy is not set, while updating it shows a difference as it will set to null value.
+			Hence, it needs to set as an empty value.
+		*/
+		empty_array := []string{}
+		d.Set("allowed_key_justifications_policy", empty_array)
+	}
+	if err := d.Set("kid", sobject.Kid); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Pub_key != nil {
+		if err := d.Set("pub_key", *sobject.Pub_key); err != nil {
 			return diag.FromErr(err)
 		}
-
-		if _, ok := req["links"]; ok {
-			if links := req["links"].(map[string]interface{}); len(links) > 0 {
-				if _, copiedToExists := req["links"].(map[string]interface{})["copiedTo"]; copiedToExists {
-					if err := d.Set("copied_to", req["links"].(map[string]interface{})["copiedTo"].([]interface{})); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, copiedFromExists := req["links"].(map[string]interface{})["copiedFrom"]; copiedFromExists {
-					if err := d.Set("copied_from", req["links"].(map[string]interface{})["copiedFrom"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacementExists := req["links"].(map[string]interface{})["replacement"]; replacementExists {
-					if err := d.Set("replacement", req["links"].(map[string]interface{})["replacement"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-				if _, replacedExists := req["links"].(map[string]interface{})["replaced"]; replacedExists {
-					if err := d.Set("replaced", req["links"].(map[string]interface{})["replaced"].(string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
+	}
+	if err := d.Set("acct_id", sobject.Acct_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("creator", sobject.Creator.Map()); err != nil {
+		return diag.FromErr(err)
+	}
+	// a security object without links has not been copied
+	links := sobject.Links
+	if links == nil {
+		links = &dsmclient.DSMSobjectLinks{}
+	}
+	if diags := setSobjectLinksTfState(d, links); diags != nil {
+		return diags
+	}
+	if err := d.Set("custom_metadata", sobject.Custom_metadata); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Fpe != nil {
+		if radix, ok := sobject.Fpe["radix"].(float64); ok && d.Get("fpe_radix") != nil {
+			if err := d.Set("fpe_radix", int(radix)); err != nil {
+				return diag.FromErr(err)
+			}
+		} else if len(d.Get("fpe").(string)) > 0 {
+			if err := d.Set("fpe", sobjectOptionsJSON(sobject.Fpe)); err != nil {
+				return diag.FromErr(err)
 			}
 		}
-		if err := d.Set("custom_metadata", req["custom_metadata"]); err != nil {
+	}
+	// rsa is only read back when it is configured, DSM returns it for every RSA key
+	if sobject.Rsa != nil && len(d.Get("rsa").(string)) > 0 {
+		if err := d.Set("rsa", sobjectOptionsJSON(sobject.Rsa)); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := req["fpe"]; err != nil {
-			if req["fpe"].(map[string]interface{})["radix"] != nil && d.Get("fpe_radix") != nil {
-				if err := d.Set("fpe_radix", int(req["fpe"].(map[string]interface{})["radix"].(float64))); err != nil {
-					return diag.FromErr(err)
-				}
-			} else {
-				if err := d.Set("fpe", (d.Get("fpe").(string)) ); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-		}
-		// FYOO: Fix TypeList sorting error
-		key_ops := make([]string, len(req["key_ops"].([]interface{})))
-		if err := d.Get("key_ops").([]interface{}); len(err) > 0 {
-			if len(d.Get("key_ops").([]interface{})) == len(req["key_ops"].([]interface{})) {
-				for idx, key_op := range d.Get("key_ops").([]interface{}) {
-					key_ops[idx] = fmt.Sprint(key_op)
-				}
-			} else {
-				req_key_ops := make([]string, len(req["key_ops"].([]interface{})))
-				for idx, key_op := range req["key_ops"].([]interface{}) {
-					req_key_ops[idx] = fmt.Sprint(key_op)
-				}
-				final_idx := 0
-				for _, key_op := range d.Get("key_ops").([]interface{}) {
-					if contains(req_key_ops, fmt.Sprint(key_op)) {
-						key_ops[final_idx] = fmt.Sprint(key_op)
-						final_idx = final_idx + 1
-					}
-				}
+	}
+	// FYOO: Fix TypeList sorting error
+	key_ops := make([]string, len(sobject.Key_ops))
+	if tf_key_ops := d.Get("key_ops").([]interface{}); len(tf_key_ops) > 0 {
+		if len(tf_key_ops) == len(sobject.Key_ops) {
+			for idx, key_op := range tf_key_ops {
+				key_ops[idx] = fmt.Sprint(key_op)
 			}
 		} else {
-			for idx, key_op := range req["key_ops"].([]interface{}) {
-				key_ops[idx] = fmt.Sprint(key_op)
+			final_idx := 0
+			for _, key_op := range tf_key_ops {
+				if contains(sobject.Key_ops, fmt.Sprint(key_op)) {
+					key_ops[final_idx] = fmt.Sprint(key_op)
+					final_idx = final_idx + 1
+				}
 			}
 		}
-		if err := d.Set("key_ops", key_ops); err != nil {
+	} else {
+		copy(key_ops, sobject.Key_ops)
+	}
+	if err := d.Set("key_ops", key_ops); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Description != nil {
+		if err := d.Set("description", *sobject.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if _, ok := req["description"]; ok {
-			if err := d.Set("description", req["description"].(string)); err != nil {
-				return diag.FromErr(err)
-			}
+	}
+	if err := d.Set("enabled", sobject.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("state", sobject.State); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(sobject.Created_at) > 0 {
+		sobj_created_at, date_error := parseTimeFromDSM(sobject.Created_at)
+		if date_error != nil {
+			return date_error
 		}
-		if err := d.Set("enabled", req["enabled"].(bool)); err != nil {
+		if err := d.Set("created_at", sobj_created_at); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := d.Set("state", req["state"].(string)); err != nil {
-			return diag.FromErr(err)
+	}
+	// a state written before rotated_kids only knows the previous_kid
+	if err := d.Set("rotated_kids", rotatedKids(d)); err != nil {
+		return diag.FromErr(err)
+	}
+	if sobject.Deactivation_date != nil {
+		// FYOO: once it's set, you can't remove deactivation date
+		layoutRFC := "2006-01-02T15:04:05Z"
+		layoutDSM := "20060102T150405Z"
+		ddate, newerr := time.Parse(layoutDSM, *sobject.Deactivation_date)
+		if newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if rfcdate, ok := req["deactivation_date"]; ok {
-			// FYOO: once it's set, you can't remove deactivation date
-			layoutRFC := "2006-01-02T15:04:05Z"
-			layoutDSM := "20060102T150405Z"
-			ddate, newerr := time.Parse(layoutDSM, rfcdate.(string))
-			if newerr != nil {
-				return diag.FromErr(newerr)
-			}
-			if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
-				return diag.FromErr(newerr)
-			}
+		if newerr = d.Set("expiry_date", ddate.Format(layoutRFC)); newerr != nil {
+			return diag.FromErr(newerr)
 		}
-		if err := req["obj_type"].(string);  err == "RSA" {
-		    // When a key is copied to byok, the below condition is needed.
-		    // Azure sobject and AWS sobject won't hold the value of pub_key. Hence, it needs to be checked before assigning.
-			if _, ok := req["pub_key"]; ok {
-				openssh_pub_key, err := PublicPEMtoOpenSSH([]byte(req["pub_key"].(string)))
-				if err != nil {
-					return err
-				} else {
-					if err := d.Set("ssh_pub_key", openssh_pub_key); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-			}
+	}
+	// When a key is copied to byok, the below condition is needed.
+	// Azure sobject and AWS sobject won't hold the value of pub_key. Hence, it needs to be checked before assigning.
+	if obj_type == "RSA" && sobject.Pub_key != nil {
+		openssh_pub_key, err := PublicPEMtoOpenSSH([]byte(*sobject.Pub_key))
+		if err != nil {
+			return err
 		}
-		if obj_type == "DSA" {
-			if _,ok := req["dsa"]; ok {
-				dsa := req["dsa"].(map[string]interface{})
-				if err := d.Set("subgroup_size", dsa["subgroup_size"]); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-		} else if obj_type == "KCDSA" {
-			if _,ok := req["kcdsa"]; ok {
-				kcdsa := req["kcdsa"].(map[string]interface{})
-				if err := d.Set("subgroup_size", kcdsa["subgroup_size"]); err != nil {
-					return diag.FromErr(err)
-				}
-				if err := d.Set("hash_alg", kcdsa["hash_alg"]); err != nil {
-					return diag.FromErr(err)
-				}
-			}
-		} else if obj_type == "ECKCDSA" {
-			if _,ok := req["eckcdsa"]; ok {
-				eckcdsa := req["eckcdsa"].(map[string]interface{})
-				if err := d.Set("hash_alg", eckcdsa["hash_alg"]); err != nil {
-					return diag.FromErr(err)
-				}
-			}
+		if err := d.Set("ssh_pub_key", openssh_pub_key); err != nil {
+			return diag.FromErr(err)
 		}
+	}
+	if obj_type == "DSA" && sobject.Dsa != nil {
+		if sobject.Dsa.Subgroup_size != nil {
+			if err := d.Set("subgroup_size", *sobject.Dsa.Subgroup_size); err != nil {
+				return diag.FromErr(err)
+			}
 		}
-		if _, ok := req["rotation_policy"]; ok {
-			rotation_policy := sobj_rotation_policy_read(req["rotation_policy"].(map[string]interface{}))
-			if err := d.Set("rotation_policy", rotation_policy); err != nil {
+	} else if obj_type == "KCDSA" && sobject.Kcdsa != nil {
+		if sobject.Kcdsa.Subgroup_size != nil {
+			if err := d.Set("subgroup_size", *sobject.Kcdsa.Subgroup_size); err != nil {
 				return diag.FromErr(err)
 			}
 		}
-		empty_map := make(map[string]int)
-		if _, ok := req["bls"]; ok {
-			if err := d.Set("bls", req["bls"].(map[string]interface{})); err != nil {
+		if sobject.Kcdsa.Hash_alg != nil {
+			if err := d.Set("hash_alg", *sobject.Kcdsa.Hash_alg); err != nil {
 				return diag.FromErr(err)
 			}
-		} else {
-			/*
-				bls is either Optional or Computed.
-				It is being made as Computed, because when a key is copied, bls will also get copied.
-				Incase of a bls key, it will set the correct value, else it will set as a null.
-
-				As it sets as a null for other key types, it shows a difference while updating.
-				Hence, it needs to set as empty.
-			*/
-			d.Set("bls", empty_map)
 		}
-		if _, ok := req["lms"]; ok {
-			if err := d.Set("lms", set_lms_read_sobject(req["lms"].(map[string]interface{}))); err != nil {
+	} else if obj_type == "ECKCDSA" && sobject.Eckcdsa != nil {
+		if sobject.Eckcdsa.Hash_alg != nil {
+			if err := d.Set("hash_alg", *sobject.Eckcdsa.Hash_alg); err != nil {
 				return diag.FromErr(err)
 			}
 		}
+	}
+	if sobject.Rotation_policy != nil {
+		rotation_policy, date_error := rotationPolicyRead(sobject.Rotation_policy)
+		if date_error != nil {
+			return date_error
+		}
+		if err := d.Set("rotation_policy", rotation_policy); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if sobject.Bls != nil {
+		if err := d.Set("bls", sobject.Bls); err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		/*
+			bls is either Optional or Computed.
+			It is being made as Computed, because when a key is copied, bls will also get copied.
+			Incase of a bls key, it will set the correct value, else it will set as a null.
 
-		// FYOO: clear values that are irrelevant
-		d.Set("rotate", "")
-		d.Set("rotate_from", "")
-		return diags
+			As it sets as a null for other key types, it shows a difference while updating.
+			Hence, it needs to set as empty.
+		*/
+		d.Set("bls", map[string]int{})
+	}
+	if sobject.Lms != nil {
+		if err := d.Set("lms", set_lms_read_sobject(sobject.Lms)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// FYOO: clear values that are irrelevant
+	d.Set("rotate", "")
+	d.Set("rotate_from", "")
+	return diags
 }
 
 // [U]: Terraform Func: resourceUpdateSobject
@@ -877,7 +1000,7 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
     // Destruct the security_object
 	if d.HasChange("destruct") {
 		if err := d.Get("destruct").(string); len(err) > 0 {
-			destruct_key := destructSobject(d, m)
+			destruct_key := destructSobject(ctx, d, m)
 			if destruct_key != nil {
 				d.Set("destruct", "")
 				return destruct_key
@@ -885,6 +1008,12 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
 			return resourceReadSobject(ctx, d, m)
 		}
 	}
+	// rotate in place first, the other changes apply to the new key
+	_, rekey_diags := rekeySobject(ctx, d, m)
+	if rekey_diags.HasError() {
+		return rekey_diags
+	}
+	diags = append(diags, rekey_diags...)
 	// already has been replaced so "rotate" and "rotate_from" does not apply
 	_, replacement := d.GetOk("replacement")
 	_, replaced := d.GetOk("replaced")
@@ -939,8 +1068,11 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
 		has_changed = true
 	}
 	if d.HasChange("rotation_policy") {
-		rotation_policy := d.Get("rotation_policy").(map[string]interface{})
-		security_object["rotation_policy"] = sobj_rotation_policy_write(rotation_policy)
+		rotation_policy, date_error := rotationPolicyWrite(d.Get("rotation_policy").([]interface{}))
+		if date_error != nil {
+			return date_error
+		}
+		security_object["rotation_policy"] = rotation_policy
 		has_changed = true
 	}
 	if d.HasChange("group_id") {
@@ -990,10 +1122,8 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	if has_changed {
-		if debug_output {
-			tflog.Warn(ctx, "Sobject has changed, calling API")
-		}
-		req, err := m.(*api_client).APICallBody("PATCH", fmt.Sprintf("crypto/v1/keys/%s", d.Id()), security_object)
+		tflog.Debug(ctx, "Sobject has changed, calling API")
+		sobject, err := m.(*api_client).API().UpdateKey(ctx, d.Id(), security_object)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
@@ -1003,10 +1133,10 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
 			return diags
 		}
 
-		key_ops := make([]string, len(req["key_ops"].([]interface{})))
-		if err := d.Get("key_ops").([]interface{}); len(err) > 0 {
-			if len(d.Get("key_ops").([]interface{})) == len(req["key_ops"].([]interface{})) {
-				for idx, key_op := range d.Get("key_ops").([]interface{}) {
+		key_ops := make([]string, len(sobject.Key_ops))
+		if tf_key_ops := d.Get("key_ops").([]interface{}); len(tf_key_ops) > 0 {
+			if len(tf_key_ops) == len(sobject.Key_ops) {
+				for idx, key_op := range tf_key_ops {
 					key_ops[idx] = fmt.Sprint(key_op)
 				}
 			} else {
@@ -1018,25 +1148,26 @@ func resourceUpdateSobject(ctx context.Context, d *schema.ResourceData, m interf
 				return diags
 			}
 		} else {
-			for idx, key_op := range req["key_ops"].([]interface{}) {
-				key_ops[idx] = fmt.Sprint(key_op)
-			}
+			copy(key_ops, sobject.Key_ops)
 		}
 		if err := d.Set("key_ops", key_ops); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	return resourceReadSobject(ctx, d, m)
+	return append(diags, resourceReadSobject(ctx, d, m)...)
 }
 
 // [D]: Terraform Func: resourceDeleteSobject
 func resourceDeleteSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	_, statuscode, err := m.(*api_client).APICall("DELETE", fmt.Sprintf("crypto/v1/keys/%s", d.Id()))
+	if rotated_diags := deleteRotatedSobjects(ctx, d, m); rotated_diags.HasError() {
+		return rotated_diags
+	}
+	err := m.(*api_client).API().DeleteKey(ctx, d.Id())
 
-	if (err != nil) && (statuscode != 404) {
+	if (err != nil) && !IsNotFound(err) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "[DSM SDK] Unable to call DSM provider API client",
@@ -1050,7 +1181,7 @@ func resourceDeleteSobject(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 // destruct the sobject, deactivate, compromise and destroy
-func destructSobject(d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func destructSobject(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	destruct_key := d.Get("destruct").(string)
@@ -1071,16 +1202,14 @@ func destructSobject(d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("Invalid option for the parameter 'destruct'"), error_summary)
 	}
 
+	var err error
 	if destruct_body != nil {
-		_, err := m.(*api_client).APICallBody("POST", endpoint, destruct_body)
-		if err != nil {
-			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err), error_summary)
-		}
+		err = m.(*api_client).API().RevokeKey(ctx, d.Id(), destruct_body)
 	} else {
-		_, _, err := m.(*api_client).APICall("POST", endpoint)
-		if err != nil {
-			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err), error_summary)
-		}
+		err = m.(*api_client).API().DestroyKey(ctx, d.Id())
+	}
+	if err != nil {
+		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err), error_summary)
 	}
 	return diags
 }
\ No newline at end of file
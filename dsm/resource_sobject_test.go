@@ -1,25 +1,39 @@
-// Code generated by apic. DO NOT EDIT.
-
 package dsm
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
-	"testing"
 )
 
 var (
 	resourceSobject_createConfig = `resource "dsm_group" "example_group" {
 		name = "example_group"
 	}
-  
+
 	resource "dsm_sobject" "example_sobject" {
 		name = "example_sobject"
     		group_id = "${dsm_group.example_group.group_id}"
 		key_size = 256
 		obj_type = "AES"
 	}`
-	resourceSobject_updateConfig = `resource "dsm_sobject" "example_sobject" {
+	resourceSobject_updateConfig = `resource "dsm_group" "example_group" {
+		name = "example_group"
+	}
+
+	resource "dsm_sobject" "example_sobject" {
 		name = "example_sobject_updated"
     		group_id = "${dsm_group.example_group.group_id}"
 		key_size = 256
@@ -32,15 +46,734 @@ func TestAccResourceSobject(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		Providers:    testAccProviders,
 		PreCheck:     func() { testAccPreCheck(t) },
-		CheckDestroy: testAccCheckDestroySobject,
+		CheckDestroy: testAccCheckDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: resourceSobject_createConfig,
 			},
+			{
+				Config: resourceSobject_updateConfig,
+			},
+		},
+	})
+}
+
+// dsm_sobject with the given AES key attributes, plus an RSA and an EC key
+func testUnitSobjectConfig(name string, description string, enabled bool, destruct string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "example_aes" {
+  name            = %q
+  group_id        = dsm_group.example_group.group_id
+  obj_type        = "AES"
+  key_size        = 256
+  description     = %q
+  enabled         = %t
+  destruct        = %q
+  key_ops         = ["ENCRYPT", "DECRYPT", "EXPORT", "APPMANAGEABLE"]
+  custom_metadata = {
+    owner = "terraform"
+  }
+}
+
+resource "dsm_sobject" "example_rsa" {
+  name     = "example_rsa"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "RSA"
+  key_size = 2048
+}
+
+resource "dsm_sobject" "example_ec" {
+  name           = "example_ec"
+  group_id       = dsm_group.example_group.group_id
+  obj_type       = "EC"
+  elliptic_curve = "NistP256"
+}
+`, name, description, enabled, destruct)
+}
+
+func TestUnitResourceSobject(t *testing.T) {
+	m := newMockDSM(t)
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitSobjectConfig("example_aes", "created by terraform", true, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_sobject.example_aes"),
+					resource.TestCheckResourceAttrPair("dsm_sobject.example_aes", "kid", "dsm_sobject.example_aes", "id"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "dsm_name", "example_aes"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "state", "Active"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "acct_id", m.acct_id),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "key_ops.#", "4"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "custom_metadata.owner", "terraform"),
+					resource.TestCheckResourceAttrSet("dsm_sobject.example_rsa", "pub_key"),
+					resource.TestCheckResourceAttrSet("dsm_sobject.example_rsa", "ssh_pub_key"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_ec", "elliptic_curve", "NistP256"),
+				),
+			},
+			{
+				ResourceName:      "dsm_sobject.example_aes",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the configured name is not read back, DSM's is kept in dsm_name
+				ImportStateVerifyIgnore: []string{"name", "destruct"},
+			},
+			{
+				Config: testUnitSobjectConfig("example_aes_updated", "updated by terraform", false, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "dsm_name", "example_aes_updated"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "enabled", "false"),
+					testUnitCheckMock(m, "keys", "dsm_sobject.example_aes", func(key map[string]interface{}) error {
+						if key["name"] != "example_aes_updated" || key["description"] != "updated by terraform" || key["enabled"] != false {
+							return fmt.Errorf("key was not updated: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// drift: the key is changed outside of terraform
+				PreConfig: func() {
+					m.mutate("keys", kid, func(key map[string]interface{}) {
+						key["description"] = "changed in dsm"
+						key["enabled"] = true
+						key["custom_metadata"] = map[string]interface{}{"owner": "someone else"}
+					})
+				},
+				Config: testUnitSobjectConfig("example_aes_updated", "updated by terraform", false, ""),
+				Check: testUnitCheckMock(m, "keys", "dsm_sobject.example_aes", func(key map[string]interface{}) error {
+					metadata, _ := key["custom_metadata"].(map[string]interface{})
+					if key["description"] != "updated by terraform" || key["enabled"] != false || metadata["owner"] != "terraform" {
+						return fmt.Errorf("drift was not corrected: %v", key)
+					}
+					return nil
+				}),
+			},
+			{
+				// drift: the key is deleted outside of terraform
+				PreConfig: func() { m.remove("keys", kid) },
+				Config:    testUnitSobjectConfig("example_aes_updated", "updated by terraform", false, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_sobject.example_aes"),
+					testUnitCheckMock(m, "keys", "dsm_sobject.example_aes", func(key map[string]interface{}) error {
+						if key["name"] != "example_aes_updated" {
+							return fmt.Errorf("key was not created again: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testUnitSobjectConfig("example_aes_updated", "updated by terraform", false, "deactivate"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "state", "Deactivated"),
+					resource.TestCheckResourceAttrSet("dsm_sobject.example_aes", "expiry_date"),
+				),
+			},
+			{
+				Config: testUnitSobjectConfig("example_aes_updated", "updated by terraform", false, "destroy"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.example_aes", "state", "Destroyed"),
+					testUnitCheckMock(m, "keys", "dsm_sobject.example_aes", func(key map[string]interface{}) error {
+						if key["state"] != "Destroyed" {
+							return fmt.Errorf("key was not destroyed: %v", key["state"])
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitResourceSobjectImportRotateCopy(t *testing.T) {
+	m := newMockDSM(t)
+
+	config := `
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_sobject" "imported" {
+  name     = "imported"
+  group_id = dsm_group.example_group.group_id
+  obj_type = "AES"
+  value    = "AAECAwQFBgcICQoLDA0ODw=="
+  key_ops  = ["ENCRYPT", "DECRYPT", "EXPORT", "APPMANAGEABLE"]
+}
+
+resource "dsm_sobject" "copied" {
+  name     = "copied"
+  group_id = dsm_group.example_group.group_id
+  key = {
+    kid = dsm_sobject.imported.kid
+  }
+}
+`
+	rotated := config + `
+resource "dsm_sobject" "rotated" {
+  name        = "rotated"
+  group_id    = dsm_group.example_group.group_id
+  obj_type    = "AES"
+  key_size    = 128
+  rotate      = "DSM"
+  rotate_from = dsm_sobject.imported.name
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dsm_sobject.copied", "copied_from", "dsm_sobject.imported", "kid"),
+					testUnitCheckMock(m, "keys", "dsm_sobject.imported", func(key map[string]interface{}) error {
+						if key["origin"] != "External" || key["key_size"] != float64(128) {
+							return fmt.Errorf("key was not imported: %v", key)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: rotated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "dsm_name", "imported"),
+					resource.TestCheckResourceAttrPair("dsm_sobject.rotated", "replaced", "dsm_sobject.imported", "kid"),
+					testUnitCheckMock(m, "keys", "dsm_sobject.imported", func(key map[string]interface{}) error {
+						if links, _ := key["links"].(map[string]interface{}); links["replacement"] == nil {
+							return fmt.Errorf("rotated key has no replacement: %v", key["links"])
+						}
+						return nil
+					}),
+				),
+				// rotate and rotate_from are cleared by every read, so a
+				// rotating configuration always plans an update
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestUnitResourceSobjectRotationTriggers(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	config := func(rotation string) string {
+		return fmt.Sprintf(`
+resource "dsm_sobject" "rotated" {
+  name     = "rotated"
+  group_id = %q
+  obj_type = "AES"
+  key_size = 256
+  %s
+}
+`, group_id, rotation)
+	}
+
+	var kids [4]string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		// the keys the rotations replaced are deleted with the security object
+		CheckDestroy: func(s *terraform.State) error {
+			for _, kid := range kids[:3] {
+				if m.get("keys", kid) != nil {
+					return fmt.Errorf("rotated security object %s was not deleted", kid)
+				}
+			}
+			return testAccCheckDestroy(s)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kids[0], "dsm_sobject.rotated"),
+					resource.TestCheckResourceAttrSet("dsm_sobject.rotated", "created_at"),
+					resource.TestCheckNoResourceAttr("dsm_sobject.rotated", "previous_kid"),
+				),
+			},
+			{
+				// the first triggers do not rotate
+				Config: config(`rotation_triggers = { version = "1" }`),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kids[1], "dsm_sobject.rotated"),
+					func(*terraform.State) error {
+						if kids[1] != kids[0] {
+							return fmt.Errorf("adding rotation_triggers rotated %s to %s", kids[0], kids[1])
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: config(`rotation_triggers = { version = "2" }`),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kids[2], "dsm_sobject.rotated"),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "previous_kid", &kids[1]),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "replaced", &kids[1]),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "kid", &kids[2]),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "dsm_name", "rotated"),
+					func(*terraform.State) error {
+						previous := m.get("keys", kids[1])
+						if kids[2] == kids[1] || previous == nil {
+							return fmt.Errorf("%s was not rotated", kids[1])
+						}
+						if links, _ := previous["links"].(map[string]interface{}); links["replacement"] != kids[2] {
+							return fmt.Errorf("rotated key has no replacement: %v", previous["links"])
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// the key is older than rotate_after_days at plan time
+				PreConfig: func() {
+					m.mutate("keys", kids[2], func(key map[string]interface{}) {
+						key["created_at"] = mockTime(time.Now().AddDate(0, 0, -40))
+					})
+				},
+				Config: config(`rotation_triggers = { version = "2" }
+  rotate_after_days = 30`),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kids[3], "dsm_sobject.rotated"),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "previous_kid", &kids[2]),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotated_kids.#", "2"),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "rotated_kids.0", &kids[1]),
+					resource.TestCheckResourceAttrPtr("dsm_sobject.rotated", "rotated_kids.1", &kids[2]),
+					func(*terraform.State) error {
+						if kids[3] == kids[2] {
+							return fmt.Errorf("%s older than rotate_after_days was not rotated", kids[2])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestUnitResourceSobjectRotationPolicy(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	config := func(rotation_policy string) string {
+		return fmt.Sprintf(`
+resource "dsm_sobject" "rotated" {
+  name     = "rotated"
+  group_id = %q
+  obj_type = "AES"
+  key_size = 256
+  %s
+}
+`, group_id, rotation_policy)
+	}
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`rotation_policy {
+    interval_days          = 30
+    effective_at           = "2025-01-02T15:04:05Z"
+    rotate_copied_keys     = "all_external"
+    deactivate_rotated_key = true
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_sobject.rotated"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.#", "1"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.interval_days", "30"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.effective_at", "2025-01-02T15:04:05Z"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.rotate_copied_keys", "all_external"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.deactivate_rotated_key", "true"),
+					func(*terraform.State) error {
+						policy, _ := m.get("keys", kid)["rotation_policy"].(map[string]interface{})
+						if policy["interval_days"] != float64(30) || policy["effective_at"] != "20250102T150405Z" || policy["deactivate_rotated_key"] != true {
+							return fmt.Errorf("unexpected rotation_policy in DSM: %v", policy)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: config(`rotation_policy {
+    interval_months = 3
+    effective_at    = "2025-01-02T15:04:05Z"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.interval_months", "3"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.interval_days", "0"),
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.deactivate_rotated_key", "false"),
+				),
+			},
+			{
+				// an offset and fractional seconds are sent to DSM in UTC, to the second
+				Config: config(`rotation_policy {
+    interval_months = 3
+    effective_at    = "2025-02-03T17:04:05.25+02:00"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.0.effective_at", "2025-02-03T15:04:05Z"),
+					func(*terraform.State) error {
+						policy, _ := m.get("keys", kid)["rotation_policy"].(map[string]interface{})
+						if policy["effective_at"] != "20250203T150405Z" {
+							return fmt.Errorf("unexpected rotation_policy in DSM: %v", policy)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: config(`rotation_policy {
+    interval_months = 3
+    effective_at    = "2025-02-03T15:04:05Z"
+  }`),
+				PlanOnly: true,
+			},
+			{
+				Config:      config("rotation_policy {\n    interval_days   = 30\n    interval_months = 3\n  }"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"rotation_policy.0.interval_days": only one of`),
+			},
+			{
+				Config:      config("rotation_policy {\n    interval_days = 30\n    effective_at  = \"20250102T150405Z\"\n  }"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`to be a valid RFC3339 date`),
+			},
+			{
+				Config: config(""),
+				Check:  resource.TestCheckResourceAttr("dsm_sobject.rotated", "rotation_policy.#", "0"),
+			},
+		},
+	})
+}
+
+func TestUnitSobjectRotationPolicyStateUpgrade(t *testing.T) {
+	for _, r := range []*schema.Resource{resourceSobject(), resourceAWSSobject(), resourceAzureSobject(), resourceGCPSobject()} {
+		if upgrader := r.StateUpgraders[0]; !upgrader.Type.IsObjectType() || !upgrader.Type.AttributeType("rotation_policy").IsMapType() {
+			t.Errorf("unexpected schema version 0 type: %#v", upgrader.Type)
+		}
+	}
+
+	state, err := rotationPolicyStateUpgradeV0(context.Background(), map[string]interface{}{
+		"name": "rotated",
+		"rotation_policy": map[string]interface{}{
+			"interval_months":        "3",
+			"effective_at":           "20250102T150405Z",
+			"rotate_copied_keys":     "all_external",
+			"deactivate_rotated_key": "true",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{
+		"interval_months":        3,
+		"effective_at":           "2025-01-02T15:04:05Z",
+		"rotate_copied_keys":     "all_external",
+		"deactivate_rotated_key": true,
+	}}
+	if !reflect.DeepEqual(state["rotation_policy"], expected) || state["name"] != "rotated" {
+		t.Errorf("unexpected upgraded state: %#v", state)
+	}
+
+	state, err = rotationPolicyStateUpgradeV0(context.Background(), map[string]interface{}{"name": "unrotated"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy := state["rotation_policy"].([]interface{}); len(policy) != 0 {
+		t.Errorf("unexpected upgraded rotation_policy: %#v", policy)
+	}
+}
+
+func TestUnitResourceSobjectValidation(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	config := func(attributes string) string {
+		return fmt.Sprintf(`
+resource "dsm_sobject" "validated" {
+  name     = "validated"
+  group_id = %q
+  %s
+}
+`, group_id, attributes)
+	}
+	// terraform quotes the attribute of the error from the configuration,
+	// or the resource when the attribute is missing
+	plan_error := func(attributes string, detail string, quoted string) resource.TestStep {
+		return resource.TestStep{
+			Config:      config(attributes),
+			PlanOnly:    true,
+			ExpectError: regexp.MustCompile(`(?s)` + detail + `.*in resource "dsm_sobject" "validated".*` + quoted),
+		}
+	}
+	dsa := `obj_type      = "DSA"
+  key_size      = 2048
+  subgroup_size = 256
+  key_ops       = %s`
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			plan_error("obj_type = \"AES\"\n  key_size = 100", "key_size 100 is not supported for AES", `key_size = 100`),
+			plan_error("obj_type = \"HMAC\"\n  key_size = 64", "key_size 64 is not supported for HMAC", `key_size = 64`),
+			plan_error("obj_type = \"AES\"", "key_size should be specified for AES", `resource "dsm_sobject" "validated" \{`),
+			plan_error("obj_type = \"EC\"\n  key_size = 256", "key_size should not be specified for EC", `key_size = 256`),
+			plan_error("obj_type = \"EC\"", "elliptic_curve should be specified for EC", `resource "dsm_sobject" "validated" \{`),
+			plan_error("obj_type = \"ECKCDSA\"\n  elliptic_curve = \"Ed25519\"", "elliptic_curve Ed25519 is not supported for ECKCDSA", `elliptic_curve = "Ed25519"`),
+			plan_error("obj_type = \"KCDSA\"\n  key_size = 2048\n  hash_alg = \"SHA1\"", "hash_alg SHA1 is not supported for KCDSA", `hash_alg = "SHA1"`),
+			plan_error("obj_type = \"RSA\"\n  key_size = 2048\n  hash_alg = \"SHA256\"", "hash_alg should only be specified for KCDSA and ECKCDSA", `hash_alg = "SHA256"`),
+			plan_error("obj_type = \"DSA\"\n  key_size = 3072\n  subgroup_size = 224", "subgroup_size 224 is only supported when the DSA key_size is 2048", `subgroup_size = 224`),
+			plan_error("obj_type = \"BLS\"\n  bls = { variant = \"large\" }", "bls variant \"large\" is not supported", `bls = \{ variant = "large" \}`),
+			plan_error("obj_type = \"LMS\"\n  lms = { l1_height = \"7\", node_size = \"32\" }", "lms l1_height \"7\" is not supported", `lms = \{`),
+			plan_error("obj_type = \"LMS\"\n  lms = { l1_height = \"5\" }", "lms node_size should be specified", `lms = \{`),
+			plan_error("obj_type = \"AES\"\n  key_size = 256\n  key_ops = [\"ENCRYPT\", \"SIGN\"]", "key_ops SIGN is not supported for AES", `key_ops = \[`),
+			plan_error("obj_type = \"AES\"\n  key = { kid = \"f81b6b4c-6b8e-4b4a-9f0a-6a4b1f1d3c2e\" }", "obj_type should not be specified while copying a key", `obj_type = "AES"`),
+			{
+				Config: config(fmt.Sprintf(dsa, `["SIGN", "VERIFY", "APPMANAGEABLE"]`)),
+				Check:  resource.TestCheckResourceAttr("dsm_sobject.validated", "subgroup_size", "256"),
+			},
+			{
+				// key_ops are validated when they change
+				Config:      config(fmt.Sprintf(dsa, `["SIGN", "VERIFY", "ENCRYPT"]`)),
+				ExpectError: regexp.MustCompile("key_ops ENCRYPT is not supported for DSA"),
+			},
+			{
+				Config: config(fmt.Sprintf(dsa, `["SIGN", "VERIFY", "EXPORT"]`)),
+				Check:  resource.TestCheckResourceAttr("dsm_sobject.validated", "key_ops.2", "EXPORT"),
+			},
 		},
 	})
 }
 
-func testAccCheckDestroySobject(s *terraform.State) (err error) {
-	return err
+// DSM adds the options that are not given to rsa and fpe, which does not
+// differ from the configuration, while a change in DSM does.
+func TestUnitResourceSobjectJSONOptions(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	config := func(rsa string) string {
+		return fmt.Sprintf(`
+resource "dsm_sobject" "example_rsa" {
+  name     = "example_rsa"
+  group_id = %q
+  obj_type = "RSA"
+  key_size = 2048
+  rsa      = %s
+}
+
+resource "dsm_sobject" "example_fpe" {
+  name     = "example_fpe"
+  group_id = %q
+  obj_type = "AES"
+  key_size = 256
+  fpe = jsonencode({
+    description = "Credit card"
+    format = {
+      char_set   = [["0", "9"]]
+      min_length = 13
+      max_length = 19
+    }
+  })
+}
+`, group_id, rsa, group_id)
+	}
+	pss := `jsonencode({ signature_policy = [{ padding = { PSS = { mgf = { mgf1 = { hash = "SHA384" } } } } }] })`
+	var kid string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(pss),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&kid, "dsm_sobject.example_rsa"),
+					resource.TestCheckResourceAttr("dsm_sobject.example_rsa", "rsa", `{"encryption_policy":[{"padding":null}],"key_size":2048,"public_exponent":65537,"signature_policy":[{"padding":{"PSS":{"mgf":{"mgf1":{"hash":"SHA384"}}}}}]}`),
+					resource.TestCheckResourceAttr("dsm_sobject.example_fpe", "fpe", `{"description":"Credit card","format":{"char_set":[["0","9"]],"max_length":19,"min_length":13},"name":"example_fpe"}`),
+				),
+			},
+			{
+				Config:   config(`"{\"signature_policy\": [ {\"padding\": {\"PSS\": {\"mgf\": {\"mgf1\": {\"hash\": \"SHA384\"}}}}} ]}"`),
+				PlanOnly: true,
+			},
+			{
+				// drift: the signature policy is changed in DSM
+				PreConfig: func() {
+					m.mutate("keys", kid, func(key map[string]interface{}) {
+						key["rsa"].(map[string]interface{})["signature_policy"] = []interface{}{map[string]interface{}{"padding": map[string]interface{}{"PKCS1_V15": map[string]interface{}{}}}}
+					})
+				},
+				Config:             config(pss),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config(pss),
+				Check: testUnitCheckMock(m, "keys", "dsm_sobject.example_rsa", func(key map[string]interface{}) error {
+					policy := key["rsa"].(map[string]interface{})["signature_policy"].([]interface{})[0].(map[string]interface{})
+					if _, ok := policy["padding"].(map[string]interface{})["PSS"]; !ok {
+						return fmt.Errorf("drift was not corrected: %v", key["rsa"])
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestUnitResourceSobjectWriteOnly(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("example_group", nil)
+	server, schemas := testUnitProtoV5Server(t)
+
+	state := testUnitCreate(t, server, schemas, "dsm_sobject", map[string]tftypes.Value{
+		"name":     tftypes.NewValue(tftypes.String, "imported"),
+		"group_id": tftypes.NewValue(tftypes.String, group_id),
+		"obj_type": tftypes.NewValue(tftypes.String, "AES"),
+		"value_wo": tftypes.NewValue(tftypes.String, "AAECAwQFBgcICQoLDA0ODw=="),
+		"key_ops": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "ENCRYPT"),
+			tftypes.NewValue(tftypes.String, "DECRYPT"),
+			tftypes.NewValue(tftypes.String, "APPMANAGEABLE"),
+		}),
+	})
+	if !state["value_wo"].IsNull() || !state["value"].IsNull() {
+		t.Errorf("the key material is stored in the state: %v", state)
+	}
+	key := m.find("keys", "imported")
+	if key == nil || key["origin"] != "External" || key["key_size"] != float64(128) {
+		t.Errorf("key was not imported: %v", key)
+	}
+}
+
+func TestUnitResourceSobjectWrappedImport(t *testing.T) {
+	m := newMockDSM(t)
+	group_id := m.seedGroup("wrapping_group", nil)
+	kek := []byte("0123456789abcdef0123456789abcdef")
+	aes_kid := m.seedKey(t, map[string]interface{}{
+		"name":     "aes_wrapping_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_ops":  []interface{}{"WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+		"value":    base64.StdEncoding.EncodeToString(kek),
+	})
+	rsa_kid := m.seedKey(t, map[string]interface{}{
+		"name":     "rsa_wrapping_key",
+		"group_id": group_id,
+		"obj_type": "RSA",
+		"key_size": 2048,
+		"key_ops":  []interface{}{"WRAPKEY", "UNWRAPKEY", "APPMANAGEABLE"},
+	})
+	plain_kid := m.seedKey(t, map[string]interface{}{
+		"name":     "plain_key",
+		"group_id": group_id,
+		"obj_type": "AES",
+		"key_size": 256,
+		"key_ops":  []interface{}{"ENCRYPT", "DECRYPT", "APPMANAGEABLE"},
+	})
+
+	material := []byte("16 byte aes key!")
+	kwp, err := mockKeyWrap(kek, material, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oaep, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, m.signers[rsa_kid].Public().(*rsa.PublicKey), material, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RSA-OAEP is randomized: wrapping the same material again gives another wrapped_value
+	rewrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, m.signers[rsa_kid].Public().(*rsa.PublicKey), material, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := func(name string, wrapping_kid string, wrapping_alg string, wrapped []byte) string {
+		return fmt.Sprintf(`
+resource "dsm_sobject" %[1]q {
+  name          = %[1]q
+  group_id      = %[2]q
+  obj_type      = "AES"
+  key_ops       = ["ENCRYPT", "DECRYPT", "EXPORT", "APPMANAGEABLE"]
+  wrapped_value = %[3]q
+  wrapping_kid  = %[4]q
+  wrapping_alg  = %[5]q
+}
+`, name, group_id, base64.StdEncoding.EncodeToString(wrapped), wrapping_kid, wrapping_alg)
+	}
+	check := func(name string) resource.TestCheckFunc {
+		return resource.ComposeTestCheckFunc(
+			resource.TestCheckNoResourceAttr("dsm_sobject."+name, "value"),
+			testUnitCheckMock(m, "keys", "dsm_sobject."+name, func(key map[string]interface{}) error {
+				if key["origin"] != "External" || string(m.values[key["kid"].(string)]) != string(material) {
+					return fmt.Errorf("key was not unwrapped: %v", key)
+				}
+				return nil
+			}),
+		)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config("aes_kwp", aes_kid, "AES-KWP", kwp) + config("rsa_oaep", rsa_kid, "RSA-OAEP-256", oaep),
+				Check:  resource.ComposeTestCheckFunc(check("aes_kwp"), check("rsa_oaep")),
+			},
+			{
+				Config:   config("aes_kwp", aes_kid, "AES-KWP", kwp) + config("rsa_oaep", rsa_kid, "RSA-OAEP-256", rewrapped),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "dsm_sobject.aes_kwp",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the wrapped material is only sent on create
+				ImportStateVerifyIgnore: []string{"name", "destruct", "wrapped_value", "wrapping_kid", "wrapping_alg"},
+			},
+			{
+				Config:      config("not_unwrapping", plain_kid, "AES-KWP", kwp),
+				ExpectError: regexp.MustCompile("UNWRAPKEY permission"),
+			},
+			{
+				Config:      config("wrong_alg", aes_kid, "RSA-OAEP", kwp),
+				ExpectError: regexp.MustCompile("does not match the AES unwrapping key"),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "dsm_sobject" "missing_kid" {
+  name          = "missing_kid"
+  group_id      = %q
+  obj_type      = "AES"
+  wrapped_value = "AAAA"
+}
+`, group_id),
+				ExpectError: regexp.MustCompile(`"wrapped_value": all of`),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "dsm_sobject" "rotated" {
+  name          = "rotated"
+  group_id      = %q
+  obj_type      = "AES"
+  wrapped_value = %q
+  wrapping_kid  = %q
+  wrapping_alg  = "AES-KWP"
+  rotate        = "DSM"
+  rotate_from   = "plain_key"
+}
+`, group_id, base64.StdEncoding.EncodeToString(kwp), aes_kid),
+				ExpectError: regexp.MustCompile("rotate is not supported while importing a wrapped security object"),
+			},
+		},
+	})
 }
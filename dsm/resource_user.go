@@ -112,24 +112,27 @@ func resourceCreateUser(ctx context.Context, d *schema.ResourceData, m interface
 	if last_name := d.Get("last_name").(string); len(last_name) > 0 {
 		user["last_name"] = last_name
 	}
-	req, err := m.(*api_client).APICallBody("POST", dsm_endpoints["user_invite"], user)
+	if description := d.Get("description").(string); len(description) > 0 {
+		user["description"] = description
+	}
+	invited, err := m.(*api_client).API().InviteUser(ctx, user)
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: %v", dsm_endpoints["user_invite"], err), error_summary)
 	}
-	d.SetId(req["user_id"].(string))
+	d.SetId(invited.User_id)
 	// Since creating a user and adding the groups to it can't be created in a single API,
 	// So, patch call should be invoked to configure the groups
 	if groups, ok := d.GetOk("groups"); ok && role == "ACCOUNTMEMBER" {
 		patch_user := map[string]interface{}{
-			"user_id": req["user_id"],
+			"user_id": invited.User_id,
 		}
 		add_groups, err := unmarshalStringToJson(groups.(string))
 		if err != nil {
 			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: %v", err), error_summary)
 		}
 		patch_user["add_groups"] = add_groups
-		patch_url := fmt.Sprintf("%s/%s", dsm_endpoints["user"], req["user_id"].(string))
-		_, p_err := m.(*api_client).APICallBody("PATCH", patch_url, patch_user)
+		patch_url := fmt.Sprintf("%s/%s", dsm_endpoints["user"], invited.User_id)
+		_, p_err := m.(*api_client).API().UpdateUser(ctx, invited.User_id, patch_user)
 		if p_err != nil {
 			d.Set("groups", "")
 			resourceDeleteUser(ctx, d, m)
@@ -143,48 +146,49 @@ func resourceCreateUser(ctx context.Context, d *schema.ResourceData, m interface
 // Read
 func resourceReadUser(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	req, statuscode, err := m.(*api_client).APICall("GET", fmt.Sprintf("%s/%s", dsm_endpoints["user"], d.Id()))
-	if statuscode == 404 {
+	user, err := m.(*api_client).API().GetUser(ctx, d.Id())
+	if IsNotFound(err) {
 		d.SetId("")
-	} else {
-		if err != nil {
-			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/users/%s: %v", d.Id(), err), error_summary)
-		}
-		if err := d.Set("user_email", req["user_email"].(string)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err := d.Set("account_role", req["account_role"].([]interface{})); err != nil {
+		return nil
+	}
+	if err != nil {
+		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET sys/v1/users/%s: %v", d.Id(), err), error_summary)
+	}
+	if err := d.Set("user_email", user.User_email); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("user_id", user.User_id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("account_role", user.Account_role); err != nil {
+		return diag.FromErr(err)
+	}
+	if user.Description != nil {
+		if err := d.Set("description", *user.Description); err != nil {
 			return diag.FromErr(err)
 		}
-		if description, ok := req["description"]; ok {
-			if err := d.Set("description", description.(string)); err != nil {
+	}
+	// Only ACCOUNTMEMBER can add or delete groups.
+	// Whereas both ACCOUNTADMINISTRATOR and ACCOUNTAUDITOR are part of all the groups.
+	// So, setting the groups information in tf state for ACCOUNTADMINISTRATOR and ACCOUNTAUDITOR is not needed.
+	// It also consumes memory.
+	// Hence, groups information will be written only for an ACCOUNTMEMBER.
+	if d.Get("role") == "ACCOUNTMEMBER" && user.Groups != nil {
+		groups_string, err := json.Marshal(user.Groups)
+		if err != nil {
+			if err := d.Set("groups", string(groups_string)); err != nil {
 				return diag.FromErr(err)
 			}
 		}
-		// Only ACCOUNTMEMBER can add or delete groups.
-		// Whereas both ACCOUNTADMINISTRATOR and ACCOUNTAUDITOR are part of all the groups.
-		// So, setting the groups information in tf state for ACCOUNTADMINISTRATOR and ACCOUNTAUDITOR is not needed.
-		// It also consumes memory.
-		// Hence, groups information will be written only for an ACCOUNTMEMBER.
-		if d.Get("role") == "ACCOUNTMEMBER" {
-			if groups, ok := req["groups"]; ok {
-				groups_string, err := json.Marshal(groups.(map[string]interface{}))
-				if err != nil {
-					if err := d.Set("groups", string(groups_string)); err != nil {
-						return diag.FromErr(err)
-					}
-				}
-			}
-		}
-		if email_verified, ok := req["email_verified"]; ok {
-			if err := d.Set("email_verified", email_verified.(bool)); err != nil {
-				return diag.FromErr(err)
-			}
+	}
+	if user.Email_verified != nil {
+		if err := d.Set("email_verified", *user.Email_verified); err != nil {
+			return diag.FromErr(err)
 		}
-		if has_password, ok := req["has_password"]; ok {
-			if err := d.Set("has_password", has_password.(bool)); err != nil {
-				return diag.FromErr(err)
-			}
+	}
+	if user.Has_password != nil {
+		if err := d.Set("has_password", *user.Has_password); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 	return nil
@@ -252,8 +256,7 @@ func resourceUpdateUser(ctx context.Context, d *schema.ResourceData, m interface
 			user["mod_groups"] = mod_groups
 		}
 	}
-	patch_url := fmt.Sprintf("%s/%s", dsm_endpoints["user"], d.Id())
-	_, err := m.(*api_client).APICallBody("PATCH", patch_url, user)
+	_, err := m.(*api_client).API().UpdateUser(ctx, d.Id(), user)
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: PATCH %s: %v", dsm_endpoints["user"], err), error_summary)
 	}
@@ -271,13 +274,12 @@ func resourceDeleteUser(ctx context.Context, d *schema.ResourceData, m interface
 			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: %v", err), error_summary)
 		}
 		user["del_groups"] = del_groups
-		patch_url := fmt.Sprintf("%s/%s", dsm_endpoints["user"], d.Id())
-		_, p_err := m.(*api_client).APICallBody("PATCH", patch_url, user)
+		_, p_err := m.(*api_client).API().UpdateUser(ctx, d.Id(), user)
 		if p_err != nil {
 			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: PATCH %s: %v", dsm_endpoints["user"], p_err), error_summary)
 		}
 	}
-	_, _, err := m.(*api_client).APICall("DELETE", fmt.Sprintf(dsm_endpoints["user"] + "/%s" + "/accounts", d.Id()))
+	err := m.(*api_client).API().RemoveUserFromAccount(ctx, d.Id())
 	if err != nil {
 		return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: DELETE %s: %v", dsm_endpoints["user"], err), error_summary)
 	}
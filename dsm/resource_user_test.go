@@ -0,0 +1,90 @@
+package dsm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// dsm_user invited as an account member of example_group with the given role
+func testUnitUserConfig(description string, group_role string) string {
+	return fmt.Sprintf(`
+resource "dsm_group" "example_group" {
+  name = "example_group"
+}
+
+resource "dsm_user" "example_user" {
+  user_email  = "user@example.com"
+  first_name  = "Example"
+  last_name   = "User"
+  description = %q
+  role        = "ACCOUNTMEMBER"
+  groups = jsonencode({
+    (dsm_group.example_group.group_id) = [%q]
+  })
+}
+`, description, group_role)
+}
+
+// testUnitCheckUserGroup verifies the roles of a user in a group of the mock DSM.
+func testUnitCheckUserGroup(m *mockDSM, name string, group string, roles ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group_id := s.RootModule().Resources[group].Primary.ID
+		return testUnitCheckMock(m, "users", name, func(user map[string]interface{}) error {
+			groups, _ := user["groups"].(map[string]interface{})
+			if got := fmt.Sprint(groups[group_id]); got != fmt.Sprint(roles) {
+				return fmt.Errorf("%s has roles %s in %s, expected %v", name, got, group, roles)
+			}
+			return nil
+		})(s)
+	}
+}
+
+func TestUnitResourceUser(t *testing.T) {
+	m := newMockDSM(t)
+	var user_id string
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitUserConfig("created by terraform", "GROUPAUDITOR"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitID(&user_id, "dsm_user.example_user"),
+					resource.TestCheckResourceAttrPair("dsm_user.example_user", "user_id", "dsm_user.example_user", "id"),
+					resource.TestCheckResourceAttr("dsm_user.example_user", "account_role.0", "ACCOUNTMEMBER"),
+					resource.TestCheckResourceAttr("dsm_user.example_user", "email_verified", "false"),
+					testUnitCheckUserGroup(m, "dsm_user.example_user", "dsm_group.example_group", "GROUPAUDITOR"),
+				),
+			},
+			{
+				ResourceName:      "dsm_user.example_user",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the invitation attributes are not read back
+				ImportStateVerifyIgnore: []string{"role", "groups", "first_name", "last_name"},
+			},
+			{
+				Config: testUnitUserConfig("updated by terraform", "GROUPADMINISTRATOR"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dsm_user.example_user", "description", "updated by terraform"),
+					testUnitCheckUserGroup(m, "dsm_user.example_user", "dsm_group.example_group", "GROUPADMINISTRATOR"),
+				),
+			},
+			{
+				// drift: the user leaves the account outside of terraform
+				PreConfig: func() { m.remove("users", user_id) },
+				Config:    testUnitUserConfig("updated by terraform", "GROUPADMINISTRATOR"),
+				Check: testUnitCheckMock(m, "users", "dsm_user.example_user", func(user map[string]interface{}) error {
+					if user["user_id"] == user_id {
+						return fmt.Errorf("user %s was not invited again", user_id)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
@@ -0,0 +1,315 @@
+// **********
+// Terraform Provider - DSM: rotation of security objects
+// **********
+
+package dsm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// [-]: plan an in-place rekey of a security object
+// A rekey is due when a rotation trigger changes or when the security object
+// is older than rotate_after_days. Setting the first triggers is not a change.
+// The plan leaves the kid and the given attributes of the new key unknown,
+// which tells the update to rekey.
+func sobjectRotationCustomizeDiff(rotated_keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() == "" || d.Get("state").(string) != "Active" {
+			return nil
+		}
+		old_triggers, _ := d.GetChange("rotation_triggers")
+		triggered := len(old_triggers.(map[string]interface{})) > 0 && d.HasChange("rotation_triggers")
+		if !triggered && !sobjectRotationExpired(d.Get("created_at").(string), d.Get("rotate_after_days").(int)) {
+			return nil
+		}
+		for _, key := range append([]string{"kid", "previous_kid", "rotated_kids", "created_at"}, rotated_keys...) {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// [-]: whether a security object created at the given RFC date is older than the given days
+func sobjectRotationExpired(created_at string, rotate_after_days int) bool {
+	if rotate_after_days <= 0 || len(created_at) == 0 {
+		return false
+	}
+	created, err := time.Parse(time.RFC3339, created_at)
+	if err != nil {
+		return false
+	}
+	return time.Since(created) >= time.Duration(rotate_after_days)*24*time.Hour
+}
+
+// [-]: rekey a security object in place when the plan rotates it
+// The rekey follows the kid in the state and the resource moves to the new key.
+// It returns whether the security object was rotated.
+func rekeySobject(ctx context.Context, d *schema.ResourceData, m interface{}) (bool, diag.Diagnostics) {
+	if plan := d.GetRawPlan(); plan.IsNull() || plan.GetAttr("kid").IsKnown() {
+		return false, nil
+	}
+	previous_kid := d.Id()
+	rotated_kids := append(rotatedKids(d), previous_kid)
+	endpoint := fmt.Sprintf("crypto/v1/keys/%s/rekey", previous_kid)
+	rekey_request := map[string]interface{}{
+		"name": d.Get("name").(string),
+	}
+	req, diags, err := m.(*api_client).APICallBodyWithApproval(ctx, "POST", endpoint, rekey_request, approvalTimeout(d))
+	if err != nil {
+		return false, append(diags, invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: %v", endpoint, err), "[DSM SDK] Unable to call DSM provider API client")...)
+	}
+
+	kid, ok := req["kid"].(string)
+	if !ok {
+		return false, append(diags, invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST %s: response without kid", endpoint), "[DSM SDK] Unable to parse DSM provider API client output")...)
+	}
+	d.SetId(kid)
+	if err := d.Set("kid", kid); err != nil {
+		return true, append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("previous_kid", previous_kid); err != nil {
+		return true, append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("rotated_kids", rotated_kids); err != nil {
+		return true, append(diags, diag.FromErr(err)...)
+	}
+	return true, diags
+}
+
+// [-]: the security objects that in-place rekeys of the resource replaced, oldest first
+func rotatedKids(d *schema.ResourceData) []string {
+	rotated_kids := []string{}
+	for _, kid := range d.Get("rotated_kids").([]interface{}) {
+		rotated_kids = append(rotated_kids, kid.(string))
+	}
+	if previous_kid := d.Get("previous_kid").(string); len(previous_kid) > 0 && !slices.Contains(rotated_kids, previous_kid) {
+		rotated_kids = append(rotated_kids, previous_kid)
+	}
+	return rotated_kids
+}
+
+// [-]: delete the security objects that in-place rekeys of the resource replaced
+// They are not managed by any resource and would keep their group from being deleted.
+func deleteRotatedSobjects(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	for _, kid := range rotatedKids(d) {
+		if err := m.(*api_client).API().DeleteKey(ctx, kid); err != nil && !IsNotFound(err) {
+			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: DELETE crypto/v1/keys/%s: %v", kid, err), "[DSM SDK] Unable to call DSM provider API client")
+		}
+	}
+	return nil
+}
+
+// [-]: apply a deletion step of a BYOK security object to the keys its in-place rekeys replaced
+// The replaced keys live in the same external KMS and, like the security object,
+// can only be deleted once destroyed. Keys whose external state, custom_metadata
+// state_key, is one of skip_states already went through the step.
+func rotatedBYOKSobjectsAction(ctx context.Context, d *schema.ResourceData, m interface{}, action func(context.Context, string) error, state_key string, skip_states ...string) diag.Diagnostics {
+	for _, kid := range rotatedKids(d) {
+		key, err := m.(*api_client).API().GetKeyAnyState(ctx, kid)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: GET crypto/v1/keys/%s: %v", kid, err), "[DSM SDK] Unable to call DSM provider API client")
+		}
+		if key.State == "Destroyed" || slices.Contains(skip_states, key.Custom_metadata[state_key]) {
+			continue
+		}
+		if err := action(ctx, kid); err != nil {
+			return invokeErrorDiagsWithSummary(fmt.Sprintf("[E]: API: POST crypto/v1/keys/%s: %v", kid, err), "[DSM SDK] Unable to call DSM provider API client")
+		}
+	}
+	return nil
+}
+
+// [-]: rotation_policy block with the common options and the given extra options
+// Either interval_days or interval_months should be given, but not both.
+func rotationPolicySchema(description string, extra_options ...string) *schema.Schema {
+	intervals := []string{"rotation_policy.0.interval_days", "rotation_policy.0.interval_months"}
+	options := map[string]*schema.Schema{
+		"interval_days": {
+			Description:  "Rotate the key every given number of days.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(1),
+			ExactlyOneOf: intervals,
+		},
+		"interval_months": {
+			Description:  "Rotate the key every given number of months.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(1),
+			ExactlyOneOf: intervals,
+		},
+		"effective_at": {
+			Description:      "Start of the rotation policy time in RFC 3339 format, e.g. 2025-01-02T15:04:05Z or 2025-01-02T17:04:05+02:00. DSM keeps it in UTC, to the second.",
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
+			ValidateFunc:     validation.IsRFC3339Time,
+			DiffSuppressFunc: rfc3339DiffSuppress,
+		},
+	}
+	extra := map[string]*schema.Schema{
+		"rotate_copied_keys": {
+			Description:  "Rotate the keys copied from the security object as well. The value is `all_external`.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"all_external"}, false),
+		},
+		"deactivate_rotated_key": {
+			Description: "Deactivate the original key after rotation.",
+			Type:        schema.TypeBool,
+			Optional:    true,
+		},
+	}
+	for _, option := range extra_options {
+		options[option] = extra[option]
+	}
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: options,
+		},
+	}
+}
+
+// [-]: rotation_policy request from the rotation_policy block, empty without one
+func rotationPolicyWrite(rotation_policy []interface{}) (map[string]interface{}, diag.Diagnostics) {
+	request := make(map[string]interface{})
+	if len(rotation_policy) == 0 || rotation_policy[0] == nil {
+		return request, nil
+	}
+	policy := rotation_policy[0].(map[string]interface{})
+	if interval_days, _ := policy["interval_days"].(int); interval_days > 0 {
+		request["interval_days"] = interval_days
+	}
+	if interval_months, _ := policy["interval_months"].(int); interval_months > 0 {
+		request["interval_months"] = interval_months
+	}
+	if effective_at, _ := policy["effective_at"].(string); len(effective_at) > 0 {
+		dsm_effective_at, date_error := parseTimeToDSM(effective_at)
+		if date_error != nil {
+			return nil, date_error
+		}
+		request["effective_at"] = dsm_effective_at
+	}
+	if rotate_copied_keys, _ := policy["rotate_copied_keys"].(string); len(rotate_copied_keys) > 0 {
+		request["rotate_copied_keys"] = rotate_copied_keys
+	}
+	if deactivate_rotated_key, ok := policy["deactivate_rotated_key"].(bool); ok {
+		request["deactivate_rotated_key"] = deactivate_rotated_key
+	}
+	return request, nil
+}
+
+// [-]: DiffSuppressFunc of an RFC 3339 date
+// Dates of the same second do not differ, e.g. 2025-01-02T17:04:05+02:00 and
+// the 2025-01-02T15:04:05Z that DSM returns for it.
+func rfc3339DiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	old_time, old_err := time.Parse(time.RFC3339, old)
+	new_time, new_err := time.Parse(time.RFC3339, new)
+	return old_err == nil && new_err == nil && old_time.Truncate(time.Second).Equal(new_time.Truncate(time.Second))
+}
+
+// [-]: rotation_policy block from the rotation_policy of DSM
+// Options the block of a resource does not have are ignored by d.Set.
+func rotationPolicyRead(rotation_policy map[string]interface{}) ([]interface{}, diag.Diagnostics) {
+	policy := make(map[string]interface{})
+	if interval_days, ok := rotation_policy["interval_days"].(float64); ok {
+		policy["interval_days"] = int(interval_days)
+	}
+	if interval_months, ok := rotation_policy["interval_months"].(float64); ok {
+		policy["interval_months"] = int(interval_months)
+	}
+	if effective_at, ok := rotation_policy["effective_at"].(string); ok {
+		rfc_effective_at, date_error := parseTimeFromDSM(effective_at)
+		if date_error != nil {
+			return nil, date_error
+		}
+		policy["effective_at"] = rfc_effective_at
+	}
+	if rotate_copied_keys, ok := rotation_policy["rotate_copied_keys"].(string); ok {
+		policy["rotate_copied_keys"] = rotate_copied_keys
+	}
+	if deactivate_rotated_key, ok := rotation_policy["deactivate_rotated_key"].(bool); ok {
+		policy["deactivate_rotated_key"] = deactivate_rotated_key
+	}
+	if len(policy) == 0 {
+		return []interface{}{}, nil
+	}
+	return []interface{}{policy}, nil
+}
+
+// [-]: state upgrader of the rotation_policy string map of schema version 0 to the block
+func rotationPolicyStateUpgrader(resource *schema.Resource) schema.StateUpgrader {
+	v0_schema := make(map[string]*schema.Schema, len(resource.Schema))
+	for key, value := range resource.Schema {
+		v0_schema[key] = value
+	}
+	v0_schema["rotation_policy"] = &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+	v0 := &schema.Resource{
+		Schema:   v0_schema,
+		Timeouts: resource.Timeouts,
+	}
+	return schema.StateUpgrader{
+		Version: 0,
+		Type:    v0.CoreConfigSchema().ImpliedType(),
+		Upgrade: rotationPolicyStateUpgradeV0,
+	}
+}
+
+// The values of the map were the strings written by sobj_rotation_policy_read,
+// effective_at in DSM format. Values that do not parse are dropped, as DSM
+// never accepted them.
+func rotationPolicyStateUpgradeV0(ctx context.Context, raw_state map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	old_policy, _ := raw_state["rotation_policy"].(map[string]interface{})
+	if len(old_policy) == 0 {
+		raw_state["rotation_policy"] = []interface{}{}
+		return raw_state, nil
+	}
+	policy := make(map[string]interface{})
+	for key, value := range old_policy {
+		value, _ := value.(string)
+		switch key {
+		case "interval_days", "interval_months":
+			if interval, err := strconv.Atoi(value); err == nil {
+				policy[key] = interval
+			}
+		case "deactivate_rotated_key":
+			if deactivate, err := strconv.ParseBool(value); err == nil {
+				policy[key] = deactivate
+			}
+		case "effective_at":
+			if rfc_effective_at, date_error := parseTimeFromDSM(value); !date_error.HasError() {
+				policy[key] = rfc_effective_at
+			} else if _, err := time.Parse("2006-01-02T15:04:05Z", value); err == nil {
+				policy[key] = value
+			}
+		default:
+			policy[key] = value
+		}
+	}
+	raw_state["rotation_policy"] = []interface{}{policy}
+	return raw_state, nil
+}
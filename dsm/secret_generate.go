@@ -0,0 +1,251 @@
+// **********
+// Terraform Provider - DSM: generation of secret values
+// **********
+
+package dsm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// character classes of a generated secret, in the order of the generate options
+var secret_generate_classes = []struct {
+	name  string
+	chars string
+}{
+	{"lowercase", "abcdefghijklmnopqrstuvwxyz"},
+	{"uppercase", "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+	{"numeric", "0123456789"},
+	{"special", "!@#$%^&*()_+-={}|[]`~"},
+}
+
+// [-]: generate block of dsm_secret
+func secretGenerateSchema() *schema.Schema {
+	options := map[string]*schema.Schema{
+		"length": {
+			Description:  "The number of characters of the secret, or the number of random bytes when output is not `characters`. Default is 32.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      32,
+			ValidateFunc: validation.IntBetween(1, 4096),
+		},
+		"output": {
+			Description: "The format of the secret. Default is `characters`.\n" +
+				"   * `characters`: length characters of the enabled character classes.\n" +
+				"   * `raw`: length random bytes.\n" +
+				"   * `hex`, `base64`: length random bytes in hex or base64 format.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      "characters",
+			ValidateFunc: validation.StringInSlice([]string{"characters", "raw", "hex", "base64"}, false),
+		},
+		"exclude_characters": {
+			Description: "Characters that the secret should not contain, e.g. quotes for a secret used in a shell.",
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    true,
+		},
+	}
+	for _, class := range secret_generate_classes {
+		options[class.name] = &schema.Schema{
+			Description: fmt.Sprintf("Whether the secret contains %s characters ``%s``. Default is true.", class.name, class.chars),
+			Type:        schema.TypeBool,
+			Optional:    true,
+			ForceNew:    true,
+			Default:     true,
+		}
+		options["min_"+class.name] = &schema.Schema{
+			Description:  fmt.Sprintf("The minimum number of %s characters of the secret. Default is 0.", class.name),
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+		}
+	}
+	return &schema.Schema{
+		Description: "Generate the value of the secret in the provider with a cryptographically secure random generator, and import it. At most one of value, value_wo, wrapped_value or generate should be specified: without any of them, the secret is generated with the default options.\n" +
+			"   * The generated value is not stored in the state. Changing the block replaces the secret with a newly generated one.\n" +
+			"   * Adding the block to a secret whose state has none, e.g. one created by an older provider or imported, does not replace it.\n" +
+			"   * The character class options only apply to the `characters` output.",
+		Type:          schema.TypeList,
+		Optional:      true,
+		Computed:      true,
+		ForceNew:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"value", "value_wo", "wrapped_value"},
+		Elem: &schema.Resource{
+			Schema: options,
+		},
+	}
+}
+
+// [-]: options of the generate block when the secret has no value
+func secretGenerateDefaults() map[string]interface{} {
+	defaults := make(map[string]interface{})
+	for name, option := range secretGenerateSchema().Elem.(*schema.Resource).Schema {
+		if option.Default != nil {
+			defaults[name] = option.Default
+		} else {
+			defaults[name] = option.ZeroValue()
+		}
+	}
+	return defaults
+}
+
+// [-]: characters of each enabled class of the generate block, without the excluded characters
+func secretGenerateCharsets(generate map[string]interface{}) map[string]string {
+	exclude := generate["exclude_characters"].(string)
+	charsets := make(map[string]string)
+	for _, class := range secret_generate_classes {
+		if !generate[class.name].(bool) {
+			continue
+		}
+		charsets[class.name] = strings.Map(func(c rune) rune {
+			if strings.ContainsRune(exclude, c) {
+				return -1
+			}
+			return c
+		}, class.chars)
+	}
+	return charsets
+}
+
+// [-]: validate the generate block of dsm_secret at plan time
+func validateSecretGenerateCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	generate_list, _ := d.Get("generate").([]interface{})
+	if len(generate_list) == 0 || generate_list[0] == nil || !d.NewValueKnown("generate") {
+		return nil
+	}
+	generate := generate_list[0].(map[string]interface{})
+	if generate["output"].(string) != "characters" {
+		return nil
+	}
+	path := cty.GetAttrPath("generate").IndexInt(0)
+	charsets := secretGenerateCharsets(generate)
+	if len(charsets) == 0 {
+		return path.NewErrorf("at least one of lowercase, uppercase, numeric or special should be enabled")
+	}
+	var chars string
+	min_length := 0
+	for _, class := range secret_generate_classes {
+		min_count := generate["min_"+class.name].(int)
+		charset, enabled := charsets[class.name]
+		if min_count > 0 && !enabled {
+			return path.GetAttr("min_"+class.name).NewErrorf("min_%s is %d but %s characters are disabled", class.name, min_count, class.name)
+		}
+		if min_count > 0 && len(charset) == 0 {
+			return path.GetAttr("min_"+class.name).NewErrorf("min_%s is %d but exclude_characters excludes all %s characters", class.name, min_count, class.name)
+		}
+		min_length += min_count
+		chars += charset
+	}
+	if len(chars) == 0 {
+		return path.GetAttr("exclude_characters").NewErrorf("exclude_characters excludes all the characters of the enabled classes")
+	}
+	if length := generate["length"].(int); min_length > length {
+		return path.GetAttr("length").NewErrorf("length %d is less than the sum %d of the minimum character counts", length, min_length)
+	}
+	return nil
+}
+
+// The generate block only applies when the secret is created. A secret whose
+// state has no block, e.g. one created by an older provider with no value or
+// imported, keeps its value when the block is added.
+func secretGenerateExistingCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChange("generate") {
+		return nil
+	}
+	if old_generate, _ := d.GetChange("generate"); len(old_generate.([]interface{})) == 0 {
+		return d.Clear("generate")
+	}
+	return nil
+}
+
+// [-]: generate a secret of the generate block, in base64 format as DSM imports it
+func generateSecret(generate map[string]interface{}) (string, diag.Diagnostics) {
+	length := generate["length"].(int)
+	var secret []byte
+	switch output := generate["output"].(string); output {
+	case "characters":
+		characters, err := generateSecretCharacters(generate, length)
+		if err != nil {
+			return "", invokeErrorDiagsNoSummary(fmt.Sprintf("[E]: unable to generate the secret: %v", err))
+		}
+		secret = []byte(characters)
+	default:
+		secret = make([]byte, length)
+		if _, err := rand.Read(secret); err != nil {
+			return "", invokeErrorDiagsNoSummary(fmt.Sprintf("[E]: unable to generate the secret: %v", err))
+		}
+		if output == "hex" {
+			secret = []byte(hex.EncodeToString(secret))
+		} else if output == "base64" {
+			secret = []byte(base64.StdEncoding.EncodeToString(secret))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(secret), nil
+}
+
+// The minimum characters of each class are drawn first, the rest from all the
+// enabled classes, and the characters are shuffled so that their positions
+// do not tell the classes.
+func generateSecretCharacters(generate map[string]interface{}, length int) (string, error) {
+	charsets := secretGenerateCharsets(generate)
+	var chars string
+	secret := make([]byte, 0, length)
+	for _, class := range secret_generate_classes {
+		charset := charsets[class.name]
+		chars += charset
+		if min_count := generate["min_"+class.name].(int); min_count > 0 && len(charset) == 0 {
+			return "", fmt.Errorf("no %s characters for min_%s", class.name, class.name)
+		}
+		for i := 0; i < generate["min_"+class.name].(int); i++ {
+			c, err := randomIndex(len(charset))
+			if err != nil {
+				return "", err
+			}
+			secret = append(secret, charset[c])
+		}
+	}
+	if len(chars) == 0 {
+		return "", fmt.Errorf("no characters to generate the secret from")
+	}
+	for len(secret) < length {
+		c, err := randomIndex(len(chars))
+		if err != nil {
+			return "", err
+		}
+		secret = append(secret, chars[c])
+	}
+	for i := len(secret) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		secret[i], secret[j] = secret[j], secret[i]
+	}
+	return string(secret), nil
+}
+
+// [-]: uniform random index below n from the CSPRNG
+func randomIndex(n int) (int, error) {
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(index.Int64()), nil
+}
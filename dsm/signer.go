@@ -9,6 +9,7 @@
 package dsm
 
 import (
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/x509"
@@ -30,6 +31,8 @@ type dsmsigner struct {
 
 	kid        string
 	api_client *api_client
+	// crypto.Signer has no context argument, keep the one of the resource call
+	ctx        context.Context
 
 	Cn       string
 	Ou       string
@@ -48,12 +51,13 @@ func (dsmsigner *dsmsigner) setProperty(propName string, propValue string) *dsms
 	return dsmsigner
 }
 
-func NewDSMSigner(kid string, dnsnames []string, ips []net.IP, email []string, cn string, ou string, l string, c string, o string, st string, e string, api_client *api_client) (*dsmsigner, diag.Diagnostics) {
+func NewDSMSigner(ctx context.Context, kid string, dnsnames []string, ips []net.IP, email []string, cn string, ou string, l string, c string, o string, st string, e string, api_client *api_client) (*dsmsigner, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	var new_signer = &dsmsigner{
 		kid:        kid,
 		api_client: api_client,
+		ctx:        ctx,
 		Dnsnames:   dnsnames,
 		Ips:        ips,
 		Email:      email,
@@ -148,7 +152,7 @@ func (dsmsigner *dsmsigner) generate_csr() (string, diag.Diagnostics) {
 }
 
 func (dsmsigner dsmsigner) Public() crypto.PublicKey {
-	req, _, err := dsmsigner.api_client.APICall("GET", fmt.Sprintf("crypto/v1/keys/%s", dsmsigner.kid))
+	req, err := dsmsigner.api_client.APICall(dsmsigner.ctx, "GET", fmt.Sprintf("crypto/v1/keys/%s", dsmsigner.kid))
 	if err != nil {
 		panic("Unable to call DSM")
 	}
@@ -187,14 +191,17 @@ func (dsmsigner dsmsigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOp
 		"data":     base64.StdEncoding.EncodeToString(digest),
 	}
 
-	reqfpi, err := dsmsigner.api_client.FindPluginId("Terraform Plugin - CSR")
+	plugin, err := dsmsigner.api_client.API().FindPlugin(dsmsigner.ctx, "Terraform Plugin - CSR")
 	if err != nil {
 		return nil, fmt.Errorf("[DSM SDK]: signer: Unable to call DSM provider API client: GET: sys/v1/plugins: %v", err)
 	}
-	var endpoint = fmt.Sprintf("sys/v1/plugins/%s", string(reqfpi))
+	if plugin == nil {
+		return nil, fmt.Errorf("[DSM SDK]: signer: Unable to find plugin %q through DSM provider", "Terraform Plugin - CSR")
+	}
+	var endpoint = fmt.Sprintf("sys/v1/plugins/%s", plugin.Plugin_id)
 	var operation = "POST"
 
-	req, err := dsmsigner.api_client.APICallBody(operation, endpoint, sign_op)
+	req, err := dsmsigner.api_client.APICallBody(dsmsigner.ctx, operation, endpoint, sign_op)
 	if err != nil {
 		return nil, fmt.Errorf("[DSM SDK]: signer: Unable to call DSM provider API client: POST: sys/v1/plugins: %v", err)
 	}
@@ -0,0 +1,291 @@
+// **********
+// Terraform Provider - DSM: security object validation
+// **********
+
+package dsm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// [-] Define the key type matrix documented in the key_size description
+type sobjectKeyType struct {
+	key_sizes []int
+	curves    []string
+	hash_algs []string
+	key_ops   []string
+}
+
+var sobject_ec_curves = []string{"SecP192K1", "SecP224K1", "SecP256K1", "NistP192", "NistP224", "NistP256", "NistP384", "NistP521"}
+
+var sobject_key_types = map[string]sobjectKeyType{
+	"RSA": {
+		key_sizes: []int{1024, 2048, 4096, 8192},
+		key_ops:   []string{"APPMANAGEABLE", "SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "EXPORT"},
+	},
+	"DSA": {
+		key_sizes: []int{2048, 3072},
+		key_ops:   []string{"APPMANAGEABLE", "SIGN", "VERIFY", "EXPORT"},
+	},
+	"KCDSA": {
+		key_sizes: []int{2048},
+		hash_algs: []string{"SHA224", "SHA256"},
+		key_ops:   []string{"APPMANAGEABLE", "SIGN", "VERIFY", "EXPORT"},
+	},
+	"AES": {
+		key_sizes: []int{128, 192, 256},
+		key_ops:   []string{"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE", "EXPORT"},
+	},
+	"DES": {
+		key_sizes: []int{56},
+		key_ops:   []string{"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "APPMANAGEABLE", "EXPORT"},
+	},
+	"DES3": {
+		key_sizes: []int{112, 168},
+		key_ops:   []string{"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE", "EXPORT"},
+	},
+	"ARIA": {
+		key_sizes: []int{128, 192, 256},
+		key_ops:   []string{"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE", "EXPORT"},
+	},
+	"SEED": {
+		key_sizes: []int{128},
+		key_ops:   []string{"ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "EXPORT"},
+	},
+	// any size from 112 to 8192 bits, see sobjectKeySizeValid
+	"HMAC": {
+		key_ops: []string{"DERIVEKEY", "MACGENERATE", "MACVERIFY", "APPMANAGEABLE", "EXPORT"},
+	},
+	"EC": {
+		curves:  append(append([]string{}, sobject_ec_curves...), "X25519", "Ed25519"),
+		key_ops: []string{"APPMANAGEABLE", "SIGN", "VERIFY", "AGREEKEY", "EXPORT"},
+	},
+	"ECKCDSA": {
+		curves:    sobject_ec_curves,
+		hash_algs: []string{"SHA1", "SHA224", "SHA256", "SHA384", "SHA512"},
+		key_ops:   []string{"APPMANAGEABLE", "SIGN", "VERIFY", "EXPORT"},
+	},
+	"BLS": {
+		key_ops: []string{"APPMANAGEABLE", "SIGN", "VERIFY", "EXPORT"},
+	},
+	"LMS": {
+		key_ops: []string{"APPMANAGEABLE", "SIGN", "VERIFY"},
+	},
+	"CERTIFICATE": {
+		key_ops: []string{"APPMANAGEABLE", "ENCRYPT", "VERIFY", "WRAPKEY", "EXPORT"},
+	},
+	"OPAQUE": {
+		key_ops: []string{"APPMANAGEABLE", "EXPORT"},
+	},
+}
+
+// [-]: whether the key size is allowed for the key type
+func sobjectKeySizeValid(obj_type string, key_size int) bool {
+	if obj_type == "HMAC" {
+		return key_size >= 112 && key_size <= 8192
+	}
+	for _, size := range sobject_key_types[obj_type].key_sizes {
+		if size == key_size {
+			return true
+		}
+	}
+	return false
+}
+
+// [-]: plan time validation of dsm_sobject, mirrors the checks of createSO
+// The key type, size, curve and options are validated for a new security
+// object only, key_ops whenever they change. Values unknown at plan time are
+// not validated. The first error is returned with the path of its attribute.
+func validateSobjectCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	obj_type := d.Get("obj_type").(string)
+	if !d.NewValueKnown("obj_type") {
+		obj_type = ""
+	}
+	if d.Id() == "" {
+		if err := validateSobjectKeyType(d, obj_type); err != nil {
+			return err
+		}
+	}
+	if d.Id() == "" || d.HasChange("key_ops") {
+		return validateSobjectKeyOps(d, obj_type)
+	}
+	return nil
+}
+
+// [-]: the attributes of the configuration given for a new security object
+func sobjectConfigured(d *schema.ResourceDiff, keys ...string) []string {
+	config := d.GetRawConfig()
+	var configured []string
+	for _, key := range keys {
+		if !config.IsNull() && !config.GetAttr(key).IsNull() {
+			configured = append(configured, key)
+		}
+	}
+	return configured
+}
+
+func validateSobjectKeyType(d *schema.ResourceDiff, obj_type string) error {
+	key_options := []string{"obj_type", "key_size", "hash_alg", "subgroup_size", "elliptic_curve", "bls", "lms"}
+
+	// a copy takes the key type of the copied security object
+	if len(sobjectConfigured(d, "key")) > 0 {
+		if configured := sobjectConfigured(d, key_options...); len(configured) > 0 {
+			return cty.GetAttrPath(configured[0]).NewErrorf("%s should not be specified while copying a key", configured[0])
+		}
+		return nil
+	}
+	// an import takes the key size and curve of the imported value
+	if len(sobjectConfigured(d, "value", "value_wo", "wrapped_value")) > 0 {
+		if len(obj_type) == 0 && len(sobjectConfigured(d, "wrapped_value")) > 0 && d.NewValueKnown("obj_type") {
+			return cty.GetAttrPath("obj_type").NewErrorf("obj_type should be specified while importing a wrapped security object")
+		}
+		return nil
+	}
+	if len(obj_type) == 0 {
+		if d.NewValueKnown("obj_type") {
+			return cty.GetAttrPath("obj_type").NewErrorf("obj_type should be specified while creating a security object")
+		}
+		return nil
+	}
+	key_type, ok := sobject_key_types[obj_type]
+	if !ok {
+		// other types, e.g. SECRET, are validated by DSM
+		return nil
+	}
+
+	var required string
+	switch obj_type {
+	case "EC", "ECKCDSA":
+		required = "elliptic_curve"
+	case "BLS":
+		required = "bls"
+	case "LMS":
+		required = "lms"
+	default:
+		required = "key_size"
+	}
+	for _, key := range sobjectConfigured(d, "key_size", "elliptic_curve", "bls", "lms") {
+		if key != required {
+			return cty.GetAttrPath(key).NewErrorf("%s should not be specified for %s, %s should be specified", key, obj_type, required)
+		}
+	}
+	if len(sobjectConfigured(d, required)) == 0 {
+		return cty.GetAttrPath(required).NewErrorf("%s should be specified for %s", required, obj_type)
+	}
+
+	switch required {
+	case "key_size":
+		if key_size := d.Get("key_size").(int); d.NewValueKnown("key_size") && !sobjectKeySizeValid(obj_type, key_size) {
+			if obj_type == "HMAC" {
+				return cty.GetAttrPath("key_size").NewErrorf("key_size %d is not supported for HMAC, it should be from 112 to 8192", key_size)
+			}
+			return cty.GetAttrPath("key_size").NewErrorf("key_size %d is not supported for %s, it should be one of %v", key_size, obj_type, key_type.key_sizes)
+		}
+	case "elliptic_curve":
+		if curve := d.Get("elliptic_curve").(string); d.NewValueKnown("elliptic_curve") && !contains(key_type.curves, curve) {
+			return cty.GetAttrPath("elliptic_curve").NewErrorf("elliptic_curve %s is not supported for %s, it should be one of %s", curve, obj_type, strings.Join(key_type.curves, ", "))
+		}
+	case "bls":
+		if err := validateSobjectBls(d); err != nil {
+			return err
+		}
+	case "lms":
+		if err := validateSobjectLms(d); err != nil {
+			return err
+		}
+	}
+
+	if len(sobjectConfigured(d, "hash_alg")) > 0 && d.NewValueKnown("hash_alg") {
+		hash_alg := d.Get("hash_alg").(string)
+		if len(key_type.hash_algs) == 0 {
+			return cty.GetAttrPath("hash_alg").NewErrorf("hash_alg should only be specified for KCDSA and ECKCDSA")
+		}
+		if !contains(key_type.hash_algs, hash_alg) {
+			return cty.GetAttrPath("hash_alg").NewErrorf("hash_alg %s is not supported for %s, it should be one of %s", hash_alg, obj_type, strings.Join(key_type.hash_algs, ", "))
+		}
+	}
+	if len(sobjectConfigured(d, "subgroup_size")) > 0 && d.NewValueKnown("subgroup_size") {
+		subgroup_size := d.Get("subgroup_size").(int)
+		if obj_type != "DSA" && obj_type != "KCDSA" {
+			return cty.GetAttrPath("subgroup_size").NewErrorf("subgroup_size should only be specified for DSA and KCDSA")
+		}
+		if subgroup_size != 224 && subgroup_size != 256 {
+			return cty.GetAttrPath("subgroup_size").NewErrorf("subgroup_size %d is not supported, it should be 224 or 256", subgroup_size)
+		}
+		if obj_type == "DSA" && subgroup_size == 224 && d.Get("key_size").(int) != 2048 {
+			return cty.GetAttrPath("subgroup_size").NewErrorf("subgroup_size 224 is only supported when the DSA key_size is 2048")
+		}
+	}
+	return nil
+}
+
+func validateSobjectBls(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("bls") {
+		return nil
+	}
+	bls := d.Get("bls").(map[string]interface{})
+	for key := range bls {
+		if key != "variant" {
+			return cty.GetAttrPath("bls").IndexString(key).NewErrorf("bls option %s is not supported, only variant should be specified", key)
+		}
+	}
+	if variant, _ := bls["variant"].(string); variant != "small_signatures" && variant != "small_public_keys" {
+		return cty.GetAttrPath("bls").IndexString("variant").NewErrorf("bls variant %q is not supported, it should be small_signatures or small_public_keys", variant)
+	}
+	return nil
+}
+
+func validateSobjectLms(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("lms") {
+		return nil
+	}
+	lms := d.Get("lms").(map[string]interface{})
+	allowed := map[string][]int{
+		"l1_height": {5, 10, 15, 20, 25},
+		"l2_height": {5, 10, 15, 20, 25},
+		"node_size": {24, 32},
+	}
+	for _, key := range []string{"l1_height", "node_size"} {
+		if _, ok := lms[key]; !ok {
+			return cty.GetAttrPath("lms").IndexString(key).NewErrorf("lms %s should be specified, l2_height is optional", key)
+		}
+	}
+	for key, value := range lms {
+		values, ok := allowed[key]
+		if !ok {
+			return cty.GetAttrPath("lms").IndexString(key).NewErrorf("lms option %s is not supported, it should be l1_height, l2_height or node_size", key)
+		}
+		number, err := strconv.Atoi(value.(string))
+		valid := false
+		for _, allowed_value := range values {
+			valid = valid || (err == nil && number == allowed_value)
+		}
+		if !valid {
+			return cty.GetAttrPath("lms").IndexString(key).NewErrorf("lms %s %q is not supported, it should be one of %v", key, value, values)
+		}
+	}
+	return nil
+}
+
+// [-]: key_ops should be permissions of the key type
+func validateSobjectKeyOps(d *schema.ResourceDiff, obj_type string) error {
+	key_type, ok := sobject_key_types[strings.ToUpper(obj_type)]
+	if !ok || !d.NewValueKnown("key_ops") || len(sobjectConfigured(d, "key_ops")) == 0 {
+		return nil
+	}
+	if obj_type == "AES" && len(sobjectConfigured(d, "fpe", "fpe_radix")) > 0 {
+		// a tokenization object has its own permissions
+		return nil
+	}
+	for idx, key_op := range d.Get("key_ops").([]interface{}) {
+		if key_op, _ := key_op.(string); !contains(key_type.key_ops, key_op) {
+			return cty.GetAttrPath("key_ops").IndexInt(idx).NewErrorf("key_ops %s is not supported for %s, it should be one of %s", key_op, obj_type, strings.Join(key_type.key_ops, ", "))
+		}
+	}
+	return nil
+}
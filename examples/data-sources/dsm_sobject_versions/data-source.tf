@@ -0,0 +1,8 @@
+data "dsm_sobject_versions" "payments" {
+  name = "payments_key"
+}
+
+# the kids of the versions that are still active, e.g. to re-encrypt data
+output "active_kids" {
+  value = [for version in data.dsm_sobject_versions.payments.versions : version.kid if version.state == "Active"]
+}
@@ -0,0 +1,5 @@
+data "dsm_wrapped_key" "escrow" {
+  name          = "payments_key"
+  wrapping_name = "escrow_kek"
+  wrapping_alg  = "AES-KWP"
+}
@@ -3,113 +3,101 @@ variable "acct_id" {
   default = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
 }
 ## Add cryptographic policy to a Fortanix DSM account
+## An account has one cryptographic policy: the resources below are alternatives.
 
-# This resource is an example of a crypto policy with all the permissions allowed.
+# This resource is an example of a crypto policy with all the algorithms allowed.
 resource "dsm_acc_crypto_policy" "name" {
   acct_id = var.acct_id
-  cryptographic_policy = jsonencode({
+  cryptographic_policy {
     legacy_policy = "allowed" # other accepted values: prohibited and unprotect_only
     key_ops = [
       "SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "TRANSFORM", "MACGENERATE",
       "MACVERIFY", "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE"
     ]
-    aes = {
+    aes {
       key_sizes = [128, 192, 256]
     }
-    des3 = {
+    des3 {
       key_sizes = [112, 168]
     }
-    hmac = {
+    hmac {
       minimum_key_length = 112
     }
-    opaque = {}
-    rsa = {
-      encryption_policy = [
-        {
-          padding = {
-            PKCS1_V15 = {}
-          }
-        },
-        {
-          padding = {
-            RAW_DECRYPT = {}
-          }
-        },
-        {
-          padding = {
-            OAEP = {
-              mgf = {
-                mgf1 = {}
-              }
-            }
-          }
-        }
-      ]
-      signature_policy = [
-        {
-          padding = {
-            PKCS1_V15 = {}
-          }
-        },
-        {
-          padding = {
-            PSS = {
-              mgf = {
-                mgf1 = {}
-              }
-            }
-          }
-        }
-      ]
+    rsa {
       minimum_key_length = 1024
+      encryption_policy {
+        padding = "PKCS1_V15"
+      }
+      encryption_policy {
+        padding = "RAW_DECRYPT"
+      }
+      encryption_policy {
+        padding = "OAEP"
+      }
+      signature_policy {
+        padding = "PKCS1_V15"
+      }
+      signature_policy {
+        padding = "PSS"
+      }
     }
-    des = {}
-    ec = {
+    ec {
       elliptic_curves = [
         "SecP192K1", "SecP224K1", "SecP256K1", "NistP192", "NistP224",
         "NistP256", "NistP384", "NistP521", "Gost256A", "X25519", "Ed25519"
       ]
     }
-    dsa         = {}
-    secret      = {}
-    certificate = {}
-    aria        = {}
-    seed        = {}
-    kcdsa       = {}
-    eckcdsa     = {}
-  })
+    aria {}
+    opaque {}
+    des {}
+    dsa {}
+    secret {}
+    certificate {}
+    seed {}
+    kcdsa {}
+    eckcdsa {}
+  }
 }
 
 # This resource is an example of a crypto policy with some restrictions.
-# rsa, ec and dsa are defined as null, hence they are not allowed to do any operations for rsa, ec and dsa.
-# Similarly, if others are not required in the use case, those values can be defined as null.
-resource "dsm_acc_crypto_policy" "name" {
+# rsa only allows OAEP encryption and PSS signatures with MGF1 over SHA-256.
+# ec and dsa have no block, hence security objects of ec and dsa are not allowed.
+# Similarly, the blocks of the other algorithms can be left out when they are not required in the use case.
+resource "dsm_acc_crypto_policy" "restricted" {
   acct_id = var.acct_id
-  cryptographic_policy = jsonencode({
+  cryptographic_policy {
     legacy_policy = "prohibited" # other accepted values: allowed and unprotect_only
     key_ops = [
       "SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "TRANSFORM", "MACGENERATE",
       "MACVERIFY", "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE"
     ]
-    aes = {
+    aes {
       key_sizes = [128, 192, 256]
     }
-    des3 = {
+    des3 {
       key_sizes = [112, 168]
     }
-    hmac = {
+    hmac {
       minimum_key_length = 112
     }
-    opaque      = {}
-    rsa         = null
-    des         = {}
-    ec          = null
-    dsa         = null
-    secret      = {}
-    certificate = {}
-    aria        = {}
-    seed        = {}
-    kcdsa       = {}
-    eckcdsa     = {}
-  })
+    rsa {
+      minimum_key_length = 2048
+      encryption_policy {
+        padding   = "OAEP"
+        mgf1_hash = "SHA256"
+      }
+      signature_policy {
+        padding   = "PSS"
+        mgf1_hash = "SHA256"
+      }
+    }
+    aria {}
+    opaque {}
+    des {}
+    secret {}
+    certificate {}
+    seed {}
+    kcdsa {}
+    eckcdsa {}
+  }
 }
\ No newline at end of file
@@ -10,47 +10,39 @@ variable "acct_id" {
 # The user/app value should be its UUID.
 resource "dsm_acc_quorum_policy" "account_quorum_policy" {
   acct_id = var.acct_id
-  approval_policy = jsonencode({
-    policy = {
-      quorum = {
-        n = 1 # This defines that `n` member of approvals required.
-        members = [
-          {
-            quorum = {
-              n = 1, # This defines that `n` member of approvals required.
-              members = [
-                {
-                  user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                },
-                {
-                  user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                }
-              ]
-              require_2fa      = false
-              require_password = true
-            }
-          },
-          {
-            quorum = {
-              n = 1 # This defines that `n` member of approvals required.
-              members = [
-                {
-                  app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                },
-                {
-                  app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                }
-              ]
-            }
-          }
-        ]
-      }
-    }
+  approval_policy {
     manage_groups                  = false
     protect_authentication_methods = true
     protect_cryptographic_policy   = true
     protect_logging_config         = true
-  })
+    quorum {
+      n = 1 # This defines that `n` member of approvals required.
+      members {
+        quorum {
+          n                = 1 # This defines that `n` member of approvals required.
+          require_2fa      = false
+          require_password = true
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+        }
+      }
+      members {
+        quorum {
+          n = 1 # This defines that `n` member of approvals required.
+          members {
+            app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+          members {
+            app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+        }
+      }
+    }
+  }
 }
 
 # Add quorum policy to a Fortanix DSM account
@@ -60,39 +52,31 @@ resource "dsm_acc_quorum_policy" "account_quorum_policy" {
 # The user/app value should be its UUID.
 resource "dsm_acc_quorum_policy" "account_quorum_policy" {
   acct_id = var.acct_id
-  approval_policy = jsonencode({
-    policy = {
-      quorum = {
-        n = 2 # This defines that `n` member of approvals required.
-        members = [
-          {
-            quorum = {
-              n = 1 # This defines that `n` member of approvals required.
-              members = [
-                {
-                  user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                }
-              ]
-              require_2fa      = false
-              require_password = true
-            }
-          },
-          {
-            quorum = {
-              n = 1 # This defines that `n` member of approvals required.
-              members = [
-                {
-                  app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-                }
-              ]
-            }
-          }
-        ]
-      }
-    }
+  approval_policy {
     manage_groups                  = false
     protect_authentication_methods = true
     protect_cryptographic_policy   = true
     protect_logging_config         = true
-  })
+    quorum {
+      n = 2 # This defines that `n` member of approvals required.
+      members {
+        quorum {
+          n                = 1 # This defines that `n` member of approvals required.
+          require_2fa      = false
+          require_password = true
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+        }
+      }
+      members {
+        quorum {
+          n = 1 # This defines that `n` member of approvals required.
+          members {
+            app = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+        }
+      }
+    }
+  }
 }
\ No newline at end of file
@@ -9,22 +9,19 @@ resource "dsm_group" "normal_group" {
 resource "dsm_group" "aws_group" {
   name        = "aws_group"
   description = "AWS group"
-  hmg = jsonencode(
-    {
-      url = "kms.us-east-1.amazonaws.com"
-      tls = {
-        mode              = "required"
-        validate_hostname = false,
-        ca = {
-          ca_set = "global_roots"
-        }
-      }
-      kind       = "AWSKMS"
-      access_key = "XXXXXXXXXXXXXXXXXXXX"
-      secret_key = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
-      region     = "us-east-1"
-      service    = "kms"
-  })
+  hmg {
+    kind       = "AWSKMS"
+    url        = "kms.us-east-1.amazonaws.com"
+    access_key = "XXXXXXXXXXXXXXXXXXXX"
+    secret_key = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+    region     = "us-east-1"
+    service    = "kms"
+    tls {
+      mode              = "required"
+      validate_hostname = false
+      ca_set            = "global_roots"
+    }
+  }
 }
 
 # Create an AES key inside DSM
@@ -78,20 +75,17 @@ provider "dsm" {
 resource "dsm_group" "aws_group_no_credentials" {
   name        = "aws_group_no_credentials"
   description = "AWS group"
-  hmg = jsonencode(
-    {
-      url = "kms.us-east-1.amazonaws.com"
-      tls = {
-        mode              = "required"
-        validate_hostname = false,
-        ca = {
-          ca_set = "global_roots"
-        }
-      }
-      kind    = "AWSKMS"
-      region  = "us-east-1"
-      service = "kms"
-  })
+  hmg {
+    kind    = "AWSKMS"
+    url     = "kms.us-east-1.amazonaws.com"
+    region  = "us-east-1"
+    service = "kms"
+    tls {
+      mode              = "required"
+      validate_hostname = false
+      ca_set            = "global_roots"
+    }
+  }
 }
 
 # Step 4: AWS sobject creation(Copies the key from DSM)
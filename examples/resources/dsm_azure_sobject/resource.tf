@@ -2,22 +2,20 @@
 resource "dsm_group" "azure_group" {
   name        = "azure_group"
   description = "azure_group"
-  hmg = jsonencode({
-    url = "https://sampleakv.vault.azure.net/"
-    tls = {
-      mode = "required"
-      validate_hostname : false
-      ca = {
-        ca_set = "global_roots"
-      }
-    }
+  hmg {
     kind            = "AZUREKEYVAULT"
+    url             = "https://sampleakv.vault.azure.net/"
     secret_key      = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
     tenant_id       = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     client_id       = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     subscription_id = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     key_vault_type  = "STANDARD"
-  })
+    tls {
+      mode              = "required"
+      validate_hostname = false
+      ca_set            = "global_roots"
+    }
+  }
 }
 
 # Create a normal group
@@ -48,9 +46,9 @@ resource "dsm_azure_sobject" "azure_sobject" {
   custom_metadata = {
     azure-key-name = "key_inside_akv"
   }
-  rotation_policy = {
+  rotation_policy {
     interval_days          = 10
-    effective_at           = "20231130T183000Z"
+    effective_at           = "2023-11-30T18:30:00Z"
     deactivate_rotated_key = true
   }
 }
@@ -70,9 +68,9 @@ resource "dsm_azure_sobject" "sobject" {
     azure-key-name = "key_inside_akv"
     azure-key-type = "hardware"
   }
-  rotation_policy = {
+  rotation_policy {
     interval_days          = 10
-    effective_at           = "20231130T183000Z"
+    effective_at           = "2023-11-30T18:30:00Z"
     deactivate_rotated_key = true
   }
 }
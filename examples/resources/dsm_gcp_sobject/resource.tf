@@ -6,14 +6,14 @@ resource "dsm_group" "normal_group" {
 # Create GCP group
 resource "dsm_group" "gcp_group" {
   name = "gcp_group"
-  hmg = jsonencode({
+  hmg {
     kind                  = "GCPKEYRING"
     key_ring              = "key_ring_name"
     project_id            = "gcp_project_id"
     service_account_email = "test@test.iam.gserviceaccount.com"
     location              = "us-east1"
     private_key           = "<Private component of the service account key pair that can be obtained from the GCP cloud console. It is used to authenticate the requests made by DSM to the GCP cloud. This should be base64 encoded private key.>"
-  })
+  }
 }
 
 # Create an AES key in normal group
@@ -34,9 +34,9 @@ resource "dsm_gcp_sobject" "gcp_sobject" {
   custom_metadata = {
     gcp-key-id = "name-of-the-key-in-gcp"
   }
-  rotation_policy = {
+  rotation_policy {
     interval_days = 20
-    effective_at  = "20231130T183000Z"
+    effective_at  = "2023-11-30T18:30:00Z"
   }
   obj_type    = "AES"
   key_size    = 256
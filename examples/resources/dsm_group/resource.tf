@@ -12,88 +12,103 @@ resource "dsm_group" "group" {
 resource "dsm_group" "group" {
   name        = "group"
   description = "group description"
-  approval_policy = jsonencode({
+  approval_policy {
+    protect_crypto_operations = true
     protect_permissions = [
       "ROTATE_SOBJECTS", "REVOKE_SOBJECTS", "REVERT_SOBJECTS", "DELETE_KEY_MATERIAL", "DELETE_SOBJECTS",
       "DESTROY_SOBJECTS", "MOVE_SOBJECTS", "CREATE_SOBJECTS", "UPDATE_SOBJECTS_PROFILE", "UPDATE_SOBJECTS_ENABLED_STATE",
       "UPDATE_SOBJECT_POLICIES", "ACTIVATE_SOBJECTS", "UPDATE_KEY_OPS"
     ]
-    protect_crypto_operations = true
-    quorum = {
-      n = 1, # This defines that `n` member of approvals required.
-      members = [
-        {
-          quorum = {
-            n = 1 # This defines that `n` member of approvals required.
-            members = [
-              {
-                user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-              },
-              {
-                user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-              }
-            ]
-            require_2fa      = false,
-            require_password = false
+    quorum {
+      n = 1 # This defines that `n` member of approvals required.
+      members {
+        quorum {
+          n                = 1 # This defines that `n` member of approvals required.
+          require_2fa      = false
+          require_password = false
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
           }
-        },
-        {
-          quorum = {
-            n = 1 # This defines that `n` member of approvals required.
-            members = [
-              {
-                user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-              }
-            ]
-            require_2fa      = false,
-            require_password = false
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+          }
+        }
+      }
+      members {
+        quorum {
+          n                = 1 # This defines that `n` member of approvals required.
+          require_2fa      = false
+          require_password = false
+          members {
+            user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
           }
         }
-      ]
-    }
-  })
-  hmg = jsonencode({
-    url = "https://sampleakv.vault.azure.net/"
-    tls = {
-      mode = "required"
-      validate_hostname : false
-      ca = {
-        ca_set = "global_roots"
       }
     }
+  }
+  hmg {
     kind            = "AZUREKEYVAULT"
+    url             = "https://sampleakv.vault.azure.net/"
     secret_key      = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
     tenant_id       = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     client_id       = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     subscription_id = "0XXXXXXX-YYYY-HHHH-GGGG-123456789123"
     key_vault_type  = "STANDARD"
-  })
+    tls {
+      mode              = "required"
+      validate_hostname = false
+      ca_set            = "global_roots"
+    }
+  }
 }
 
 
+# Create a failover group of two AWS KMS regions
+# DSM uses the HMG of the lowest hsm_order first.
+resource "dsm_group" "failover_group" {
+  name           = "failover_group"
+  hmg_redundancy = "PriorityFailover"
+  hmg {
+    kind       = "AWSKMS"
+    url        = "kms.us-east-1.amazonaws.com"
+    access_key = "XXXXXXXXXXXXXXXXXXXX"
+    secret_key = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+    region     = "us-east-1"
+    service    = "kms"
+    hsm_order  = 0
+  }
+  hmg {
+    kind       = "AWSKMS"
+    url        = "kms.us-west-2.amazonaws.com"
+    access_key = "XXXXXXXXXXXXXXXXXXXX"
+    secret_key = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+    region     = "us-west-2"
+    service    = "kms"
+    hsm_order  = 1
+  }
+}
+
 # Create a group with basic approval_policy
 # The following resource group is an example of approval policy which has configured with a single user.
 resource "dsm_group" "group" {
   name        = "group"
   description = "group description"
-  approval_policy = jsonencode({
+  approval_policy {
+    protect_crypto_operations = true
     protect_permissions = [
       "ROTATE_SOBJECTS", "REVOKE_SOBJECTS", "REVERT_SOBJECTS", "DELETE_KEY_MATERIAL", "DELETE_SOBJECTS",
       "DESTROY_SOBJECTS", "MOVE_SOBJECTS", "CREATE_SOBJECTS", "UPDATE_SOBJECTS_PROFILE", "UPDATE_SOBJECTS_ENABLED_STATE",
       "UPDATE_SOBJECT_POLICIES", "ACTIVATE_SOBJECTS", "UPDATE_KEY_OPS"
     ]
-    protect_crypto_operations = true
-    quorum = {
-      n = 1 # This defines that `n` member of approvals required.
-      members = [
-        {
-          user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
-        }
-      ]
+    quorum {
+      n                = 1 # This defines that `n` member of approvals required.
       require_password = false
       require_2fa      = false
+      members {
+        user = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+      }
     }
-  })
+  }
 }
 
 
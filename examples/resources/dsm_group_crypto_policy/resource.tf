@@ -5,81 +5,81 @@ resource "dsm_group" "group" {
 
 # Adding cryptographic policy to the group
 
-# This resource is an example of a crypto policy with all the permissions allowed.
+# This resource is an example of a crypto policy with all the algorithms allowed.
 resource "dsm_group_crypto_policy" "group_crypto_policy" {
   name = dsm_group.group.name
-  cryptographic_policy = jsonencode({
+  cryptographic_policy {
     legacy_policy = "allowed"
     key_ops = [
       "SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "MACGENERATE",
-      "MACVERIFY", "EXhasPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE", "TRANSFORM"
+      "MACVERIFY", "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE", "TRANSFORM"
     ]
-    aes = {
+    aes {
       key_sizes = [128, 192, 256]
     }
-    des3 = {
+    des3 {
       key_sizes = [112, 168]
     }
-    hmac = {
+    hmac {
       minimum_key_length = 112
     }
-    opaque = {}
-    rsa = {
-      encryption_policy  = []
-      signature_policy   = []
+    rsa {
       minimum_key_length = 1024
     }
-    des = {}
-    ec = {
+    ec {
       elliptic_curves = [
         "SecP192K1", "SecP224K1", "SecP256K1", "NistP192", "NistP224", "NistP256",
         "NistP384", "NistP521", "Gost256A", "X25519", "Ed25519"
       ]
     }
-    dsa         = {}
-    secret      = {}
-    certificate = {}
-    aria        = {}
-    seed        = {}
-    kcdsa       = {}
-    eckcdsa     = {}
-    bip32       = {}
-    lms         = {}
-    mlkem_beta  = {}
-    bls         = {}
-  })
+    aria {}
+    opaque {}
+    des {}
+    dsa {}
+    secret {}
+    certificate {}
+    seed {}
+    kcdsa {}
+    eckcdsa {}
+    bip32 {}
+    lms {}
+    mlkem_beta {}
+    bls {}
+  }
+}
+
+# Create another group
+resource "dsm_group" "restricted_group" {
+  name = "restricted_group"
 }
 
 # This resource is an example of a crypto policy with some restrictions.
-# rsa, ec and dsa are defined as null, hence they are not allowed to do any operations for rsa, ec and dsa.
-# Similarly, if others are not required in the use case, those values can be defined as null.
-resource "dsm_group_crypto_policy" "group_crypto_policy" {
-  name = dsm_group.group.name
-  cryptographic_policy = jsonencode({
+# rsa, ec and dsa have no block, hence security objects of rsa, ec and dsa are not allowed.
+# Similarly, the blocks of the other algorithms can be left out when they are not required in the use case.
+resource "dsm_group_crypto_policy" "restricted_group_crypto_policy" {
+  name = dsm_group.restricted_group.name
+  cryptographic_policy {
     legacy_policy = "prohibited" # other values: allowed and unprotect_only
     key_ops = [
       "SIGN", "VERIFY", "ENCRYPT", "DECRYPT", "WRAPKEY", "UNWRAPKEY", "DERIVEKEY", "TRANSFORM", "MACGENERATE",
-      "MACVERIFY",has "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE"
+      "MACVERIFY", "EXPORT", "APPMANAGEABLE", "AGREEKEY", "ENCAPSULATE", "DECAPSULATE"
     ]
-    aes = {
+    aes {
       key_sizes = [128, 192, 256]
     }
-    des3 = {
+    des3 {
       key_sizes = [112, 168]
     }
-    hmac = {
+    hmac {
       minimum_key_length = 112
     }
-    opaque      = {}
-    rsa         = null
-    des         = {}
-    ec          = null
-    dsa         = null
-    secret      = {}
-    certificate = {}
-    aria        = {}
-    seed        = {}
-    kcdsa       = {}
-    eckcdsa     = {}
-  })
+    aria {}
+    opaque {}
+    des {}
+    secret {}
+    certificate {}
+    seed {}
+    kcdsa {}
+    eckcdsa {}
+  }
 }
\ No newline at end of file
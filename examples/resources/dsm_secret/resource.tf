@@ -15,6 +15,19 @@ resource "dsm_secret" "secret" {
   expiry_date = "2025-02-02T17:04:05Z"
 }
 
+# Generate a secret in the provider and import it
+resource "dsm_secret" "secret_generated" {
+  name        = "secret_generated"
+  group_id    = dsm_group.group.id
+  description = "generated secret"
+  enabled     = true
+  generate {
+    length             = 40
+    min_special        = 4
+    exclude_characters = "\"'`"
+  }
+}
+
 # Rotate a secret
 resource "dsm_secret" "secret_rotate" {
   name        = "secret_rotate"
@@ -31,9 +31,9 @@ resource "dsm_sobject" "sobject" {
     "CUSTOMER_INITIATED_ACCESS"
   ]
   allowed_missing_justifications = true
-  rotation_policy = {
+  rotation_policy {
     interval_days          = 20
-    effective_at           = "20231130T183000Z"
+    effective_at           = "2023-11-30T18:30:00Z"
     deactivate_rotated_key = true
     rotate_copied_keys     = "all_external"
   }
@@ -68,9 +68,9 @@ resource "dsm_sobject" "sobject_copy" {
   custom_metadata = {
     key1 = "value1"
   }
-  rotation_policy = {
+  rotation_policy {
     interval_days          = 20
-    effective_at           = "20231130T183000Z"
+    effective_at           = "2023-11-30T18:30:00Z"
     deactivate_rotated_key = true
     rotate_copied_keys     = "all_external"
   }
@@ -88,6 +88,18 @@ resource "dsm_sobject" "sobject_rotate" {
   rotate_from = "sobject"
 }
 
+# rotate a security object in place, when a trigger changes or every 90 days
+resource "dsm_sobject" "sobject_rotate_in_place" {
+  name     = "sobject_rotate_in_place"
+  obj_type = "AES"
+  group_id = dsm_group.group.id
+  key_size = 256
+  rotation_triggers = {
+    version = "1"
+  }
+  rotate_after_days = 90
+}
+
 ## import a security object
 
 # This is an example of importing a certificate
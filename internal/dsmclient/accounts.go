@@ -0,0 +1,39 @@
+// **********
+// Terraform Provider - DSM: typed API client: accounts
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const accounts_endpoint = "sys/v1/accounts"
+
+// [-] Structs to define DSM Account
+type Account struct {
+	Acct_id              string                 `json:"acct_id"`
+	Name                 string                 `json:"name"`
+	Description          *string                `json:"description,omitempty"`
+	Approval_policy      map[string]interface{} `json:"approval_policy,omitempty"`
+	Cryptographic_policy map[string]interface{} `json:"cryptographic_policy,omitempty"`
+}
+
+// GetAccount reads an account.
+func (c *Client) GetAccount(ctx context.Context, acct_id string) (*Account, error) {
+	account := &Account{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", accounts_endpoint, acct_id), nil, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateAccount updates an account, e.g. its quorum or cryptographic policy.
+func (c *Client) UpdateAccount(ctx context.Context, acct_id string, body interface{}) (*Account, error) {
+	account := &Account{}
+	if err := c.call(ctx, "PATCH", fmt.Sprintf("%s/%s", accounts_endpoint, acct_id), body, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
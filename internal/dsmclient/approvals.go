@@ -0,0 +1,77 @@
+// **********
+// Terraform Provider - DSM: typed API client: approval requests
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const approvals_endpoint = "sys/v1/approval_requests"
+
+// status of an approval request
+const (
+	ApprovalPending  = "PENDING"
+	ApprovalApproved = "APPROVED"
+	ApprovalDenied   = "DENIED"
+	ApprovalFailed   = "FAILED"
+)
+
+// [-] Structs to define DSM Approval Request
+type ApprovalRequest struct {
+	Request_id  string      `json:"request_id"`
+	Acct_id     string      `json:"acct_id,omitempty"`
+	Operation   string      `json:"operation"`
+	Method      string      `json:"method"`
+	Body        interface{} `json:"body,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Status      string      `json:"status"`
+	Requester   DSMCreator  `json:"requester"`
+	Created_at  string      `json:"created_at,omitempty"`
+	Expiry      string      `json:"expiry,omitempty"`
+}
+
+// [-] Structs to define the result of an approved request
+type ApprovalResult struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"`
+}
+
+// CreateApprovalRequest files a request for an operation guarded by a quorum policy.
+func (c *Client) CreateApprovalRequest(ctx context.Context, body interface{}) (*ApprovalRequest, error) {
+	approval := &ApprovalRequest{}
+	if err := c.call(ctx, "POST", approvals_endpoint, body, approval); err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+// GetApprovalRequest reads an approval request.
+func (c *Client) GetApprovalRequest(ctx context.Context, request_id string) (*ApprovalRequest, error) {
+	approval := &ApprovalRequest{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", approvals_endpoint, request_id), nil, approval); err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+// ApprovalRequestResult runs an approved request and returns the result of the operation.
+func (c *Client) ApprovalRequestResult(ctx context.Context, request_id string) (*ApprovalResult, error) {
+	result := &ApprovalResult{}
+	if err := c.call(ctx, "POST", fmt.Sprintf("%s/%s/result", approvals_endpoint, request_id), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DenyApprovalRequest denies an approval request.
+func (c *Client) DenyApprovalRequest(ctx context.Context, request_id string) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/deny", approvals_endpoint, request_id), nil, nil)
+}
+
+// DeleteApprovalRequest deletes an approval request.
+func (c *Client) DeleteApprovalRequest(ctx context.Context, request_id string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s", approvals_endpoint, request_id), nil, nil)
+}
@@ -0,0 +1,102 @@
+// **********
+// Terraform Provider - DSM: typed API client: apps
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const apps_endpoint = "sys/v1/apps"
+
+// [-] Structs to define DSM App
+type App struct {
+	App_id        string                 `json:"app_id"`
+	Name          string                 `json:"name"`
+	Description   *string                `json:"description,omitempty"`
+	Acct_id       string                 `json:"acct_id"`
+	Creator       DSMCreator             `json:"creator"`
+	App_type      string                 `json:"app_type,omitempty"`
+	Default_group string                 `json:"default_group"`
+	Groups        map[string]interface{} `json:"groups,omitempty"`
+	Enabled       bool                   `json:"enabled"`
+}
+
+// [-] Structs to define the credential of a DSM App, only one kind is set
+type AppCredential struct {
+	Secret      *string                   `json:"secret,omitempty"`
+	Certificate *AppCertificateCredential `json:"certificate,omitempty"`
+	Awsxks      *AppAWSXKSCredential      `json:"awsxks,omitempty"`
+}
+
+type AppCertificateCredential struct {
+	Certificate string `json:"certificate"`
+}
+
+type AppAWSXKSCredential struct {
+	Access_key_id string `json:"access_key_id"`
+	Secret_key    string `json:"secret_key"`
+}
+
+// GetApp reads an app.
+func (c *Client) GetApp(ctx context.Context, app_id string) (*App, error) {
+	app := &App{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", apps_endpoint, app_id), nil, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// ListApps lists every app of the account.
+func (c *Client) ListApps(ctx context.Context) ([]App, error) {
+	var apps []App
+	if err := c.list(ctx, apps_endpoint, &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// GetAppCredential reads the credential of an app.
+func (c *Client) GetAppCredential(ctx context.Context, app_id string) (*AppCredential, error) {
+	var resp struct {
+		Credential AppCredential `json:"credential"`
+	}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s/credential", apps_endpoint, app_id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Credential, nil
+}
+
+// CreateApp creates an app.
+func (c *Client) CreateApp(ctx context.Context, body interface{}) (*App, error) {
+	app := &App{}
+	if err := c.call(ctx, "POST", apps_endpoint, body, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// UpdateApp updates an app.
+func (c *Client) UpdateApp(ctx context.Context, app_id string, body interface{}) (*App, error) {
+	app := &App{}
+	if err := c.call(ctx, "PATCH", fmt.Sprintf("%s/%s", apps_endpoint, app_id), body, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// ResetAppSecret generates a new API key for an app.
+func (c *Client) ResetAppSecret(ctx context.Context, app_id string, body interface{}) (*App, error) {
+	app := &App{}
+	if err := c.call(ctx, "POST", fmt.Sprintf("%s/%s/reset_secret", apps_endpoint, app_id), body, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// DeleteApp deletes an app.
+func (c *Client) DeleteApp(ctx context.Context, app_id string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s", apps_endpoint, app_id), nil, nil)
+}
@@ -0,0 +1,157 @@
+// **********
+// Terraform Provider - DSM: typed API client
+// **********
+
+// Package dsmclient is a typed client for the Fortanix DSM REST API.
+//
+// It only knows about API paths and JSON models. Authentication, retries,
+// rate limiting and error decoding are left to the Transport, which is the
+// session of the provider.
+package dsmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Transport sends a request to DSM and returns the body of a 2xx response.
+// Any other response must be returned as an error.
+type Transport interface {
+	Do(ctx context.Context, method string, path string, body []byte) ([]byte, error)
+}
+
+// Client calls the DSM REST API through a Transport.
+type Client struct {
+	transport Transport
+}
+
+// New returns a Client using the given Transport.
+func New(transport Transport) *Client {
+	return &Client{transport: transport}
+}
+
+// page size used to walk DSM list endpoints
+const list_page_size = 100
+
+// [-]: build an endpoint with query parameters, e.g. crypto/v1/keys?name=<name>
+func withQuery(path string, query map[string]string) string {
+	values := url.Values{}
+	for k, v := range query {
+		values.Set(k, v)
+	}
+	if len(values) == 0 {
+		return path
+	}
+	return path + "?" + values.Encode()
+}
+
+// [-]: send a request, in is marshalled as the body when not nil and the
+// response is unmarshalled into out when not nil
+func (c *Client) call(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("%s %s: unable to marshal request body: %w", method, path, err)
+		}
+	}
+	resp, err := c.transport.Do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(bytes.TrimSpace(resp)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp, out); err != nil {
+		return fmt.Errorf("%s %s: unable to unmarshal response: %w", method, path, err)
+	}
+	return nil
+}
+
+// [-]: read a list endpoint
+// Every page is requested with limit/offset. DSM answers either with a JSON
+// array or with an object holding the array in "items".
+func (c *Client) list(ctx context.Context, path string, out interface{}) error {
+	endpoint, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	query := endpoint.Query()
+	query.Set("limit", strconv.Itoa(list_page_size))
+
+	var items []json.RawMessage
+	var previous json.RawMessage
+	for offset := 0; ; offset += list_page_size {
+		query.Set("offset", strconv.Itoa(offset))
+		endpoint.RawQuery = query.Encode()
+		page, err := c.listPage(ctx, endpoint.String())
+		if err != nil {
+			return err
+		}
+		if len(page) > 0 {
+			// an endpoint that ignores offset returns the same page again
+			if previous != nil && bytes.Equal(page[0], previous) {
+				break
+			}
+			previous = page[0]
+		}
+		items = append(items, page...)
+		// a short page is the last one, a page longer than limit means
+		// the endpoint does not paginate and returned everything at once.
+		if len(page) != list_page_size {
+			break
+		}
+	}
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	all, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	if err := json.Unmarshal(all, out); err != nil {
+		return fmt.Errorf("GET %s: unable to unmarshal response: %w", path, err)
+	}
+	return nil
+}
+
+// [-]: read a single page of a list endpoint
+func (c *Client) listPage(ctx context.Context, path string) ([]json.RawMessage, error) {
+	resp, err := c.transport.Do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp = bytes.TrimSpace(resp)
+	if len(resp) == 0 {
+		return nil, nil
+	}
+	var page []json.RawMessage
+	if resp[0] == '[' {
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("GET %s: unable to unmarshal response: %w -> %s", path, err, resp)
+		}
+		return page, nil
+	}
+	var wrapped struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(resp, &wrapped); err != nil {
+		return nil, fmt.Errorf("GET %s: unable to unmarshal response: %w -> %s", path, err, resp)
+	}
+	return wrapped.Items, nil
+}
+
+// List reads every item of a list endpoint that has no typed method yet,
+// e.g. sys/v1/roles.
+func (c *Client) List(ctx context.Context, path string) ([]interface{}, error) {
+	var items []interface{}
+	if err := c.list(ctx, path, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
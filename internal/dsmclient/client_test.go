@@ -0,0 +1,124 @@
+package dsmclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// fakeTransport answers from a map of path to response body
+type fakeTransport struct {
+	responses map[string]string
+	requests  []string
+}
+
+func (f *fakeTransport) Do(_ context.Context, method string, path string, body []byte) ([]byte, error) {
+	f.requests = append(f.requests, method+" "+path)
+	resp, ok := f.responses[method+" "+path]
+	if !ok {
+		return nil, fmt.Errorf("%s %s: not found", method, path)
+	}
+	return []byte(resp), nil
+}
+
+func TestListPagination(t *testing.T) {
+	fake := &fakeTransport{responses: map[string]string{}}
+	var first, second []map[string]string
+	for i := 0; i < list_page_size; i++ {
+		first = append(first, map[string]string{"group_id": strconv.Itoa(i), "name": "g" + strconv.Itoa(i)})
+	}
+	second = append(second, map[string]string{"group_id": "last", "name": "last"})
+	page := func(offset int) string {
+		q := url.Values{}
+		q.Set("limit", strconv.Itoa(list_page_size))
+		q.Set("offset", strconv.Itoa(offset))
+		return "GET sys/v1/groups?" + q.Encode()
+	}
+	b, _ := json.Marshal(first)
+	fake.responses[page(0)] = string(b)
+	b, _ = json.Marshal(map[string]interface{}{"items": second})
+	fake.responses[page(list_page_size)] = string(b)
+
+	groups, err := New(fake).ListGroups(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != list_page_size+1 || groups[list_page_size].Group_id != "last" {
+		t.Fatalf("unexpected groups: %d", len(groups))
+	}
+
+	// the name is sent as a filter, the exact match is still checked
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(list_page_size))
+	q.Set("name", "g7")
+	q.Set("offset", "0")
+	fake.responses["GET sys/v1/groups?"+q.Encode()] = `[{"group_id": "70", "name": "g70"}, {"group_id": "7", "name": "g7"}]`
+	group, err := New(fake).FindGroup(context.Background(), "g7")
+	if err != nil || group == nil || group.Group_id != "7" {
+		t.Fatalf("unexpected group: %v %v", group, err)
+	}
+	if last := fake.requests[len(fake.requests)-1]; last != "GET sys/v1/groups?"+q.Encode() {
+		t.Fatalf("unexpected request %s", last)
+	}
+}
+
+func TestGetKeyViews(t *testing.T) {
+	fake := &fakeTransport{responses: map[string]string{
+		"GET crypto/v1/keys/k1?show_deleted=true&show_destroyed=true": `{
+			"kid": "k1", "name": "aws-key", "obj_type": "AES", "key_size": 256,
+			"key_ops": ["ENCRYPT", "DECRYPT"], "enabled": true, "state": "Active",
+			"creator": {"user": "u1"},
+			"custom_metadata": {"aws-key-state": "Enabled", "aws-aliases": "alias/k1"},
+			"external": {"hsm_group_id": "g1", "id": {"key_arn": "arn:k1", "key_id": "id1"}},
+			"links": {"copiedFrom": "k0"}
+		}`,
+	}}
+	sobject, err := New(fake).GetKeyAnyState(context.Background(), "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sobject.Key_size == nil || *sobject.Key_size != 256 || len(sobject.Key_ops) != 2 {
+		t.Fatalf("unexpected sobject: %#v", sobject)
+	}
+	if sobject.Description != nil || sobject.Deactivation_date != nil {
+		t.Fatal("absent fields must decode as nil")
+	}
+	if creator := sobject.Creator.Map(); len(creator) != 1 || creator["user"] != "u1" {
+		t.Fatalf("unexpected creator: %v", creator)
+	}
+	if links := sobject.Links.Map(); links["copiedFrom"] != "k0" {
+		t.Fatalf("unexpected links: %v", links)
+	}
+
+	aws, err := sobject.AWS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.External.Id.Key_arn != "arn:k1" || aws.Custom_metadata.Aws_key_state != "Enabled" {
+		t.Fatalf("unexpected AWS view: %#v", aws)
+	}
+}
+
+func TestAppCredential(t *testing.T) {
+	fake := &fakeTransport{responses: map[string]string{
+		"GET sys/v1/apps/a1/credential": `{"credential": {"secret": "s3cr3t"}}`,
+		"DELETE sys/v1/apps/a1":         ``,
+	}}
+	client := New(fake)
+	credential, err := client.GetAppCredential(context.Background(), "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.Secret == nil || *credential.Secret != "s3cr3t" || credential.Certificate != nil {
+		t.Fatalf("unexpected credential: %#v", credential)
+	}
+	if err := client.DeleteApp(context.Background(), "a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetApp(context.Background(), "missing"); err == nil {
+		t.Fatal("expected the transport error")
+	}
+}
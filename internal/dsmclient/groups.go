@@ -0,0 +1,109 @@
+// **********
+// Terraform Provider - DSM: typed API client: groups
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const groups_endpoint = "sys/v1/groups"
+
+// [-] Structs to define DSM Group
+type Group struct {
+	Group_id             string                 `json:"group_id"`
+	Name                 string                 `json:"name"`
+	Description          *string                `json:"description,omitempty"`
+	Acct_id              string                 `json:"acct_id"`
+	Creator              DSMCreator             `json:"creator"`
+	Approval_policy      map[string]interface{} `json:"approval_policy,omitempty"`
+	Cryptographic_policy map[string]interface{} `json:"cryptographic_policy,omitempty"`
+	Hmg                  map[string]interface{} `json:"hmg,omitempty"`
+	Hmg_redundancy       string                 `json:"hmg_redundancy,omitempty"`
+}
+
+// [-]: the AWS KMS view of a BYOK group
+func (g *Group) AWS() (*AWSGroup, error) {
+	aws := &AWSGroup{}
+	if err := convert(g, aws); err != nil {
+		return nil, err
+	}
+	return aws, nil
+}
+
+// [-]: the Azure Key Vault view of a BYOK group
+func (g *Group) Azure() (*AzureGroup, error) {
+	azure := &AzureGroup{}
+	if err := convert(g, azure); err != nil {
+		return nil, err
+	}
+	return azure, nil
+}
+
+// GetGroup reads a group.
+func (c *Client) GetGroup(ctx context.Context, group_id string) (*Group, error) {
+	group := &Group{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", groups_endpoint, group_id), nil, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroups lists every group of the account.
+func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	if err := c.list(ctx, groups_endpoint, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// FindGroup returns the group with the given name, or nil if there is none.
+// DSM filters the groups by name, the exact match is checked again here.
+func (c *Client) FindGroup(ctx context.Context, name string) (*Group, error) {
+	var groups []Group
+	if err := c.list(ctx, withQuery(groups_endpoint, map[string]string{"name": name}), &groups); err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Name == name {
+			return &groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateGroup creates a group.
+func (c *Client) CreateGroup(ctx context.Context, body interface{}) (*Group, error) {
+	group := &Group{}
+	if err := c.call(ctx, "POST", groups_endpoint, body, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UpdateGroup updates a group.
+func (c *Client) UpdateGroup(ctx context.Context, group_id string, body interface{}) (*Group, error) {
+	group := &Group{}
+	if err := c.call(ctx, "PATCH", fmt.Sprintf("%s/%s", groups_endpoint, group_id), body, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteGroup deletes a group, DSM refuses to delete a group that is not empty.
+func (c *Client) DeleteGroup(ctx context.Context, group_id string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s", groups_endpoint, group_id), nil, nil)
+}
+
+// CheckGroupHmg checks the connection of a BYOK group to its external key manager.
+func (c *Client) CheckGroupHmg(ctx context.Context, group_id string) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/hmg/check", groups_endpoint, group_id), map[string]interface{}{}, nil)
+}
+
+// ScanGroupHmg imports the keys of the external key manager of a BYOK group.
+func (c *Client) ScanGroupHmg(ctx context.Context, group_id string) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/hmg/scan", groups_endpoint, group_id), map[string]interface{}{}, nil)
+}
@@ -0,0 +1,187 @@
+// **********
+// Terraform Provider - DSM: typed API client: security objects
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const keys_endpoint = "crypto/v1/keys"
+
+// [-] Structs to define DSM Security Object
+type Sobject struct {
+	Kid                         string                    `json:"kid"`
+	Name                        string                    `json:"name"`
+	Description                 *string                   `json:"description,omitempty"`
+	Acct_id                     string                    `json:"acct_id"`
+	Group_id                    string                    `json:"group_id"`
+	Creator                     DSMCreator                `json:"creator"`
+	Obj_type                    string                    `json:"obj_type"`
+	Origin                      string                    `json:"origin,omitempty"`
+	Key_size                    *int                      `json:"key_size,omitempty"`
+	Elliptic_curve              *string                   `json:"elliptic_curve,omitempty"`
+	Key_ops                     []string                  `json:"key_ops"`
+	Pub_key                     *string                   `json:"pub_key,omitempty"`
+	Value                       *string                   `json:"value,omitempty"`
+	Enabled                     bool                      `json:"enabled"`
+	State                       string                    `json:"state"`
+	Created_at                  string                    `json:"created_at,omitempty"`
+	Activation_date             *string                   `json:"activation_date,omitempty"`
+	Deactivation_date           *string                   `json:"deactivation_date,omitempty"`
+	Custom_metadata             map[string]string         `json:"custom_metadata,omitempty"`
+	Links                       *DSMSobjectLinks          `json:"links,omitempty"`
+	External                    *SobjectExternal          `json:"external,omitempty"`
+	Google_access_reason_policy *GoogleAccessReasonPolicy `json:"google_access_reason_policy,omitempty"`
+	Dsa                         *SobjectSubgroup          `json:"dsa,omitempty"`
+	Kcdsa                       *SobjectSubgroup          `json:"kcdsa,omitempty"`
+	Eckcdsa                     *SobjectSubgroup          `json:"eckcdsa,omitempty"`
+	Fpe                         map[string]interface{}    `json:"fpe,omitempty"`
+	Rsa                         map[string]interface{}    `json:"rsa,omitempty"`
+	Rotation_policy             map[string]interface{}    `json:"rotation_policy,omitempty"`
+	Bls                         map[string]interface{}    `json:"bls,omitempty"`
+	Lms                         map[string]interface{}    `json:"lms,omitempty"`
+}
+
+// external key of a security object copied to a BYOK (AWS, Azure, GCP) group
+type SobjectExternal struct {
+	Hsm_group_id string                 `json:"hsm_group_id"`
+	Id           map[string]interface{} `json:"id"`
+}
+
+type GoogleAccessReasonPolicy struct {
+	Allow                []string `json:"allow"`
+	Allow_missing_reason bool     `json:"allow_missing_reason"`
+}
+
+// subgroup and hash parameters of DSA, KCDSA and ECKCDSA keys
+type SobjectSubgroup struct {
+	Subgroup_size *int    `json:"subgroup_size,omitempty"`
+	Hash_alg      *string `json:"hash_alg,omitempty"`
+}
+
+// [-]: the AWS KMS view of a security object copied to an AWS group
+func (s *Sobject) AWS() (*AWSSobject, error) {
+	aws := &AWSSobject{}
+	if err := convert(s, aws); err != nil {
+		return nil, err
+	}
+	return aws, nil
+}
+
+// [-]: the Azure Key Vault view of a security object copied to an Azure group
+func (s *Sobject) Azure() (*AzureSobject, error) {
+	azure := &AzureSobject{}
+	if err := convert(s, azure); err != nil {
+		return nil, err
+	}
+	return azure, nil
+}
+
+// [-]: convert between two JSON models
+func convert(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// GetKey reads an active security object.
+func (c *Client) GetKey(ctx context.Context, kid string) (*Sobject, error) {
+	sobject := &Sobject{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", keys_endpoint, kid), nil, sobject); err != nil {
+		return nil, err
+	}
+	return sobject, nil
+}
+
+// GetKeyAnyState reads a security object, including destroyed and deleted ones.
+func (c *Client) GetKeyAnyState(ctx context.Context, kid string) (*Sobject, error) {
+	sobject := &Sobject{}
+	path := withQuery(fmt.Sprintf("%s/%s", keys_endpoint, kid), map[string]string{"show_destroyed": "true", "show_deleted": "true"})
+	if err := c.call(ctx, "GET", path, nil, sobject); err != nil {
+		return nil, err
+	}
+	return sobject, nil
+}
+
+// ListKeys lists security objects, filters are DSM query parameters such as name or group_id.
+func (c *Client) ListKeys(ctx context.Context, filters map[string]string) ([]Sobject, error) {
+	var sobjects []Sobject
+	if err := c.list(ctx, withQuery(keys_endpoint, filters), &sobjects); err != nil {
+		return nil, err
+	}
+	return sobjects, nil
+}
+
+// CreateKey generates a security object.
+func (c *Client) CreateKey(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "POST", keys_endpoint, body)
+}
+
+// ImportKey imports a security object.
+func (c *Client) ImportKey(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "PUT", keys_endpoint, body)
+}
+
+// UpdateKey updates the properties of a security object.
+func (c *Client) UpdateKey(ctx context.Context, kid string, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "PATCH", fmt.Sprintf("%s/%s", keys_endpoint, kid), body)
+}
+
+// RotateKey replaces a security object by a new one with the same name.
+func (c *Client) RotateKey(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "POST", keys_endpoint+"/rekey", body)
+}
+
+// CopyKey copies a security object, e.g. into a BYOK group.
+func (c *Client) CopyKey(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "POST", keys_endpoint+"/copy", body)
+}
+
+// ExportKey reads a security object with its value.
+func (c *Client) ExportKey(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "POST", keys_endpoint+"/export", body)
+}
+
+// KeyInfo reads a security object identified by name or kid in body.
+func (c *Client) KeyInfo(ctx context.Context, body interface{}) (*Sobject, error) {
+	return c.keyCall(ctx, "POST", keys_endpoint+"/info", body)
+}
+
+// DeleteKey deletes a security object.
+func (c *Client) DeleteKey(ctx context.Context, kid string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s", keys_endpoint, kid), nil, nil)
+}
+
+// RevokeKey deactivates or compromises a security object.
+func (c *Client) RevokeKey(ctx context.Context, kid string, body interface{}) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/revoke", keys_endpoint, kid), body, nil)
+}
+
+// DestroyKey destroys a security object.
+func (c *Client) DestroyKey(ctx context.Context, kid string) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/destroy", keys_endpoint, kid), nil, nil)
+}
+
+// ScheduleKeyDeletion schedules the deletion of a BYOK security object.
+func (c *Client) ScheduleKeyDeletion(ctx context.Context, kid string, body interface{}) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/schedule_deletion", keys_endpoint, kid), body, nil)
+}
+
+// DeleteKeyMaterial removes the key material of a BYOK security object.
+func (c *Client) DeleteKeyMaterial(ctx context.Context, kid string) error {
+	return c.call(ctx, "POST", fmt.Sprintf("%s/%s/delete_key_material", keys_endpoint, kid), nil, nil)
+}
+
+func (c *Client) keyCall(ctx context.Context, method string, path string, body interface{}) (*Sobject, error) {
+	sobject := &Sobject{}
+	if err := c.call(ctx, method, path, body, sobject); err != nil {
+		return nil, err
+	}
+	return sobject, nil
+}
@@ -0,0 +1,95 @@
+// **********
+// Terraform Provider - DSM: typed API client: plugins
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const plugins_endpoint = "sys/v1/plugins"
+
+// [-] Structs to define DSM Plugin
+type Plugin struct {
+	Plugin_id     string        `json:"plugin_id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	Acct_id       string        `json:"acct_id"`
+	Creator       DSMCreator    `json:"creator"`
+	Default_group string        `json:"default_group"`
+	Groups        []string      `json:"groups"`
+	Enabled       bool          `json:"enabled"`
+	Plugin_type   string        `json:"plugin_type,omitempty"`
+	Source        *PluginSource `json:"source,omitempty"`
+}
+
+type PluginSource struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// GetPlugin reads a plugin.
+func (c *Client) GetPlugin(ctx context.Context, plugin_id string) (*Plugin, error) {
+	plugin := &Plugin{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", plugins_endpoint, plugin_id), nil, plugin); err != nil {
+		return nil, err
+	}
+	return plugin, nil
+}
+
+// ListPlugins lists every plugin of the account.
+func (c *Client) ListPlugins(ctx context.Context) ([]Plugin, error) {
+	var plugins []Plugin
+	if err := c.list(ctx, plugins_endpoint, &plugins); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
+
+// FindPlugin returns the plugin with the given name, or nil if there is none.
+func (c *Client) FindPlugin(ctx context.Context, name string) (*Plugin, error) {
+	plugins, err := c.ListPlugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plugins {
+		if plugins[i].Name == name {
+			return &plugins[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreatePlugin creates a plugin.
+func (c *Client) CreatePlugin(ctx context.Context, body interface{}) (*Plugin, error) {
+	plugin := &Plugin{}
+	if err := c.call(ctx, "POST", plugins_endpoint, body, plugin); err != nil {
+		return nil, err
+	}
+	return plugin, nil
+}
+
+// UpdatePlugin updates a plugin.
+func (c *Client) UpdatePlugin(ctx context.Context, plugin_id string, body interface{}) (*Plugin, error) {
+	plugin := &Plugin{}
+	if err := c.call(ctx, "PATCH", fmt.Sprintf("%s/%s", plugins_endpoint, plugin_id), body, plugin); err != nil {
+		return nil, err
+	}
+	return plugin, nil
+}
+
+// InvokePlugin runs a plugin and returns its output.
+func (c *Client) InvokePlugin(ctx context.Context, plugin_id string, input interface{}) (map[string]interface{}, error) {
+	output := map[string]interface{}{}
+	if err := c.call(ctx, "POST", fmt.Sprintf("%s/%s", plugins_endpoint, plugin_id), input, &output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// DeletePlugin deletes a plugin.
+func (c *Client) DeletePlugin(ctx context.Context, plugin_id string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s", plugins_endpoint, plugin_id), nil, nil)
+}
@@ -6,7 +6,7 @@
 //       - Date:      27/11/2020
 // **********
 
-package dsm
+package dsmclient
 
 // [-] Structs to define DSM AWS Group
 type AWSGroup struct {
@@ -99,42 +99,62 @@ type AzureSobject struct {
 }
 
 type AzureSobjectCustomMetadata struct {
-	Azure_key_state     string `json:"azure-key-state"`
-	Azure_key_name      string `json:"azure-key-name"`
-	Azure_backup        string `json:"azure-backup"`
+	Azure_key_state string `json:"azure-key-state"`
+	Azure_key_name  string `json:"azure-key-name"`
+	Azure_backup    string `json:"azure-backup"`
 }
 
 type AzureSobjectExternal struct {
-	Id              AzureSobjectExternalId
-	Hsm_group_id    string
+	Id           AzureSobjectExternalId
+	Hsm_group_id string
 }
 
 type AzureSobjectExternalId struct {
-	Version     string
-	Label       string
-}
-
-
-// [-] Structs to define DSM GCP Security Object
-type GCPSobject struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Key_size    int
-	Acct_id     string
-	Group_id    string
-	Creator     DSMCreator
-	Kid         string
-	Obj_type    string
-	Enabled     bool
-	State       string
-	Links       DSMSobjectLinks
+	Version string
+	Label   string
 }
 
 // [-] Structs to define DSM definition
 type DSMCreator struct {
-	User string
+	User   string `json:"user,omitempty"`
+	App    string `json:"app,omitempty"`
+	Plugin string `json:"plugin,omitempty"`
+}
+
+// Map returns the creator the way the terraform schema stores it, e.g. {"user": "<uuid>"}
+func (c DSMCreator) Map() map[string]interface{} {
+	creator := map[string]interface{}{}
+	if c.User != "" {
+		creator["user"] = c.User
+	}
+	if c.App != "" {
+		creator["app"] = c.App
+	}
+	if c.Plugin != "" {
+		creator["plugin"] = c.Plugin
+	}
+	return creator
 }
 
 type DSMSobjectLinks struct {
-	Copiedfrom string
+	Copiedfrom  string   `json:"copiedFrom,omitempty"`
+	Copiedto    []string `json:"copiedTo,omitempty"`
+	Replacement string   `json:"replacement,omitempty"`
+	Replaced    string   `json:"replaced,omitempty"`
+}
+
+// Map returns the links the way the terraform schema stores them, copiedTo is
+// a list and is left out.
+func (l DSMSobjectLinks) Map() map[string]interface{} {
+	links := map[string]interface{}{}
+	if l.Copiedfrom != "" {
+		links["copiedFrom"] = l.Copiedfrom
+	}
+	if l.Replacement != "" {
+		links["replacement"] = l.Replacement
+	}
+	if l.Replaced != "" {
+		links["replaced"] = l.Replaced
+	}
+	return links
 }
@@ -0,0 +1,82 @@
+// **********
+// Terraform Provider - DSM: typed API client: users
+// **********
+
+package dsmclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const users_endpoint = "sys/v1/users"
+
+// [-] Structs to define DSM User
+type User struct {
+	User_id        string                 `json:"user_id"`
+	User_email     string                 `json:"user_email"`
+	First_name     string                 `json:"first_name,omitempty"`
+	Last_name      string                 `json:"last_name,omitempty"`
+	Description    *string                `json:"description,omitempty"`
+	Account_role   []string               `json:"account_role"`
+	Groups         map[string]interface{} `json:"groups,omitempty"`
+	Email_verified *bool                  `json:"email_verified,omitempty"`
+	Has_password   *bool                  `json:"has_password,omitempty"`
+}
+
+// GetUser reads a user.
+func (c *Client) GetUser(ctx context.Context, user_id string) (*User, error) {
+	user := &User{}
+	if err := c.call(ctx, "GET", fmt.Sprintf("%s/%s", users_endpoint, user_id), nil, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsers lists every user of the account.
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	if err := c.list(ctx, users_endpoint, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindUser returns the user with the given email, compared case insensitively,
+// or nil if there is none.
+func (c *Client) FindUser(ctx context.Context, user_email string) (*User, error) {
+	users, err := c.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if strings.EqualFold(users[i].User_email, user_email) {
+			return &users[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// InviteUser invites a user into the account.
+func (c *Client) InviteUser(ctx context.Context, body interface{}) (*User, error) {
+	user := &User{}
+	if err := c.call(ctx, "POST", users_endpoint+"/invite", body, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUser updates a user, e.g. its groups and roles.
+func (c *Client) UpdateUser(ctx context.Context, user_id string, body interface{}) (*User, error) {
+	user := &User{}
+	if err := c.call(ctx, "PATCH", fmt.Sprintf("%s/%s", users_endpoint, user_id), body, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RemoveUserFromAccount removes a user from the account.
+func (c *Client) RemoveUserFromAccount(ctx context.Context, user_id string) error {
+	return c.call(ctx, "DELETE", fmt.Sprintf("%s/%s/accounts", users_endpoint, user_id), nil, nil)
+}
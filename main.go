@@ -9,16 +9,33 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"flag"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 
 	"terraform-provider-dsm/dsm"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return dsm.Provider()
-		},
-	})
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	provider_server, err := dsm.NewProviderServer(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serve_opts []tf5server.ServeOpt
+	if debug {
+		serve_opts = append(serve_opts, tf5server.WithManagedDebug())
+	}
+	err = tf5server.Serve("registry.terraform.io/fortanix/dsm", provider_server, serve_opts...)
+	// Serve returns once terraform has shut the plugin down
+	dsm.TerminateSessions()
+	if err != nil {
+		log.Fatal(err)
+	}
 }